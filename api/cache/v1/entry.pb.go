@@ -0,0 +1,402 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: cache/v1/entry.proto
+
+package cachev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Entry is the protobuf payload of a cached POI list. It is versioned by
+// the cache entry header rather than shared with the public API, so either
+// can change without breaking the other.
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pois          []*POI                 `protobuf:"bytes,1,rep,name=pois,proto3" json:"pois,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_cache_v1_entry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_v1_entry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_cache_v1_entry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Entry) GetPois() []*POI {
+	if x != nil {
+		return x.Pois
+	}
+	return nil
+}
+
+type POI struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Latitude             float64                `protobuf:"fixed64,3,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude            float64                `protobuf:"fixed64,4,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Category             string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	Source               string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Rating               float64                `protobuf:"fixed64,7,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatingCount          int32                  `protobuf:"varint,8,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	Website              string                 `protobuf:"bytes,9,opt,name=website,proto3" json:"website,omitempty"`
+	Phone                string                 `protobuf:"bytes,10,opt,name=phone,proto3" json:"phone,omitempty"`
+	OpeningHours         []string               `protobuf:"bytes,11,rep,name=opening_hours,json=openingHours,proto3" json:"opening_hours,omitempty"`
+	Cuisine              string                 `protobuf:"bytes,12,opt,name=cuisine,proto3" json:"cuisine,omitempty"`
+	PriceLevel           int32                  `protobuf:"varint,13,opt,name=price_level,json=priceLevel,proto3" json:"price_level,omitempty"`
+	MenuUrl              string                 `protobuf:"bytes,14,opt,name=menu_url,json=menuUrl,proto3" json:"menu_url,omitempty"`
+	Address              string                 `protobuf:"bytes,15,opt,name=address,proto3" json:"address,omitempty"`
+	Description          string                 `protobuf:"bytes,16,opt,name=description,proto3" json:"description,omitempty"`
+	Email                string                 `protobuf:"bytes,17,opt,name=email,proto3" json:"email,omitempty"`
+	OpenNow              *bool                  `protobuf:"varint,18,opt,name=open_now,json=openNow,proto3,oneof" json:"open_now,omitempty"`
+	WheelchairAccessible *bool                  `protobuf:"varint,19,opt,name=wheelchair_accessible,json=wheelchairAccessible,proto3,oneof" json:"wheelchair_accessible,omitempty"`
+	OutdoorSeating       *bool                  `protobuf:"varint,20,opt,name=outdoor_seating,json=outdoorSeating,proto3,oneof" json:"outdoor_seating,omitempty"`
+	Takeaway             *bool                  `protobuf:"varint,21,opt,name=takeaway,proto3,oneof" json:"takeaway,omitempty"`
+	Delivery             *bool                  `protobuf:"varint,22,opt,name=delivery,proto3,oneof" json:"delivery,omitempty"`
+	Verified             *bool                  `protobuf:"varint,23,opt,name=verified,proto3,oneof" json:"verified,omitempty"`
+	Popularity           float64                `protobuf:"fixed64,24,opt,name=popularity,proto3" json:"popularity,omitempty"`
+	// canonical_id is Hynek's stable ID for the place, shared by every
+	// provider ID linked to it.
+	CanonicalId   string `protobuf:"bytes,25,opt,name=canonical_id,json=canonicalId,proto3" json:"canonical_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *POI) Reset() {
+	*x = POI{}
+	mi := &file_cache_v1_entry_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *POI) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*POI) ProtoMessage() {}
+
+func (x *POI) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_v1_entry_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use POI.ProtoReflect.Descriptor instead.
+func (*POI) Descriptor() ([]byte, []int) {
+	return file_cache_v1_entry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *POI) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *POI) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *POI) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *POI) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *POI) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *POI) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *POI) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *POI) GetRatingCount() int32 {
+	if x != nil {
+		return x.RatingCount
+	}
+	return 0
+}
+
+func (x *POI) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *POI) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *POI) GetOpeningHours() []string {
+	if x != nil {
+		return x.OpeningHours
+	}
+	return nil
+}
+
+func (x *POI) GetCuisine() string {
+	if x != nil {
+		return x.Cuisine
+	}
+	return ""
+}
+
+func (x *POI) GetPriceLevel() int32 {
+	if x != nil {
+		return x.PriceLevel
+	}
+	return 0
+}
+
+func (x *POI) GetMenuUrl() string {
+	if x != nil {
+		return x.MenuUrl
+	}
+	return ""
+}
+
+func (x *POI) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *POI) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *POI) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *POI) GetOpenNow() bool {
+	if x != nil && x.OpenNow != nil {
+		return *x.OpenNow
+	}
+	return false
+}
+
+func (x *POI) GetWheelchairAccessible() bool {
+	if x != nil && x.WheelchairAccessible != nil {
+		return *x.WheelchairAccessible
+	}
+	return false
+}
+
+func (x *POI) GetOutdoorSeating() bool {
+	if x != nil && x.OutdoorSeating != nil {
+		return *x.OutdoorSeating
+	}
+	return false
+}
+
+func (x *POI) GetTakeaway() bool {
+	if x != nil && x.Takeaway != nil {
+		return *x.Takeaway
+	}
+	return false
+}
+
+func (x *POI) GetDelivery() bool {
+	if x != nil && x.Delivery != nil {
+		return *x.Delivery
+	}
+	return false
+}
+
+func (x *POI) GetVerified() bool {
+	if x != nil && x.Verified != nil {
+		return *x.Verified
+	}
+	return false
+}
+
+func (x *POI) GetPopularity() float64 {
+	if x != nil {
+		return x.Popularity
+	}
+	return 0
+}
+
+func (x *POI) GetCanonicalId() string {
+	if x != nil {
+		return x.CanonicalId
+	}
+	return ""
+}
+
+var File_cache_v1_entry_proto protoreflect.FileDescriptor
+
+const file_cache_v1_entry_proto_rawDesc = "" +
+	"\n" +
+	"\x14cache/v1/entry.proto\x12\x0ehynek.cache.v1\"0\n" +
+	"\x05Entry\x12'\n" +
+	"\x04pois\x18\x01 \x03(\v2\x13.hynek.cache.v1.POIR\x04pois\"\xdf\x06\n" +
+	"\x03POI\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\blatitude\x18\x03 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x04 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x16\n" +
+	"\x06rating\x18\a \x01(\x01R\x06rating\x12!\n" +
+	"\frating_count\x18\b \x01(\x05R\vratingCount\x12\x18\n" +
+	"\awebsite\x18\t \x01(\tR\awebsite\x12\x14\n" +
+	"\x05phone\x18\n" +
+	" \x01(\tR\x05phone\x12#\n" +
+	"\ropening_hours\x18\v \x03(\tR\fopeningHours\x12\x18\n" +
+	"\acuisine\x18\f \x01(\tR\acuisine\x12\x1f\n" +
+	"\vprice_level\x18\r \x01(\x05R\n" +
+	"priceLevel\x12\x19\n" +
+	"\bmenu_url\x18\x0e \x01(\tR\amenuUrl\x12\x18\n" +
+	"\aaddress\x18\x0f \x01(\tR\aaddress\x12 \n" +
+	"\vdescription\x18\x10 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05email\x18\x11 \x01(\tR\x05email\x12\x1e\n" +
+	"\bopen_now\x18\x12 \x01(\bH\x00R\aopenNow\x88\x01\x01\x128\n" +
+	"\x15wheelchair_accessible\x18\x13 \x01(\bH\x01R\x14wheelchairAccessible\x88\x01\x01\x12,\n" +
+	"\x0foutdoor_seating\x18\x14 \x01(\bH\x02R\x0eoutdoorSeating\x88\x01\x01\x12\x1f\n" +
+	"\btakeaway\x18\x15 \x01(\bH\x03R\btakeaway\x88\x01\x01\x12\x1f\n" +
+	"\bdelivery\x18\x16 \x01(\bH\x04R\bdelivery\x88\x01\x01\x12\x1f\n" +
+	"\bverified\x18\x17 \x01(\bH\x05R\bverified\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"popularity\x18\x18 \x01(\x01R\n" +
+	"popularity\x12!\n" +
+	"\fcanonical_id\x18\x19 \x01(\tR\vcanonicalIdB\v\n" +
+	"\t_open_nowB\x18\n" +
+	"\x16_wheelchair_accessibleB\x12\n" +
+	"\x10_outdoor_seatingB\v\n" +
+	"\t_takeawayB\v\n" +
+	"\t_deliveryB\v\n" +
+	"\t_verifiedB9Z7github.com/hynek-systems/hynek-poi/api/cache/v1;cachev1b\x06proto3"
+
+var (
+	file_cache_v1_entry_proto_rawDescOnce sync.Once
+	file_cache_v1_entry_proto_rawDescData []byte
+)
+
+func file_cache_v1_entry_proto_rawDescGZIP() []byte {
+	file_cache_v1_entry_proto_rawDescOnce.Do(func() {
+		file_cache_v1_entry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cache_v1_entry_proto_rawDesc), len(file_cache_v1_entry_proto_rawDesc)))
+	})
+	return file_cache_v1_entry_proto_rawDescData
+}
+
+var file_cache_v1_entry_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_cache_v1_entry_proto_goTypes = []any{
+	(*Entry)(nil), // 0: hynek.cache.v1.Entry
+	(*POI)(nil),   // 1: hynek.cache.v1.POI
+}
+var file_cache_v1_entry_proto_depIdxs = []int32{
+	1, // 0: hynek.cache.v1.Entry.pois:type_name -> hynek.cache.v1.POI
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_cache_v1_entry_proto_init() }
+func file_cache_v1_entry_proto_init() {
+	if File_cache_v1_entry_proto != nil {
+		return
+	}
+	file_cache_v1_entry_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cache_v1_entry_proto_rawDesc), len(file_cache_v1_entry_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_cache_v1_entry_proto_goTypes,
+		DependencyIndexes: file_cache_v1_entry_proto_depIdxs,
+		MessageInfos:      file_cache_v1_entry_proto_msgTypes,
+	}.Build()
+	File_cache_v1_entry_proto = out.File
+	file_cache_v1_entry_proto_goTypes = nil
+	file_cache_v1_entry_proto_depIdxs = nil
+}
@@ -0,0 +1,10 @@
+// Package openapi holds the OpenAPI 3 description of the HTTP API, the
+// source for client generation and contract validation.
+package openapi
+
+import _ "embed"
+
+// Document is openapi.json, served at /openapi.json.
+//
+//go:embed openapi.json
+var Document []byte
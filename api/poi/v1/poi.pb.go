@@ -0,0 +1,993 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: poi/v1/poi.proto
+
+package poiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Latitude  float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Optional; takes precedence over latitude/longitude and radius.
+	Bbox *BBox `protobuf:"bytes,3,opt,name=bbox,proto3" json:"bbox,omitempty"`
+	// Radius in meters, at most 50000. Defaults to 1000.
+	Radius int32 `protobuf:"varint,4,opt,name=radius,proto3" json:"radius,omitempty"`
+	// Maximum results per provider, at most 200. Defaults to 50.
+	Limit         int32    `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Categories    []string `protobuf:"bytes,6,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_poi_v1_poi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetBbox() *BBox {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetRadius() int32 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type BBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLat        float64                `protobuf:"fixed64,1,opt,name=min_lat,json=minLat,proto3" json:"min_lat,omitempty"`
+	MinLng        float64                `protobuf:"fixed64,2,opt,name=min_lng,json=minLng,proto3" json:"min_lng,omitempty"`
+	MaxLat        float64                `protobuf:"fixed64,3,opt,name=max_lat,json=maxLat,proto3" json:"max_lat,omitempty"`
+	MaxLng        float64                `protobuf:"fixed64,4,opt,name=max_lng,json=maxLng,proto3" json:"max_lng,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BBox) Reset() {
+	*x = BBox{}
+	mi := &file_poi_v1_poi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BBox) ProtoMessage() {}
+
+func (x *BBox) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BBox.ProtoReflect.Descriptor instead.
+func (*BBox) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BBox) GetMinLat() float64 {
+	if x != nil {
+		return x.MinLat
+	}
+	return 0
+}
+
+func (x *BBox) GetMinLng() float64 {
+	if x != nil {
+		return x.MinLng
+	}
+	return 0
+}
+
+func (x *BBox) GetMaxLat() float64 {
+	if x != nil {
+		return x.MaxLat
+	}
+	return 0
+}
+
+func (x *BBox) GetMaxLng() float64 {
+	if x != nil {
+		return x.MaxLng
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SearchResponse_ProviderResult
+	//	*SearchResponse_Result
+	Event         isSearchResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_poi_v1_poi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchResponse) GetEvent() isSearchResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetProviderResult() *ProviderResult {
+	if x != nil {
+		if x, ok := x.Event.(*SearchResponse_ProviderResult); ok {
+			return x.ProviderResult
+		}
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetResult() *SearchResult {
+	if x != nil {
+		if x, ok := x.Event.(*SearchResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isSearchResponse_Event interface {
+	isSearchResponse_Event()
+}
+
+type SearchResponse_ProviderResult struct {
+	ProviderResult *ProviderResult `protobuf:"bytes,1,opt,name=provider_result,json=providerResult,proto3,oneof"`
+}
+
+type SearchResponse_Result struct {
+	Result *SearchResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*SearchResponse_ProviderResult) isSearchResponse_Event() {}
+
+func (*SearchResponse_Result) isSearchResponse_Event() {}
+
+// ProviderResult is a single provider's answer, cut to the search area and
+// with overrides applied, before deduplication.
+type ProviderResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ProviderStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Pois          []*POI                 `protobuf:"bytes,2,rep,name=pois,proto3" json:"pois,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderResult) Reset() {
+	*x = ProviderResult{}
+	mi := &file_poi_v1_poi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderResult) ProtoMessage() {}
+
+func (x *ProviderResult) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderResult.ProtoReflect.Descriptor instead.
+func (*ProviderResult) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProviderResult) GetStatus() *ProviderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *ProviderResult) GetPois() []*POI {
+	if x != nil {
+		return x.Pois
+	}
+	return nil
+}
+
+type SearchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Pois  []*POI                 `protobuf:"bytes,1,rep,name=pois,proto3" json:"pois,omitempty"`
+	// False when at least one provider failed, timed out or was skipped.
+	Complete      bool              `protobuf:"varint,2,opt,name=complete,proto3" json:"complete,omitempty"`
+	Cached        bool              `protobuf:"varint,3,opt,name=cached,proto3" json:"cached,omitempty"`
+	Providers     []*ProviderStatus `protobuf:"bytes,4,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_poi_v1_poi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResult) GetPois() []*POI {
+	if x != nil {
+		return x.Pois
+	}
+	return nil
+}
+
+func (x *SearchResult) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *SearchResult) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *SearchResult) GetProviders() []*ProviderStatus {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type ProviderStatus struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Provider string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// One of ok, error, timeout, circuit_open or skipped.
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	ErrorClass    string `protobuf:"bytes,3,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`
+	LatencyMs     int64  `protobuf:"varint,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	ResultCount   int32  `protobuf:"varint,5,opt,name=result_count,json=resultCount,proto3" json:"result_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderStatus) Reset() {
+	*x = ProviderStatus{}
+	mi := &file_poi_v1_poi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderStatus) ProtoMessage() {}
+
+func (x *ProviderStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderStatus.ProtoReflect.Descriptor instead.
+func (*ProviderStatus) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProviderStatus) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ProviderStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProviderStatus) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *ProviderStatus) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+func (x *ProviderStatus) GetResultCount() int32 {
+	if x != nil {
+		return x.ResultCount
+	}
+	return 0
+}
+
+type GetPOIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPOIRequest) Reset() {
+	*x = GetPOIRequest{}
+	mi := &file_poi_v1_poi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPOIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPOIRequest) ProtoMessage() {}
+
+func (x *GetPOIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPOIRequest.ProtoReflect.Descriptor instead.
+func (*GetPOIRequest) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPOIRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetPOIRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListCategoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesRequest) Reset() {
+	*x = ListCategoriesRequest{}
+	mi := &file_poi_v1_poi_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesRequest) ProtoMessage() {}
+
+func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListCategoriesRequest) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{7}
+}
+
+type ListCategoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Categories    []string               `protobuf:"bytes,1,rep,name=categories,proto3" json:"categories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesResponse) Reset() {
+	*x = ListCategoriesResponse{}
+	mi := &file_poi_v1_poi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesResponse) ProtoMessage() {}
+
+func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesResponse.ProtoReflect.Descriptor instead.
+func (*ListCategoriesResponse) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListCategoriesResponse) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+type POI struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Latitude             float64                `protobuf:"fixed64,3,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude            float64                `protobuf:"fixed64,4,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Category             string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	Source               string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Rating               float64                `protobuf:"fixed64,7,opt,name=rating,proto3" json:"rating,omitempty"`
+	RatingCount          int32                  `protobuf:"varint,8,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	Website              string                 `protobuf:"bytes,9,opt,name=website,proto3" json:"website,omitempty"`
+	Phone                string                 `protobuf:"bytes,10,opt,name=phone,proto3" json:"phone,omitempty"`
+	OpeningHours         []string               `protobuf:"bytes,11,rep,name=opening_hours,json=openingHours,proto3" json:"opening_hours,omitempty"`
+	Cuisine              string                 `protobuf:"bytes,12,opt,name=cuisine,proto3" json:"cuisine,omitempty"`
+	PriceLevel           int32                  `protobuf:"varint,13,opt,name=price_level,json=priceLevel,proto3" json:"price_level,omitempty"`
+	MenuUrl              string                 `protobuf:"bytes,14,opt,name=menu_url,json=menuUrl,proto3" json:"menu_url,omitempty"`
+	Address              string                 `protobuf:"bytes,15,opt,name=address,proto3" json:"address,omitempty"`
+	Description          string                 `protobuf:"bytes,16,opt,name=description,proto3" json:"description,omitempty"`
+	Email                string                 `protobuf:"bytes,17,opt,name=email,proto3" json:"email,omitempty"`
+	OpenNow              *bool                  `protobuf:"varint,18,opt,name=open_now,json=openNow,proto3,oneof" json:"open_now,omitempty"`
+	WheelchairAccessible *bool                  `protobuf:"varint,19,opt,name=wheelchair_accessible,json=wheelchairAccessible,proto3,oneof" json:"wheelchair_accessible,omitempty"`
+	OutdoorSeating       *bool                  `protobuf:"varint,20,opt,name=outdoor_seating,json=outdoorSeating,proto3,oneof" json:"outdoor_seating,omitempty"`
+	Takeaway             *bool                  `protobuf:"varint,21,opt,name=takeaway,proto3,oneof" json:"takeaway,omitempty"`
+	Delivery             *bool                  `protobuf:"varint,22,opt,name=delivery,proto3,oneof" json:"delivery,omitempty"`
+	Verified             *bool                  `protobuf:"varint,23,opt,name=verified,proto3,oneof" json:"verified,omitempty"`
+	Popularity           float64                `protobuf:"fixed64,24,opt,name=popularity,proto3" json:"popularity,omitempty"`
+	// canonical_id is Hynek's stable ID for the place, shared by every
+	// provider ID linked to it.
+	CanonicalId   string `protobuf:"bytes,25,opt,name=canonical_id,json=canonicalId,proto3" json:"canonical_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *POI) Reset() {
+	*x = POI{}
+	mi := &file_poi_v1_poi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *POI) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*POI) ProtoMessage() {}
+
+func (x *POI) ProtoReflect() protoreflect.Message {
+	mi := &file_poi_v1_poi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use POI.ProtoReflect.Descriptor instead.
+func (*POI) Descriptor() ([]byte, []int) {
+	return file_poi_v1_poi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *POI) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *POI) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *POI) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *POI) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *POI) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *POI) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *POI) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *POI) GetRatingCount() int32 {
+	if x != nil {
+		return x.RatingCount
+	}
+	return 0
+}
+
+func (x *POI) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *POI) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *POI) GetOpeningHours() []string {
+	if x != nil {
+		return x.OpeningHours
+	}
+	return nil
+}
+
+func (x *POI) GetCuisine() string {
+	if x != nil {
+		return x.Cuisine
+	}
+	return ""
+}
+
+func (x *POI) GetPriceLevel() int32 {
+	if x != nil {
+		return x.PriceLevel
+	}
+	return 0
+}
+
+func (x *POI) GetMenuUrl() string {
+	if x != nil {
+		return x.MenuUrl
+	}
+	return ""
+}
+
+func (x *POI) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *POI) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *POI) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *POI) GetOpenNow() bool {
+	if x != nil && x.OpenNow != nil {
+		return *x.OpenNow
+	}
+	return false
+}
+
+func (x *POI) GetWheelchairAccessible() bool {
+	if x != nil && x.WheelchairAccessible != nil {
+		return *x.WheelchairAccessible
+	}
+	return false
+}
+
+func (x *POI) GetOutdoorSeating() bool {
+	if x != nil && x.OutdoorSeating != nil {
+		return *x.OutdoorSeating
+	}
+	return false
+}
+
+func (x *POI) GetTakeaway() bool {
+	if x != nil && x.Takeaway != nil {
+		return *x.Takeaway
+	}
+	return false
+}
+
+func (x *POI) GetDelivery() bool {
+	if x != nil && x.Delivery != nil {
+		return *x.Delivery
+	}
+	return false
+}
+
+func (x *POI) GetVerified() bool {
+	if x != nil && x.Verified != nil {
+		return *x.Verified
+	}
+	return false
+}
+
+func (x *POI) GetPopularity() float64 {
+	if x != nil {
+		return x.Popularity
+	}
+	return 0
+}
+
+func (x *POI) GetCanonicalId() string {
+	if x != nil {
+		return x.CanonicalId
+	}
+	return ""
+}
+
+var File_poi_v1_poi_proto protoreflect.FileDescriptor
+
+const file_poi_v1_poi_proto_rawDesc = "" +
+	"\n" +
+	"\x10poi/v1/poi.proto\x12\fhynek.poi.v1\"\xbf\x01\n" +
+	"\rSearchRequest\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12&\n" +
+	"\x04bbox\x18\x03 \x01(\v2\x12.hynek.poi.v1.BBoxR\x04bbox\x12\x16\n" +
+	"\x06radius\x18\x04 \x01(\x05R\x06radius\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x06 \x03(\tR\n" +
+	"categories\"j\n" +
+	"\x04BBox\x12\x17\n" +
+	"\amin_lat\x18\x01 \x01(\x01R\x06minLat\x12\x17\n" +
+	"\amin_lng\x18\x02 \x01(\x01R\x06minLng\x12\x17\n" +
+	"\amax_lat\x18\x03 \x01(\x01R\x06maxLat\x12\x17\n" +
+	"\amax_lng\x18\x04 \x01(\x01R\x06maxLng\"\x98\x01\n" +
+	"\x0eSearchResponse\x12G\n" +
+	"\x0fprovider_result\x18\x01 \x01(\v2\x1c.hynek.poi.v1.ProviderResultH\x00R\x0eproviderResult\x124\n" +
+	"\x06result\x18\x02 \x01(\v2\x1a.hynek.poi.v1.SearchResultH\x00R\x06resultB\a\n" +
+	"\x05event\"m\n" +
+	"\x0eProviderResult\x124\n" +
+	"\x06status\x18\x01 \x01(\v2\x1c.hynek.poi.v1.ProviderStatusR\x06status\x12%\n" +
+	"\x04pois\x18\x02 \x03(\v2\x11.hynek.poi.v1.POIR\x04pois\"\xa5\x01\n" +
+	"\fSearchResult\x12%\n" +
+	"\x04pois\x18\x01 \x03(\v2\x11.hynek.poi.v1.POIR\x04pois\x12\x1a\n" +
+	"\bcomplete\x18\x02 \x01(\bR\bcomplete\x12\x16\n" +
+	"\x06cached\x18\x03 \x01(\bR\x06cached\x12:\n" +
+	"\tproviders\x18\x04 \x03(\v2\x1c.hynek.poi.v1.ProviderStatusR\tproviders\"\xa7\x01\n" +
+	"\x0eProviderStatus\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1f\n" +
+	"\verror_class\x18\x03 \x01(\tR\n" +
+	"errorClass\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x04 \x01(\x03R\tlatencyMs\x12!\n" +
+	"\fresult_count\x18\x05 \x01(\x05R\vresultCount\"7\n" +
+	"\rGetPOIRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x17\n" +
+	"\x15ListCategoriesRequest\"8\n" +
+	"\x16ListCategoriesResponse\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x01 \x03(\tR\n" +
+	"categories\"\xdf\x06\n" +
+	"\x03POI\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\blatitude\x18\x03 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x04 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x16\n" +
+	"\x06rating\x18\a \x01(\x01R\x06rating\x12!\n" +
+	"\frating_count\x18\b \x01(\x05R\vratingCount\x12\x18\n" +
+	"\awebsite\x18\t \x01(\tR\awebsite\x12\x14\n" +
+	"\x05phone\x18\n" +
+	" \x01(\tR\x05phone\x12#\n" +
+	"\ropening_hours\x18\v \x03(\tR\fopeningHours\x12\x18\n" +
+	"\acuisine\x18\f \x01(\tR\acuisine\x12\x1f\n" +
+	"\vprice_level\x18\r \x01(\x05R\n" +
+	"priceLevel\x12\x19\n" +
+	"\bmenu_url\x18\x0e \x01(\tR\amenuUrl\x12\x18\n" +
+	"\aaddress\x18\x0f \x01(\tR\aaddress\x12 \n" +
+	"\vdescription\x18\x10 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05email\x18\x11 \x01(\tR\x05email\x12\x1e\n" +
+	"\bopen_now\x18\x12 \x01(\bH\x00R\aopenNow\x88\x01\x01\x128\n" +
+	"\x15wheelchair_accessible\x18\x13 \x01(\bH\x01R\x14wheelchairAccessible\x88\x01\x01\x12,\n" +
+	"\x0foutdoor_seating\x18\x14 \x01(\bH\x02R\x0eoutdoorSeating\x88\x01\x01\x12\x1f\n" +
+	"\btakeaway\x18\x15 \x01(\bH\x03R\btakeaway\x88\x01\x01\x12\x1f\n" +
+	"\bdelivery\x18\x16 \x01(\bH\x04R\bdelivery\x88\x01\x01\x12\x1f\n" +
+	"\bverified\x18\x17 \x01(\bH\x05R\bverified\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"popularity\x18\x18 \x01(\x01R\n" +
+	"popularity\x12!\n" +
+	"\fcanonical_id\x18\x19 \x01(\tR\vcanonicalIdB\v\n" +
+	"\t_open_nowB\x18\n" +
+	"\x16_wheelchair_accessibleB\x12\n" +
+	"\x10_outdoor_seatingB\v\n" +
+	"\t_takeawayB\v\n" +
+	"\t_deliveryB\v\n" +
+	"\t_verified2\xea\x01\n" +
+	"\n" +
+	"POIService\x12E\n" +
+	"\x06Search\x12\x1b.hynek.poi.v1.SearchRequest\x1a\x1c.hynek.poi.v1.SearchResponse0\x01\x128\n" +
+	"\x06GetPOI\x12\x1b.hynek.poi.v1.GetPOIRequest\x1a\x11.hynek.poi.v1.POI\x12[\n" +
+	"\x0eListCategories\x12#.hynek.poi.v1.ListCategoriesRequest\x1a$.hynek.poi.v1.ListCategoriesResponseB5Z3github.com/hynek-systems/hynek-poi/api/poi/v1;poiv1b\x06proto3"
+
+var (
+	file_poi_v1_poi_proto_rawDescOnce sync.Once
+	file_poi_v1_poi_proto_rawDescData []byte
+)
+
+func file_poi_v1_poi_proto_rawDescGZIP() []byte {
+	file_poi_v1_poi_proto_rawDescOnce.Do(func() {
+		file_poi_v1_poi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_poi_v1_poi_proto_rawDesc), len(file_poi_v1_poi_proto_rawDesc)))
+	})
+	return file_poi_v1_poi_proto_rawDescData
+}
+
+var file_poi_v1_poi_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_poi_v1_poi_proto_goTypes = []any{
+	(*SearchRequest)(nil),          // 0: hynek.poi.v1.SearchRequest
+	(*BBox)(nil),                   // 1: hynek.poi.v1.BBox
+	(*SearchResponse)(nil),         // 2: hynek.poi.v1.SearchResponse
+	(*ProviderResult)(nil),         // 3: hynek.poi.v1.ProviderResult
+	(*SearchResult)(nil),           // 4: hynek.poi.v1.SearchResult
+	(*ProviderStatus)(nil),         // 5: hynek.poi.v1.ProviderStatus
+	(*GetPOIRequest)(nil),          // 6: hynek.poi.v1.GetPOIRequest
+	(*ListCategoriesRequest)(nil),  // 7: hynek.poi.v1.ListCategoriesRequest
+	(*ListCategoriesResponse)(nil), // 8: hynek.poi.v1.ListCategoriesResponse
+	(*POI)(nil),                    // 9: hynek.poi.v1.POI
+}
+var file_poi_v1_poi_proto_depIdxs = []int32{
+	1,  // 0: hynek.poi.v1.SearchRequest.bbox:type_name -> hynek.poi.v1.BBox
+	3,  // 1: hynek.poi.v1.SearchResponse.provider_result:type_name -> hynek.poi.v1.ProviderResult
+	4,  // 2: hynek.poi.v1.SearchResponse.result:type_name -> hynek.poi.v1.SearchResult
+	5,  // 3: hynek.poi.v1.ProviderResult.status:type_name -> hynek.poi.v1.ProviderStatus
+	9,  // 4: hynek.poi.v1.ProviderResult.pois:type_name -> hynek.poi.v1.POI
+	9,  // 5: hynek.poi.v1.SearchResult.pois:type_name -> hynek.poi.v1.POI
+	5,  // 6: hynek.poi.v1.SearchResult.providers:type_name -> hynek.poi.v1.ProviderStatus
+	0,  // 7: hynek.poi.v1.POIService.Search:input_type -> hynek.poi.v1.SearchRequest
+	6,  // 8: hynek.poi.v1.POIService.GetPOI:input_type -> hynek.poi.v1.GetPOIRequest
+	7,  // 9: hynek.poi.v1.POIService.ListCategories:input_type -> hynek.poi.v1.ListCategoriesRequest
+	2,  // 10: hynek.poi.v1.POIService.Search:output_type -> hynek.poi.v1.SearchResponse
+	9,  // 11: hynek.poi.v1.POIService.GetPOI:output_type -> hynek.poi.v1.POI
+	8,  // 12: hynek.poi.v1.POIService.ListCategories:output_type -> hynek.poi.v1.ListCategoriesResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_poi_v1_poi_proto_init() }
+func file_poi_v1_poi_proto_init() {
+	if File_poi_v1_poi_proto != nil {
+		return
+	}
+	file_poi_v1_poi_proto_msgTypes[2].OneofWrappers = []any{
+		(*SearchResponse_ProviderResult)(nil),
+		(*SearchResponse_Result)(nil),
+	}
+	file_poi_v1_poi_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_poi_v1_poi_proto_rawDesc), len(file_poi_v1_poi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_poi_v1_poi_proto_goTypes,
+		DependencyIndexes: file_poi_v1_poi_proto_depIdxs,
+		MessageInfos:      file_poi_v1_poi_proto_msgTypes,
+	}.Build()
+	File_poi_v1_poi_proto = out.File
+	file_poi_v1_poi_proto_goTypes = nil
+	file_poi_v1_poi_proto_depIdxs = nil
+}
@@ -0,0 +1,217 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: poi/v1/poi.proto
+
+package poiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	POIService_Search_FullMethodName         = "/hynek.poi.v1.POIService/Search"
+	POIService_GetPOI_FullMethodName         = "/hynek.poi.v1.POIService/GetPOI"
+	POIService_ListCategories_FullMethodName = "/hynek.poi.v1.POIService/ListCategories"
+)
+
+// POIServiceClient is the client API for POIService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// POIService is the gRPC counterpart of the HTTP API. Client deadlines and
+// cancellation are honoured by the search pipeline and upstream providers.
+type POIServiceClient interface {
+	// Search streams one ProviderResult per provider as it answers, followed
+	// by a single SearchResult with the merged, deduplicated and ranked POIs.
+	// Cache hits produce only the final SearchResult.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResponse], error)
+	// GetPOI returns a POI served by a recent search on this instance.
+	GetPOI(ctx context.Context, in *GetPOIRequest, opts ...grpc.CallOption) (*POI, error)
+	// ListCategories returns the category names accepted by Search.
+	ListCategories(ctx context.Context, in *ListCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+}
+
+type pOIServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPOIServiceClient(cc grpc.ClientConnInterface) POIServiceClient {
+	return &pOIServiceClient{cc}
+}
+
+func (c *pOIServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &POIService_ServiceDesc.Streams[0], POIService_Search_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, SearchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type POIService_SearchClient = grpc.ServerStreamingClient[SearchResponse]
+
+func (c *pOIServiceClient) GetPOI(ctx context.Context, in *GetPOIRequest, opts ...grpc.CallOption) (*POI, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(POI)
+	err := c.cc.Invoke(ctx, POIService_GetPOI_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pOIServiceClient) ListCategories(ctx context.Context, in *ListCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, POIService_ListCategories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// POIServiceServer is the server API for POIService service.
+// All implementations must embed UnimplementedPOIServiceServer
+// for forward compatibility.
+//
+// POIService is the gRPC counterpart of the HTTP API. Client deadlines and
+// cancellation are honoured by the search pipeline and upstream providers.
+type POIServiceServer interface {
+	// Search streams one ProviderResult per provider as it answers, followed
+	// by a single SearchResult with the merged, deduplicated and ranked POIs.
+	// Cache hits produce only the final SearchResult.
+	Search(*SearchRequest, grpc.ServerStreamingServer[SearchResponse]) error
+	// GetPOI returns a POI served by a recent search on this instance.
+	GetPOI(context.Context, *GetPOIRequest) (*POI, error)
+	// ListCategories returns the category names accepted by Search.
+	ListCategories(context.Context, *ListCategoriesRequest) (*ListCategoriesResponse, error)
+	mustEmbedUnimplementedPOIServiceServer()
+}
+
+// UnimplementedPOIServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPOIServiceServer struct{}
+
+func (UnimplementedPOIServiceServer) Search(*SearchRequest, grpc.ServerStreamingServer[SearchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedPOIServiceServer) GetPOI(context.Context, *GetPOIRequest) (*POI, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPOI not implemented")
+}
+func (UnimplementedPOIServiceServer) ListCategories(context.Context, *ListCategoriesRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCategories not implemented")
+}
+func (UnimplementedPOIServiceServer) mustEmbedUnimplementedPOIServiceServer() {}
+func (UnimplementedPOIServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafePOIServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to POIServiceServer will
+// result in compilation errors.
+type UnsafePOIServiceServer interface {
+	mustEmbedUnimplementedPOIServiceServer()
+}
+
+func RegisterPOIServiceServer(s grpc.ServiceRegistrar, srv POIServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPOIServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&POIService_ServiceDesc, srv)
+}
+
+func _POIService_Search_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(POIServiceServer).Search(m, &grpc.GenericServerStream[SearchRequest, SearchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type POIService_SearchServer = grpc.ServerStreamingServer[SearchResponse]
+
+func _POIService_GetPOI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPOIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(POIServiceServer).GetPOI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: POIService_GetPOI_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(POIServiceServer).GetPOI(ctx, req.(*GetPOIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _POIService_ListCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCategoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(POIServiceServer).ListCategories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: POIService_ListCategories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(POIServiceServer).ListCategories(ctx, req.(*ListCategoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// POIService_ServiceDesc is the grpc.ServiceDesc for POIService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var POIService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hynek.poi.v1.POIService",
+	HandlerType: (*POIServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPOI",
+			Handler:    _POIService_GetPOI_Handler,
+		},
+		{
+			MethodName: "ListCategories",
+			Handler:    _POIService_ListCategories_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Search",
+			Handler:       _POIService_Search_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "poi/v1/poi.proto",
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/cost"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/overrides"
+)
+
+type invalidateResponse struct {
+	Removed      int  `json:"removed"`
+	IndexRemoved int  `json:"index_removed"`
+	Broadcast    bool `json:"broadcast"`
+}
+
+// invalidateHandler serves POST /admin/cache/invalidate. It purges both
+// cache layers and the POI index here, and broadcasts the selector so
+// every other replica drops it from its L1 and index too.
+func invalidateHandler(
+	layers cache.Invalidator,
+	index *cache.POIIndex,
+	bus *cache.InvalidationBus,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var sel cache.Selector
+
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&sel); err != nil {
+			http.Error(w, "invalid request body", 400)
+			return
+		}
+
+		if err := sel.Validate(); err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		removed, err := layers.Invalidate(sel)
+
+		if err != nil {
+			slog.ErrorContext(r.Context(), "admin: invalidate", "selector", sel, "error", err)
+			http.Error(w, "invalidation failed", 500)
+			return
+		}
+
+		indexRemoved, _ := index.Invalidate(sel)
+
+		resp := invalidateResponse{
+			Removed:      removed,
+			IndexRemoved: indexRemoved,
+			Broadcast:    true,
+		}
+
+		if err := bus.Publish(r.Context(), sel); err != nil {
+			slog.WarnContext(r.Context(), "admin: broadcast invalidation", "error", err)
+			resp.Broadcast = false
+		}
+
+		metrics.CacheInvalidations.WithLabelValues("api").Inc()
+
+		slog.InfoContext(r.Context(), "admin: invalidated", "selector", sel, "remote_addr", r.RemoteAddr, "removed", removed, "index_removed", indexRemoved)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, err.Error(), 500)
+		}
+	}
+}
+
+type costsResponse struct {
+	Currency  string              `json:"currency"`
+	Providers []cost.ProviderCost `json:"providers"`
+}
+
+// costsHandler serves GET /admin/costs: each paid provider's calls and
+// estimated spend today and this month, against its caps. Totals are
+// fleet-wide as of the last flush plus this replica's calls since.
+func costsHandler(ledger *cost.Ledger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(w, costsResponse{Currency: ledger.Currency(), Providers: ledger.Report()})
+	}
+}
+
+type overridesResponse struct {
+	Rules []overrides.Rule `json:"rules"`
+}
+
+type auditResponse struct {
+	Entries []overrides.AuditEntry `json:"entries"`
+}
+
+// overridesHandler serves /admin/overrides: GET lists every rule, POST
+// creates or replaces an admin rule, and DELETE ?id=&reason= removes one.
+// Changes are audited under the X-Actor header, or the client address
+// without one.
+func overridesHandler(engine *overrides.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.Method {
+
+		case http.MethodGet:
+			writeJSON(w, overridesResponse{Rules: engine.Rules()})
+
+		case http.MethodPost:
+
+			var rule overrides.Rule
+
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&rule); err != nil {
+				http.Error(w, "invalid request body", 400)
+				return
+			}
+
+			if rule.ID == "" {
+				rule.ID = newRuleID()
+			}
+
+			saved, err := engine.Put(r.Context(), rule, actor(r))
+
+			if err != nil {
+				overrideError(w, r, err)
+				return
+			}
+
+			slog.InfoContext(r.Context(), "admin: override saved", "id", saved.ID, "action", saved.Action, "author", saved.Author, "reason", saved.Reason)
+
+			writeJSON(w, saved)
+
+		case http.MethodDelete:
+
+			id := r.URL.Query().Get("id")
+
+			if id == "" {
+				http.Error(w, "id is required", 400)
+				return
+			}
+
+			if err := engine.Delete(r.Context(), id, r.URL.Query().Get("reason"), actor(r)); err != nil {
+				overrideError(w, r, err)
+				return
+			}
+
+			slog.InfoContext(r.Context(), "admin: override deleted", "id", id, "author", actor(r))
+
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// overrideAuditHandler serves GET /admin/overrides/audit?limit=, newest
+// changes first.
+func overrideAuditHandler(engine *overrides.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		limit := 100
+
+		if v := r.URL.Query().Get("limit"); v != "" {
+
+			n, err := strconv.Atoi(v)
+
+			if err != nil || n < 1 || n > 1000 {
+				http.Error(w, "limit must be between 1 and 1000", 400)
+				return
+			}
+
+			limit = n
+		}
+
+		entries, err := engine.Audit(r.Context(), limit)
+
+		if err != nil {
+			slog.ErrorContext(r.Context(), "admin: override audit", "error", err)
+			http.Error(w, "audit log unavailable", 500)
+			return
+		}
+
+		writeJSON(w, auditResponse{Entries: entries})
+	}
+}
+
+func overrideError(w http.ResponseWriter, r *http.Request, err error) {
+
+	switch {
+
+	case errors.Is(err, overrides.ErrFileRule):
+		http.Error(w, err.Error(), http.StatusConflict)
+
+	case errors.Is(err, overrides.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+	case errors.Is(err, overrides.ErrInvalidRule):
+		http.Error(w, err.Error(), 400)
+
+	default:
+		slog.ErrorContext(r.Context(), "admin: overrides", "error", err)
+		http.Error(w, "override store unavailable", 500)
+	}
+}
+
+func actor(r *http.Request) string {
+
+	if a := strings.TrimSpace(r.Header.Get("X-Actor")); a != "" {
+		return a
+	}
+
+	return r.RemoteAddr
+}
+
+func newRuleID() string {
+
+	b := make([]byte, 8)
+
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), 500)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/overrides"
+)
+
+func TestInvalidateHandler_RejectsBadSelector(t *testing.T) {
+
+	handler := invalidateHandler(cache.NewMemoryCache(), cache.NewPOIIndex(time.Minute, 10), nil)
+
+	for _, body := range []string{`{}`, `{"key":"a","source":"osm"}`, `not json`} {
+
+		rec := httptest.NewRecorder()
+
+		handler(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/invalidate", strings.NewReader(body)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestOverridesHandler_RejectsBadRules(t *testing.T) {
+
+	engine := overrides.NewEngine(nil)
+
+	engine.SetFileRules([]overrides.Rule{
+		{ID: "from-file", Action: overrides.ActionHide, Match: overrides.Match{ID: "1"}, Reason: "closed"},
+	})
+
+	handler := overridesHandler(engine)
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`not json`, http.StatusBadRequest},
+		{`{"action":"hide","match":{"id":"1"}}`, http.StatusBadRequest},
+		{`{"action":"boost","match":{"id":"1"},"reason":"x"}`, http.StatusBadRequest},
+		{`{"id":"from-file","action":"hide","match":{"id":"1"},"reason":"x"}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+
+		rec := httptest.NewRecorder()
+
+		handler(rec, httptest.NewRequest(http.MethodPost, "/admin/overrides", strings.NewReader(tt.body)))
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.body, tt.code, rec.Code)
+		}
+	}
+}
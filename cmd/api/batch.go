@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/logging"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+)
+
+// maxBatchBody bounds the request body of /v1/search/batch.
+const maxBatchBody = 1 << 20
+
+// batchConfig holds the active batch limits, swapped on config reload.
+var batchConfig atomic.Pointer[config.BatchConfig]
+
+type batchQuery struct {
+	Lat        float64   `json:"lat"`
+	Lng        float64   `json:"lng"`
+	BBox       *bboxBody `json:"bbox,omitempty"`
+	Radius     int       `json:"radius"`
+	Limit      int       `json:"limit"`
+	Categories []string  `json:"categories"`
+}
+
+type bboxBody struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+type batchRequest struct {
+	Queries  []batchQuery `json:"queries"`
+	PageSize int          `json:"page_size"`
+}
+
+// batchItem holds either a result page or an error for one query.
+type batchItem struct {
+	*domain.PaginatedResponse
+
+	Error string `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Results       []batchItem `json:"results"`
+	ProviderCalls int         `json:"provider_calls"`
+}
+
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+
+	start := time.Now()
+
+	metrics.RequestsTotal.WithLabelValues("/v1/search/batch").Inc()
+
+	defer func() {
+		metrics.RequestDuration.
+			WithLabelValues("/v1/search/batch").
+			Observe(time.Since(start).Seconds())
+	}()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limits := batchConfig.Load()
+
+	var req batchRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", 400)
+		return
+	}
+
+	if len(req.Queries) == 0 {
+		http.Error(w, "queries must not be empty", 400)
+		return
+	}
+
+	if len(req.Queries) > limits.MaxQueries {
+		http.Error(w, fmt.Sprintf("at most %d queries per batch", limits.MaxQueries), 400)
+		return
+	}
+
+	pageSize := req.PageSize
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	queries := make([]domain.SearchQuery, len(req.Queries))
+
+	for i, q := range req.Queries {
+
+		queries[i] = q.searchQuery()
+
+		if err := validateQuery(queries[i]); err != nil {
+			http.Error(w, fmt.Sprintf("query %d: %v", i, err), 400)
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	var budget *orchestrator.CallBudget
+
+	if limits.ProviderBudget > 0 {
+		budget = orchestrator.NewCallBudget(limits.ProviderBudget)
+		ctx = orchestrator.WithCallBudget(ctx, budget)
+	}
+
+	results := orchestrator.SearchBatch(ctx, orch.Load(), queries, limits.Concurrency)
+
+	resp := batchResponse{Results: make([]batchItem, len(results))}
+
+	failed, degraded := 0, 0
+
+	for i, res := range results {
+
+		if res.Err != nil {
+			failed++
+			resp.Results[i].Error = res.Err.Error()
+			continue
+		}
+
+		if !res.Result.Complete {
+			degraded++
+		}
+
+		page := paginate(res.Result, 1, pageSize)
+		resp.Results[i].PaginatedResponse = &page
+	}
+
+	if budget != nil {
+		resp.ProviderCalls = budget.Used()
+	}
+
+	logging.Annotate(ctx,
+		slog.Int("queries", len(queries)),
+		slog.Int("failed", failed),
+		slog.Int("degraded", degraded),
+		slog.Int("provider_calls", resp.ProviderCalls),
+	)
+
+	if failed > 0 || degraded > 0 {
+		logging.Keep(ctx)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), 500)
+	}
+}
+
+func (q batchQuery) searchQuery() domain.SearchQuery {
+
+	query := domain.SearchQuery{
+		Latitude:   q.Lat,
+		Longitude:  q.Lng,
+		Radius:     q.Radius,
+		Limit:      q.Limit,
+		Categories: q.Categories,
+	}
+
+	if query.Radius <= 0 {
+		query.Radius = 1000
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+
+	if q.BBox != nil {
+		query.BBox = &domain.BBox{
+			MinLat: q.BBox.MinLat,
+			MinLng: q.BBox.MinLng,
+			MaxLat: q.BBox.MaxLat,
+			MaxLng: q.BBox.MaxLng,
+		}
+	}
+
+	return query
+}
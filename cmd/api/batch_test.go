@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+func setupBatch(limits config.BatchConfig) {
+
+	orch.Store(orchestrator.NewCached(
+		orchestrator.NewParallel([]provider.Provider{&provider.MockProvider{}}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	))
+
+	batchConfig.Store(&limits)
+}
+
+func postBatch(body string) *httptest.ResponseRecorder {
+
+	rec := httptest.NewRecorder()
+
+	batchHandler(rec, httptest.NewRequest(http.MethodPost, "/v1/search/batch", strings.NewReader(body)))
+
+	return rec
+}
+
+func TestBatchHandler_OrderedResultsAndErrors(t *testing.T) {
+
+	setupBatch(config.BatchConfig{MaxQueries: 10, Concurrency: 1, ProviderBudget: 1})
+
+	rec := postBatch(`{"queries":[{"lat":59.33,"lng":18.07},{"lat":59.33,"lng":18.07},{"lat":1,"lng":1}]}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Results []struct {
+			Total int    `json:"total"`
+			Error string `json:"error"`
+		} `json:"results"`
+		ProviderCalls int `json:"provider_calls"`
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+
+	if resp.Results[0].Total != 1 || resp.Results[1].Total != 1 {
+		t.Errorf("Expected coalesced queries to share a result, got %+v", resp.Results[:2])
+	}
+
+	if resp.Results[2].Error == "" {
+		t.Errorf("Expected budget error for third query, got %+v", resp.Results[2])
+	}
+
+	if resp.ProviderCalls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", resp.ProviderCalls)
+	}
+}
+
+func TestBatchHandler_RejectsOversizedBatch(t *testing.T) {
+
+	setupBatch(config.BatchConfig{MaxQueries: 1, Concurrency: 1})
+
+	rec := postBatch(`{"queries":[{"lat":1,"lng":1},{"lat":2,"lng":2}]}`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
+
+func TestBatchHandler_RejectsOutOfBoundsQuery(t *testing.T) {
+
+	setupBatch(config.BatchConfig{MaxQueries: 2, Concurrency: 1})
+
+	rec := postBatch(`{"queries":[{"lat":1,"lng":1},{"lat":2,"lng":2,"limit":100000}]}`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
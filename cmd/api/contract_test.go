@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/api/openapi"
+	"github.com/hynek-systems/hynek-poi/internal/access"
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/contract"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/health"
+	"github.com/hynek-systems/hynek-poi/internal/identity"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+	"github.com/redis/go-redis/v9"
+)
+
+// linkStore is an identity store holding a single place.
+type linkStore struct {
+	err error
+}
+
+var linked = identity.Ref{Source: "osm", ID: "node/42"}
+
+func (s linkStore) Lookup(ctx context.Context, refs []identity.Ref) (map[identity.Ref]string, error) {
+
+	found := map[identity.Ref]string{}
+
+	for _, ref := range refs {
+		if ref == linked {
+			found[ref] = "hp_42"
+		}
+	}
+
+	return found, s.err
+}
+
+func (s linkStore) Link(ctx context.Context, proposed map[identity.Ref]string) (map[identity.Ref]string, error) {
+	return proposed, s.err
+}
+
+func (s linkStore) Links(ctx context.Context, canonical string) ([]identity.Ref, error) {
+
+	if canonical != "hp_42" {
+		return nil, s.err
+	}
+
+	return []identity.Ref{linked, {Source: "google", ID: "ChIJ42"}}, s.err
+}
+
+func unreachableRedis(t *testing.T) redis.UniversalClient {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// richPOI sets every optional field, so each is checked against the
+// document.
+func richPOI() domain.POI {
+
+	yes := true
+
+	return domain.POI{
+		ID:                   "node/42",
+		Name:                 "Café Mozart",
+		Latitude:             50.0866,
+		Longitude:            14.4206,
+		Category:             "cafe",
+		Source:               "osm",
+		Rating:               4.5,
+		RatingCount:          120,
+		Website:              "https://example.com",
+		Phone:                "+420 000 000 000",
+		OpeningHours:         []string{"Mo-Su 08:00-22:00"},
+		Cuisine:              "coffee_shop",
+		PriceLevel:           2,
+		MenuURL:              "https://example.com/menu",
+		Address:              "Staroměstské náměstí 22",
+		Description:          "Café on the square",
+		Email:                "cafe@example.com",
+		OpenNow:              &yes,
+		WheelchairAccessible: &yes,
+		OutdoorSeating:       &yes,
+		Takeaway:             &yes,
+		Delivery:             &yes,
+		Verified:             &yes,
+		Popularity:           0.8,
+		CanonicalID:          "hp_42",
+	}
+}
+
+// contractServer serves the API handlers as main routes them, behind
+// strict contract validation, so any response the document does not
+// describe becomes a 500.
+func contractServer(t *testing.T, guard *access.Guard, store linkStore) *httptest.Server {
+
+	spec, err := contract.Load(openapi.Document)
+
+	if err != nil {
+		t.Fatalf("Expected the OpenAPI document to load, got %v", err)
+	}
+
+	checker := health.New(unreachableRedis(t), false)
+
+	// one provider with a success, a failure and a canary, so every
+	// status field is checked
+	checker.Monitor = health.NewMonitor(config.HealthConfig{Window: 20, MinSuccessRate: 0.5})
+	checker.Monitor.SetProviders([]provider.RegisteredProvider{{Provider: &fixedProvider{}}})
+	checker.Monitor.Observe(domain.ProviderStatus{Provider: "fixed", Status: domain.ProviderStatusOK, LatencyMs: 40})
+	checker.Monitor.Observe(domain.ProviderStatus{Provider: "fixed", Status: domain.ProviderStatusError, ErrorClass: "upstream"})
+	checker.Monitor.Canary(context.Background(), config.CanaryConfig{Interval: time.Second, Radius: 500})
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/v1/search", guard.Middleware(http.HandlerFunc(searchHandler)))
+	mux.Handle("/v1/search/stream", guard.Middleware(http.HandlerFunc(streamHandler)))
+	mux.Handle("/v1/search/batch", guard.Middleware(http.HandlerFunc(batchHandler)))
+	mux.Handle("/v1/ids", guard.Middleware(idsHandler(identity.NewRegistry(store, 0))))
+	mux.Handle("/v1/status", guard.Middleware(http.HandlerFunc(checker.StatusHandler)))
+	mux.HandleFunc("/health", checker.HealthHandler)
+	mux.HandleFunc("/ready", checker.ReadyHandler)
+	mux.HandleFunc("/openapi.json", openapiHandler)
+
+	server := httptest.NewServer(corsMiddleware(spec.Middleware(contract.ModeStrict, mux)))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func useProvider(p provider.Provider) {
+
+	orch.Store(orchestrator.NewCached(
+		orchestrator.NewParallel([]provider.Provider{p}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	))
+
+	batchConfig.Store(&config.BatchConfig{MaxQueries: 2, Concurrency: 2, ProviderBudget: 10})
+}
+
+func TestHandlers_MatchOpenAPIDocument(t *testing.T) {
+
+	spec, _ := contract.Load(openapi.Document)
+
+	found := &fixedProvider{pois: []domain.POI{richPOI()}}
+	failing := &fixedProvider{err: errors.New("boom")}
+
+	tests := []struct {
+		name     string
+		provider provider.Provider
+		store    linkStore
+		method   string
+		target   string
+		body     string
+		noKey    bool
+		want     int
+	}{
+		{"search", found, linkStore{}, "GET", "/v1/search?lat=50.087&lng=14.421&categories=cafe&page=1&page_size=10", "", false, 200},
+		{"search bbox", found, linkStore{}, "GET", "/v1/search?bbox=50.08,14.41,50.09,14.43", "", false, 200},
+		{"search past last page", found, linkStore{}, "GET", "/v1/search?lat=50.087&lng=14.421&page=5", "", false, 200},
+		{"search empty area", &fixedProvider{}, linkStore{}, "GET", "/v1/search?lat=50.087&lng=14.421", "", false, 200},
+		{"search unavailable", failing, linkStore{}, "GET", "/v1/search?lat=50.087&lng=14.421", "", false, 503},
+		{"search bad parameter", found, linkStore{}, "GET", "/v1/search?lat=north", "", false, 400},
+		{"search without key", found, linkStore{}, "GET", "/v1/search?lat=50.087&lng=14.421", "", true, 401},
+		{"stream", found, linkStore{}, "GET", "/v1/search/stream?lat=50.087&lng=14.421", "", false, 200},
+		{"batch", found, linkStore{}, "POST", "/v1/search/batch", `{"queries":[{"lat":50.087,"lng":14.421},{"bbox":{"min_lat":50.08,"min_lng":14.41,"max_lat":50.09,"max_lng":14.43}}],"page_size":5}`, false, 200},
+		{"batch with failed query", failing, linkStore{}, "POST", "/v1/search/batch", `{"queries":[{"lat":50.087,"lng":14.421}]}`, false, 200},
+		{"batch over limit", found, linkStore{}, "POST", "/v1/search/batch", `{"queries":[{"lat":1,"lng":1},{"lat":2,"lng":2},{"lat":3,"lng":3}]}`, false, 400},
+		{"ids by provider id", found, linkStore{}, "GET", "/v1/ids?source=osm&id=node/42", "", false, 200},
+		{"ids by canonical id", found, linkStore{}, "GET", "/v1/ids?id=hp_42", "", false, 200},
+		{"ids unknown", found, linkStore{}, "GET", "/v1/ids?source=osm&id=node/7", "", false, 404},
+		{"ids without source", found, linkStore{}, "GET", "/v1/ids?id=node/42", "", false, 400},
+		{"ids unavailable", found, linkStore{err: errors.New("down")}, "GET", "/v1/ids?source=osm&id=node/42", "", false, 503},
+		{"status", found, linkStore{}, "GET", "/v1/status", "", false, 200},
+		{"health", found, linkStore{}, "GET", "/health", "", true, 200},
+		{"ready", found, linkStore{}, "GET", "/ready", "", true, 200},
+		{"openapi", found, linkStore{}, "GET", "/openapi.json", "", true, 200},
+	}
+
+	exercised := map[string]bool{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			useProvider(tt.provider)
+
+			server := contractServer(t, access.NewGuard([]string{"secret"}, 0, 0), tt.store)
+
+			req, _ := http.NewRequest(tt.method, server.URL+tt.target, strings.NewReader(tt.body))
+
+			if !tt.noKey {
+				req.Header.Set("X-API-Key", "secret")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+
+			if err != nil {
+				t.Fatalf("%s %s: %v", tt.method, tt.target, err)
+			}
+
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected %d, got %d: %s", tt.want, resp.StatusCode, body)
+			}
+
+			exercised[tt.method+" "+req.URL.Path] = true
+		})
+	}
+
+	for _, op := range spec.Operations() {
+		if !exercised[op] {
+			t.Errorf("Expected a contract test for %s", op)
+		}
+	}
+}
+
+func TestHandlers_RateLimitMatchesOpenAPIDocument(t *testing.T) {
+
+	useProvider(&fixedProvider{})
+
+	server := contractServer(t, access.NewGuard(nil, 1, 1), linkStore{})
+
+	var last *http.Response
+
+	for range 3 {
+
+		resp, err := http.Get(server.URL + "/v1/search?lat=50.087&lng=14.421")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		last = resp
+	}
+
+	if last.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected 429, got %d", last.StatusCode)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/hynek-systems/hynek-poi/internal/identity"
+)
+
+type idsResponse struct {
+	CanonicalID string         `json:"canonical_id"`
+	IDs         []identity.Ref `json:"ids"`
+}
+
+// idsHandler serves GET /v1/ids. With source and id it resolves a
+// provider ID to its canonical ID; with a canonical id alone it lists
+// the provider IDs linked to it. Either way every linked ID is returned.
+func idsHandler(registry *identity.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ref := identity.Ref{
+			Source: r.URL.Query().Get("source"),
+			ID:     r.URL.Query().Get("id"),
+		}
+
+		if ref.ID == "" || (ref.Source == "" && !identity.IsCanonical(ref.ID)) {
+			http.Error(w, "id is required, with source unless it is a canonical id", 400)
+			return
+		}
+
+		canonical, links, found, err := registry.Resolve(r.Context(), ref)
+
+		if err != nil {
+			slog.WarnContext(r.Context(), "ids: resolve", "ref", ref.String(), "error", err)
+			http.Error(w, "id registry unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !found {
+			http.Error(w, "id not found", http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, idsResponse{CanonicalID: canonical, IDs: links})
+	}
+}
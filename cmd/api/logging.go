@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/logging"
+)
+
+// logQuery adds the search parameters to the request's log line.
+func logQuery(ctx context.Context, query domain.SearchQuery, page, pageSize int) {
+
+	attrs := []any{
+		slog.Float64("lat", query.Latitude),
+		slog.Float64("lng", query.Longitude),
+	}
+
+	if query.BBox != nil {
+		attrs = append(attrs, slog.Any("bbox", []float64{query.BBox.MinLat, query.BBox.MinLng, query.BBox.MaxLat, query.BBox.MaxLng}))
+	}
+
+	if len(query.Categories) > 0 {
+		attrs = append(attrs, slog.String("categories", strings.Join(query.Categories, ",")))
+	}
+
+	attrs = append(attrs, slog.Int("page", page), slog.Int("page_size", pageSize))
+
+	logging.Annotate(ctx, slog.Group("query", attrs...))
+}
+
+// logResult adds how a search was answered to the request's log line:
+// cache outcome, result count and each provider's outcome. Degraded
+// answers are logged whatever the sampling rate.
+func logResult(ctx context.Context, result domain.SearchResult, err error) {
+
+	cacheOutcome := "miss"
+
+	if result.Cached {
+		cacheOutcome = "hit"
+	}
+
+	outcome := "complete"
+
+	if err != nil || !result.Complete {
+		outcome = "degraded"
+		logging.Keep(ctx)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("cache", cacheOutcome),
+		slog.String("result", outcome),
+		slog.Int("results", len(result.POIs)),
+	}
+
+	if len(result.Providers) > 0 {
+		attrs = append(attrs, logProviders(result.Providers))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logging.Annotate(ctx, attrs...)
+}
+
+func logProviders(statuses []domain.ProviderStatus) slog.Attr {
+
+	providers := make([]any, 0, len(statuses))
+
+	for _, s := range statuses {
+
+		attrs := []any{
+			slog.String("status", s.Status),
+			slog.Int64("latency_ms", s.LatencyMs),
+			slog.Int("results", s.ResultCount),
+		}
+
+		if s.ErrorClass != "" {
+			attrs = append(attrs, slog.String("error_class", s.ErrorClass))
+		}
+
+		providers = append(providers, slog.Group(s.Provider, attrs...))
+	}
+
+	return slog.Group("providers", providers...)
+}
@@ -1,26 +1,50 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/hynek-systems/hynek-poi/api/openapi"
+	"github.com/hynek-systems/hynek-poi/internal/access"
 	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/capture"
 	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/contract"
+	"github.com/hynek-systems/hynek-poi/internal/cost"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/gql"
+	"github.com/hynek-systems/hynek-poi/internal/grpcapi"
 	"github.com/hynek-systems/hynek-poi/internal/health"
+	"github.com/hynek-systems/hynek-poi/internal/identity"
+	"github.com/hynek-systems/hynek-poi/internal/logging"
 	"github.com/hynek-systems/hynek-poi/internal/metrics"
 	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/overrides"
+	"github.com/hynek-systems/hynek-poi/internal/poistore"
 	"github.com/hynek-systems/hynek-poi/internal/provider"
 	"github.com/hynek-systems/hynek-poi/internal/ranking"
+	"github.com/hynek-systems/hynek-poi/internal/warming"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var orch *orchestrator.CachedOrchestrator
+// orch holds the active pipeline. It is swapped as a whole on config
+// reload, so in-flight requests finish on the pipeline they started with.
+var orch atomic.Pointer[orchestrator.CachedOrchestrator]
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -28,11 +52,14 @@ func corsMiddleware(next http.Handler) http.Handler {
 		// Allow any origin
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-		// Only allow GET
-		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		// Only allow GET, and POST for GraphQL
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+
+		// Allow headers needed for GET and API key auth
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
 
-		// Allow headers needed for GET
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		// Let browser clients read the result envelope headers
+		w.Header().Set("Access-Control-Expose-Headers", "X-Hynek-Result, X-Hynek-Cache, X-Hynek-Degraded-Providers, X-Request-ID")
 
 		// Handle preflight request
 		if r.Method == http.MethodOptions {
@@ -40,8 +67,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Reject anything that isn't GET
-		if r.Method != http.MethodGet {
+		// Reject anything that isn't GET or POST
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
@@ -62,23 +89,50 @@ func parseBBox(param string) (*domain.BBox, error) {
 		return nil, fmt.Errorf("invalid bbox")
 	}
 
-	minLat, _ := strconv.ParseFloat(parts[0], 64)
-	minLng, _ := strconv.ParseFloat(parts[1], 64)
-	maxLat, _ := strconv.ParseFloat(parts[2], 64)
-	maxLng, _ := strconv.ParseFloat(parts[3], 64)
+	var v [4]float64
+
+	for i, part := range parts {
+
+		f, err := strconv.ParseFloat(part, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox")
+		}
+
+		v[i] = f
+	}
+
+	bbox := &domain.BBox{MinLat: v[0], MinLng: v[1], MaxLat: v[2], MaxLng: v[3]}
 
-	return &domain.BBox{
-		MinLat: minLat,
-		MinLng: minLng,
-		MaxLat: maxLat,
-		MaxLng: maxLng,
-	}, nil
+	if err := bbox.Validate(); err != nil {
+		return nil, err
+	}
+
+	return bbox, nil
+}
+
+// validateQuery rejects queries out of the accepted bounds, including
+// areas too large to cover with cache tiles.
+func validateQuery(query domain.SearchQuery) error {
+
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	return cache.CheckArea(query)
 }
 
 const (
 	defaultPage     = 1
 	defaultPageSize = 20
 	maxPageSize     = 100
+
+	// poiIndexSize bounds the POIs kept for lookup by ID.
+	poiIndexSize = 100000
+
+	// shutdownTimeout is how long in-flight requests get to finish on
+	// SIGINT or SIGTERM.
+	shutdownTimeout = 30 * time.Second
 )
 
 func searchHandler(w http.ResponseWriter, r *http.Request) {
@@ -93,6 +147,37 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 			Observe(time.Since(start).Seconds())
 	}()
 
+	query, page, pageSize, err := parseSearchRequest(r)
+
+	if err != nil {
+
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	logQuery(r.Context(), query, page, pageSize)
+
+	result, err := orch.Load().SearchStream(r.Context(), query, nil)
+
+	logResult(r.Context(), result, err)
+
+	if err != nil {
+		http.Error(w, err.Error(), searchErrorStatus(err))
+		return
+	}
+
+	setResultHeaders(w, result)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(paginate(result, page, pageSize)); err != nil {
+		http.Error(w, err.Error(), 500)
+	}
+}
+
+// parseSearchRequest reads the search and pagination parameters shared
+// by the JSON and streaming search endpoints.
+func parseSearchRequest(r *http.Request) (domain.SearchQuery, int, int, error) {
+
 	lat, _ := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
 	lng, _ := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
 
@@ -109,9 +194,7 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 	bbox, err := parseBBox(bboxParam)
 
 	if err != nil {
-
-		http.Error(w, "invalid bbox", 400)
-		return
+		return domain.SearchQuery{}, 0, 0, err
 	}
 
 	page := parseIntParam(r.URL.Query().Get("page"), defaultPage)
@@ -138,11 +221,31 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 		Categories: categories,
 	}
 
-	results, err := orch.Search(query)
+	if err := validateQuery(query); err != nil {
+		return domain.SearchQuery{}, 0, 0, err
+	}
 
-	if err != nil {
-		http.Error(w, err.Error(), 500)
-		return
+	return query, page, pageSize, nil
+}
+
+// searchErrorStatus maps a failed search to an HTTP status: 503 when no
+// provider could be asked, so clients can tell it apart from an area
+// with nothing in it, which is a 200 with empty data.
+func searchErrorStatus(err error) int {
+
+	if errors.Is(err, orchestrator.ErrAllProvidersFailed) || errors.Is(err, orchestrator.ErrCallBudgetExhausted) {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+func paginate(result domain.SearchResult, page int, pageSize int) domain.PaginatedResponse {
+
+	results := result.POIs
+
+	if results == nil {
+		results = []domain.POI{}
 	}
 
 	total := len(results)
@@ -163,17 +266,36 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 		end_idx = total
 	}
 
-	paginated := domain.PaginatedResponse{
+	return domain.PaginatedResponse{
 		Data:       results[start_idx:end_idx],
 		Total:      total,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
+		Complete:   result.Complete,
+		Cached:     result.Cached,
+		Providers:  result.Providers,
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(paginated); err != nil {
-		http.Error(w, err.Error(), 500)
+// setResultHeaders mirrors the result envelope in X-Hynek-* headers so
+// clients and proxies can tell degraded answers apart without parsing.
+func setResultHeaders(w http.ResponseWriter, result domain.SearchResult) {
+
+	if result.Complete {
+		w.Header().Set("X-Hynek-Result", "complete")
+	} else {
+		w.Header().Set("X-Hynek-Result", "degraded")
+	}
+
+	if result.Cached {
+		w.Header().Set("X-Hynek-Cache", "hit")
+	} else {
+		w.Header().Set("X-Hynek-Cache", "miss")
+	}
+
+	if degraded := result.DegradedProviders(); len(degraded) > 0 {
+		w.Header().Set("X-Hynek-Degraded-Providers", strings.Join(degraded, ","))
 	}
 }
 
@@ -192,13 +314,32 @@ func parseIntParam(s string, defaultVal int) int {
 	return v
 }
 
-func main() {
+// pipeline holds the parts of the search pipeline that outlive config
+// reloads. Optional parts are nil when disabled.
+type pipeline struct {
+	cache    cache.Cache
+	index    *cache.POIIndex
+	recorder orchestrator.TileRecorder
+	store    *poistore.Store
+	rules    *overrides.Engine
+	identity *identity.Registry
+	health   *health.Monitor
+	costs    *cost.Ledger
+
+	// providers keeps breakers, rate limits and hedging state across
+	// rebuilds
+	providers *provider.Registry
+}
 
-	cfg := config.Load()
+func buildOrchestrator(cfg *config.Config, parts pipeline) *orchestrator.CachedOrchestrator {
 
-	metrics.Register()
+	var meter provider.Meter
+
+	if parts.costs != nil {
+		meter = parts.costs
+	}
 
-	registered := provider.BuildProviders(cfg.Providers)
+	registered := parts.providers.Build(cfg.Providers, meter)
 
 	var providers []provider.Provider
 
@@ -213,43 +354,448 @@ func main() {
 
 	ranking.SetProviderPriorities(priorities)
 
+	if parts.health != nil {
+		parts.health.SetProviders(registered)
+	}
+
 	parallel := orchestrator.NewParallel(
 		providers,
-		3*time.Second,
+		cfg.Router.Timeout,
 	)
 
-	memoryCache := cache.NewMemoryCache()
+	if parts.store != nil {
+
+		parallel.SetIngester(parts.store)
+
+		if cfg.Store.Fallback {
+			parallel.SetFallback(parts.store)
+		}
+	}
+
+	if parts.identity != nil {
+		parallel.SetIdentity(parts.identity)
+	}
+
+	if parts.health != nil {
+		parallel.SetHealth(parts.health)
+	}
+
+	if parts.costs != nil {
+		parallel.SetCosts(parts.costs)
+	}
+
+	var search orchestrator.Orchestrator = parallel
+
+	if cfg.Router.Strategy == "tiered" {
+
+		search = orchestrator.NewTiered(parallel, tiers(registered), orchestrator.TierPolicy{
+			MinResults:         cfg.Router.Tiered.MinResults,
+			MinCompleteness:    cfg.Router.Tiered.MinCompleteness,
+			CompletenessFields: cfg.Router.Tiered.CompletenessFields,
+			Categories:         cfg.Router.Tiered.Categories,
+			EscalationTime:     cfg.Router.Tiered.EscalationTime,
+		})
+	}
 
-	redisCache := cache.NewRedisCache(
-		cfg.Redis.Addr,
-		cfg.Redis.Password,
-		cfg.Redis.DB,
+	cached := orchestrator.NewCached(
+		search,
+		parts.cache,
+		cfg.Cache.TTL,
 	)
 
-	healthChecker := health.New(redisCache.Client())
+	cached.SetDegradedTTL(cfg.Cache.DegradedTTL)
+	cached.SetEmptyTTL(cfg.Cache.EmptyTTL)
+	cached.SetNegativeTTL(cfg.Cache.NegativeTTL, cfg.Cache.NegativeMaxTTL)
+	cached.SetIndex(parts.index)
+
+	if parts.recorder != nil {
+		cached.SetRecorder(parts.recorder)
+	}
+
+	if parts.rules != nil {
+		cached.SetOverrides(parts.rules)
+	}
+
+	return cached
+}
+
+// tiers groups the providers by tier, lowest first.
+func tiers(registered []provider.RegisteredProvider) [][]provider.Provider {
+
+	byTier := map[int][]provider.Provider{}
+
+	for _, rp := range registered {
+		byTier[rp.Tier] = append(byTier[rp.Tier], rp.Provider)
+	}
+
+	levels := make([]int, 0, len(byTier))
+
+	for tier := range byTier {
+		levels = append(levels, tier)
+	}
+
+	sort.Ints(levels)
+
+	grouped := make([][]provider.Provider, len(levels))
+
+	for i, tier := range levels {
+		grouped[i] = byTier[tier]
+	}
+
+	return grouped
+}
+
+// loadOverrideFile replaces the engine's file rules with those at path;
+// an empty path means no file rules.
+func loadOverrideFile(rules *overrides.Engine, path string) error {
+
+	var fileRules []overrides.Rule
+
+	if path != "" {
+
+		var err error
+
+		if fileRules, err = overrides.LoadFile(path); err != nil {
+			return err
+		}
+	}
+
+	return rules.SetFileRules(fileRules)
+}
+
+// serveGRPC starts the gRPC API in the background and returns its server
+// for shutdown.
+func serveGRPC(port int, guard *access.Guard, index *cache.POIIndex) *grpc.Server {
+
+	service := grpcapi.NewService(
+		func() orchestrator.StreamingOrchestrator { return orch.Load() },
+		index,
+	)
+
+	addr := ":" + strconv.Itoa(port)
+
+	listener, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		fatal("grpc listen", err)
+	}
+
+	slog.Info("Hynek POI gRPC listening", "addr", addr)
+
+	server := grpcapi.NewServer(service, guard)
+
+	go func() {
+
+		// Serve returns nil once the server is stopped
+		if err := server.Serve(listener); err != nil {
+			fatal("grpc serve", err)
+		}
+	}()
+
+	return server
+}
+
+// shutdown stops taking requests and waits up to shutdownTimeout for
+// those in flight, then closes the capture file and the POI store so the
+// records and answers they queued are written.
+func shutdown(server *http.Server, grpcServer *grpc.Server, capturer *capture.Writer, store *poistore.Store) {
+
+	slog.Info("shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+
+	go func() {
+
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+
+		close(stopped)
+	}()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("http shutdown", "error", err)
+	}
+
+	select {
+
+	case <-stopped:
+
+	case <-ctx.Done():
+
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+	}
+
+	if capturer != nil {
+		if err := capturer.Close(); err != nil {
+			slog.Error("capture close", "error", err)
+		}
+	}
+
+	if store != nil {
+		if err := store.Close(); err != nil {
+			slog.Error("poi store close", "error", err)
+		}
+	}
+}
+
+func main() {
+
+	cfg := config.Load()
+
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid config", err)
+	}
+
+	if err := logging.Setup(cfg.Log, os.Stderr); err != nil {
+		fatal("log", err)
+	}
+
+	metrics.Register()
+
+	memoryCache := cache.NewMemoryCache()
+
+	redisClient, err := cache.NewRedisClient(cfg.Redis)
+
+	if err != nil {
+		fatal("redis", err)
+	}
+
+	serializer, err := cache.NewSerializer(cfg.Cache.Codec, cfg.Cache.Compression, cfg.Cache.CompressThreshold)
+
+	if err != nil {
+		fatal("cache", err)
+	}
+
+	redisCache := cache.NewRedisCache(redisClient, serializer, cfg.Redis)
+
+	healthChecker := health.New(redisClient, cfg.Redis.Required)
+
+	monitor := health.NewMonitor(cfg.Health)
+
+	healthChecker.Monitor = monitor
+	healthChecker.Readiness = cfg.Health.Readiness
 
 	layeredCache := cache.NewLayeredCache(
 		memoryCache,
 		redisCache,
 	)
 
-	orch = orchestrator.NewCached(
-		parallel,
-		layeredCache,
-		cfg.Cache.TTL,
+	poiIndex := cache.NewPOIIndex(cfg.Cache.TTL, poiIndexSize)
+
+	var recorder orchestrator.TileRecorder
+
+	var warmer *warming.Warmer
+
+	if cfg.Warming.Enabled {
+
+		store := warming.NewRedisStore(redisClient)
+		tracker := warming.NewTracker(store)
+
+		recorder = tracker
+		warmer = warming.NewWarmer(store, tracker, func() warming.Refresher { return orch.Load() }, cfg.Warming)
+	}
+
+	var poiStore *poistore.Store
+
+	if cfg.Store.Enabled {
+
+		poiStore, err = poistore.Open(cfg.Store.Path)
+
+		if err != nil {
+			fatal("poi store", err)
+		}
+
+		go poiStore.Maintain(context.Background(), cfg.Store.Retention, cfg.Store.CompactionInterval)
+	}
+
+	var rules *overrides.Engine
+
+	if cfg.Overrides.Enabled {
+
+		rules = overrides.NewEngine(overrides.NewRedisStore(redisClient))
+
+		if err := loadOverrideFile(rules, cfg.Overrides.Path); err != nil {
+			fatal("overrides", err)
+		}
+
+		if err := rules.Refresh(context.Background()); err != nil {
+			slog.Warn("overrides: admin rules unavailable, retrying", "every", cfg.Overrides.RefreshInterval.String(), "error", err)
+		}
+
+		go rules.Run(context.Background(), cfg.Overrides.RefreshInterval)
+	}
+
+	var ids *identity.Registry
+
+	if cfg.Identity.Enabled {
+		ids = identity.NewRegistry(identity.NewRedisStore(redisClient), cfg.Identity.CacheSize)
+	}
+
+	var ledger *cost.Ledger
+
+	if cfg.Costs.Enabled {
+
+		ledger = cost.NewLedger(cost.NewRedisStore(redisClient), cfg.Costs)
+
+		monitor.SetCosts(ledger)
+
+		go ledger.Run(context.Background())
+	}
+
+	parts := pipeline{
+		cache:    layeredCache,
+		index:    poiIndex,
+		recorder: recorder,
+		store:    poiStore,
+		rules:    rules,
+		identity: ids,
+		health:   monitor,
+		costs:    ledger,
+
+		providers: provider.NewRegistry(),
+	}
+
+	orch.Store(buildOrchestrator(cfg, parts))
+	batchConfig.Store(&cfg.Batch)
+
+	if warmer != nil {
+		go warmer.Run(context.Background())
+	}
+
+	if cfg.Health.Canary.Enabled {
+		go monitor.RunCanaries(context.Background(), cfg.Health.Canary)
+	}
+
+	reloader := &configReloader{
+		active: cfg,
+		parts:  parts,
+	}
+
+	config.Watch(reloader.Reload)
+
+	guard := access.NewGuard(
+		cfg.Server.APIKeys,
+		cfg.Server.RateLimit,
+		cfg.Server.RateLimitBurst,
 	)
 
+	var grpcServer *grpc.Server
+
+	if cfg.GRPC.Enabled {
+		grpcServer = serveGRPC(cfg.GRPC.Port, guard, poiIndex)
+	}
+
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/v1/search", searchHandler)
+	var search http.Handler = http.HandlerFunc(searchHandler)
+
+	var capturer *capture.Writer
+
+	if cfg.Capture.Enabled {
+
+		var err error
+
+		capturer, err = capture.Open(cfg.Capture.Path, cfg.Capture.SampleRate, cfg.Capture.MaxBytes)
+
+		if err != nil {
+			fatal("capture", err)
+		}
+
+		search = capturer.Middleware(search)
+	}
+
+	mux.Handle("/v1/search", guard.Middleware(search))
+	mux.Handle("/v1/search/stream", guard.Middleware(http.HandlerFunc(streamHandler)))
+	mux.Handle("/v1/search/batch", guard.Middleware(http.HandlerFunc(batchHandler)))
+	mux.Handle("/v1/status", guard.Middleware(http.HandlerFunc(healthChecker.StatusHandler)))
+
+	if ids != nil {
+		mux.Handle("/v1/ids", guard.Middleware(idsHandler(ids)))
+	}
+
+	if cfg.GraphQL.Enabled {
+
+		graphqlHandler, err := gql.NewHandler(
+			func() orchestrator.StreamingOrchestrator { return orch.Load() },
+			poiIndex,
+			cfg.GraphQL.MaxComplexity,
+		)
+
+		if err != nil {
+			fatal("graphql schema", err)
+		}
+
+		mux.Handle("/v1/graphql", guard.Middleware(graphqlHandler))
+	}
+
+	// every replica listens, so a purge through any admin API reaches
+	// all instance-local caches
+	bus := cache.NewInvalidationBus(redisClient, memoryCache, poiIndex)
+
+	go bus.Run(context.Background())
+
+	if cfg.Admin.Enabled {
+
+		adminGuard := access.NewGuard(cfg.Admin.APIKeys, 0, 0)
+
+		mux.Handle("/admin/cache/invalidate", adminGuard.Middleware(invalidateHandler(layeredCache, poiIndex, bus)))
+
+		if ledger != nil {
+			mux.Handle("/admin/costs", adminGuard.Middleware(costsHandler(ledger)))
+		}
+
+		if rules != nil {
+			mux.Handle("/admin/overrides", adminGuard.Middleware(overridesHandler(rules)))
+			mux.Handle("/admin/overrides/audit", adminGuard.Middleware(overrideAuditHandler(rules)))
+		}
+	}
+
 	mux.HandleFunc("/health", healthChecker.HealthHandler)
 	mux.HandleFunc("/ready", healthChecker.ReadyHandler)
+	mux.HandleFunc("/openapi.json", openapiHandler)
 
 	mux.Handle("/metrics", promhttp.Handler())
 
+	spec, err := contract.Load(openapi.Document)
+
+	if err != nil {
+		fatal("openapi", err)
+	}
+
 	addr := ":" + strconv.Itoa(cfg.Server.Port)
 
-	log.Println("Hynek POI listening on", addr)
+	slog.Info("Hynek POI listening", "addr", addr)
+
+	handler := corsMiddleware(spec.Middleware(contract.Mode(cfg.OpenAPI.Validation), mux))
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: logging.Middleware(handler, "/health", "/ready", "/metrics"),
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			fatal("http serve", err)
+		}
+	}()
+
+	<-stop
+
+	shutdown(server, grpcServer, capturer, poiStore)
+}
+
+// fatal logs a startup or serving failure and exits.
+func fatal(msg string, err error) {
 
-	log.Fatal(http.ListenAndServe(addr, corsMiddleware(mux)))
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
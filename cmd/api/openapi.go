@@ -0,0 +1,14 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/hynek-systems/hynek-poi/api/openapi"
+)
+
+// openapiHandler serves the OpenAPI document of the HTTP API.
+func openapiHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openapi.Document)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"reflect"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/logging"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// configReloader rebuilds the search pipeline from a freshly read config.
+// The cache and the providers' breakers, rate limits and hedging state
+// are kept across reloads; providers, priorities, tiers, routing, cache
+// TTL, rate limits, batch limits, the store fallback, the override rules
+// file, log level and sampling take effect immediately.
+// Server, Redis, cache codec, warming, identity, capture, OpenAPI
+// validation, log format, health, costs and other store and override
+// settings still need a restart.
+type configReloader struct {
+	active *config.Config
+	parts  pipeline
+}
+
+func (r *configReloader) Reload() {
+
+	cfg, err := config.Reload()
+
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("failure").Inc()
+		slog.Error("config reload rejected, keeping previous config", "error", err)
+		return
+	}
+
+	if !reflect.DeepEqual(cfg.Server, r.active.Server) || cfg.GRPC != r.active.GRPC {
+		restartRequired("server")
+	}
+
+	if !reflect.DeepEqual(cfg.Redis, r.active.Redis) {
+		restartRequired("redis")
+	}
+
+	if cfg.Cache.Codec != r.active.Cache.Codec || cfg.Cache.Compression != r.active.Cache.Compression || cfg.Cache.CompressThreshold != r.active.Cache.CompressThreshold {
+		restartRequired("cache codec")
+	}
+
+	if !reflect.DeepEqual(cfg.Warming, r.active.Warming) {
+		restartRequired("warming")
+	}
+
+	storeRestart := cfg.Store
+	storeRestart.Fallback = r.active.Store.Fallback
+
+	if storeRestart != r.active.Store {
+		restartRequired("store")
+	}
+
+	if cfg.Overrides.Enabled != r.active.Overrides.Enabled || cfg.Overrides.RefreshInterval != r.active.Overrides.RefreshInterval {
+		restartRequired("overrides")
+	}
+
+	if cfg.Identity != r.active.Identity {
+		restartRequired("identity")
+	}
+
+	if cfg.Capture != r.active.Capture {
+		restartRequired("capture")
+	}
+
+	if cfg.OpenAPI != r.active.OpenAPI {
+		restartRequired("openapi")
+	}
+
+	if cfg.Health != r.active.Health {
+		restartRequired("health")
+	}
+
+	if !reflect.DeepEqual(cfg.Costs, r.active.Costs) {
+		restartRequired("costs")
+	}
+
+	if cfg.Log.Format != r.active.Log.Format {
+		restartRequired("log format")
+	}
+
+	if err := logging.Apply(cfg.Log); err != nil {
+		slog.Warn("config reload: keeping previous log settings", "error", err)
+	}
+
+	if r.parts.rules != nil {
+		if err := loadOverrideFile(r.parts.rules, cfg.Overrides.Path); err != nil {
+			slog.Warn("config reload: keeping previous override file rules", "error", err)
+		}
+	}
+
+	next := buildOrchestrator(cfg, r.parts)
+	next.KeepFailures(orch.Load())
+
+	orch.Store(next)
+	batchConfig.Store(&cfg.Batch)
+
+	r.active = cfg
+
+	metrics.ConfigReloads.WithLabelValues("success").Inc()
+	slog.Info("config reloaded")
+}
+
+func restartRequired(settings string) {
+	slog.Warn("config reload: settings changed, restart required to apply", "settings", settings)
+}
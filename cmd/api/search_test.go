@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+type fixedProvider struct {
+	pois []domain.POI
+	err  error
+}
+
+func (p *fixedProvider) Name() string {
+	return "fixed"
+}
+
+func (p *fixedProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	return p.pois, p.err
+}
+
+func search(p provider.Provider) *httptest.ResponseRecorder {
+
+	orch.Store(orchestrator.NewCached(
+		orchestrator.NewParallel([]provider.Provider{p}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	))
+
+	rec := httptest.NewRecorder()
+
+	searchHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/search?lat=59.3293&lng=18.0686", nil))
+
+	return rec
+}
+
+func TestSearchHandler_EmptyAreaIsOK(t *testing.T) {
+
+	rec := search(&fixedProvider{})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if !strings.Contains(rec.Body.String(), `"data":[]`) {
+		t.Errorf("Expected empty data array, got %s", rec.Body.String())
+	}
+}
+
+func TestSearchHandler_NoProviderIsUnavailable(t *testing.T) {
+
+	rec := search(&fixedProvider{err: errors.New("boom")})
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSearchHandler_RejectsInvalidBBox(t *testing.T) {
+
+	orch.Store(orchestrator.NewCached(
+		orchestrator.NewParallel([]provider.Provider{&fixedProvider{}}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	))
+
+	for _, bbox := range []string{
+		"59.34,18.05,59.32,18.08",
+		"59.32,abc,59.34,18.08",
+		"-80,-170,80,170",
+	} {
+
+		rec := httptest.NewRecorder()
+
+		searchHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/search?bbox="+bbox, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for bbox %s, got %d: %s", bbox, rec.Code, rec.Body.String())
+		}
+	}
+}
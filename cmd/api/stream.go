@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/dedupe"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/ranking"
+)
+
+// snapshotEvent is the ranked view of everything received so far, capped
+// at page_size so slow clients are not sent the whole set repeatedly.
+type snapshotEvent struct {
+	Data  []domain.POI `json:"data"`
+	Total int          `json:"total"`
+}
+
+type errorEvent struct {
+	Error string `json:"error"`
+}
+
+// streamHandler serves /v1/search/stream as Server-Sent Events:
+//
+//	provider  one per provider as it completes, with its status
+//	snapshot  deduplicated and ranked results received so far
+//	result    the final page with per-provider status, as /v1/search
+//	error     the search failed; no result follows
+//
+// Cache hits send only the result event.
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+
+	start := time.Now()
+
+	metrics.RequestsTotal.WithLabelValues("/v1/search/stream").Inc()
+
+	defer func() {
+		metrics.RequestDuration.
+			WithLabelValues("/v1/search/stream").
+			Observe(time.Since(start).Seconds())
+	}()
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		http.Error(w, "streaming unsupported", 500)
+		return
+	}
+
+	query, page, pageSize, err := parseSearchRequest(r)
+
+	if err != nil {
+
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	logQuery(r.Context(), query, page, pageSize)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event string, payload interface{}) {
+
+		if err := writeEvent(w, event, payload); err != nil {
+			slog.DebugContext(r.Context(), "stream: write event", "event", event, "error", err)
+			return
+		}
+
+		flusher.Flush()
+	}
+
+	pipeline := orch.Load()
+
+	var received []domain.POI
+
+	observe := func(update orchestrator.ProviderUpdate) {
+
+		send("provider", update.Status)
+
+		if len(update.POIs) == 0 {
+			return
+		}
+
+		received = append(received, update.POIs...)
+
+		ranked := ranking.Rank(pipeline.ApplyOverrides(query, dedupe.Deduplicate(received)), query)
+
+		snapshot := snapshotEvent{Data: ranked, Total: len(ranked)}
+
+		if len(snapshot.Data) > pageSize {
+			snapshot.Data = snapshot.Data[:pageSize]
+		}
+
+		send("snapshot", snapshot)
+	}
+
+	// the final result goes through CachedOrchestrator, so a completed
+	// stream populates the cache just like /v1/search
+	result, err := pipeline.SearchStream(r.Context(), query, observe)
+
+	logResult(r.Context(), result, err)
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	if err != nil {
+		send("error", errorEvent{Error: err.Error()})
+		return
+	}
+
+	send("result", paginate(result, page, pageSize))
+}
+
+func writeEvent(w http.ResponseWriter, event string, payload interface{}) error {
+
+	data, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+
+	return err
+}
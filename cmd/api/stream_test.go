@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func readEvents(t *testing.T, url string) []sseEvent {
+
+	resp, err := http.Get(url)
+
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	var events []sseEvent
+
+	var current sseEvent
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+
+		line := scanner.Text()
+
+		switch {
+
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+
+		case line == "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+
+	return events
+}
+
+func TestStreamHandler_EventsThenCachedResult(t *testing.T) {
+
+	cached := orchestrator.NewCached(
+		orchestrator.NewParallel([]provider.Provider{&provider.MockProvider{}}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	)
+
+	orch.Store(cached)
+
+	server := httptest.NewServer(http.HandlerFunc(streamHandler))
+	defer server.Close()
+
+	url := server.URL + "/v1/search/stream?lat=59.33&lng=18.07"
+
+	events := readEvents(t, url)
+
+	var names []string
+
+	for _, e := range events {
+		names = append(names, e.name)
+	}
+
+	if strings.Join(names, ",") != "provider,snapshot,result" {
+		t.Fatalf("Expected provider,snapshot,result, got %v", names)
+	}
+
+	var snapshot snapshotEvent
+
+	if err := json.Unmarshal([]byte(events[1].data), &snapshot); err != nil || snapshot.Total != 1 {
+		t.Errorf("Expected snapshot with 1 POI, got %s (%v)", events[1].data, err)
+	}
+
+	var result domain.PaginatedResponse
+
+	if err := json.Unmarshal([]byte(events[2].data), &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+
+	if !result.Complete || result.Cached || len(result.Providers) != 1 {
+		t.Errorf("Expected complete uncached result with provider status, got %+v", result)
+	}
+
+	events = readEvents(t, url)
+
+	if len(events) != 1 || events[0].name != "result" {
+		t.Fatalf("Expected only a result event on cache hit, got %v", events)
+	}
+
+	if err := json.Unmarshal([]byte(events[0].data), &result); err != nil || !result.Cached {
+		t.Errorf("Expected cached result, got %s (%v)", events[0].data, err)
+	}
+}
+
+func TestStreamHandler_InvalidBBox(t *testing.T) {
+
+	rec := httptest.NewRecorder()
+
+	streamHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/search/stream?bbox=1,2", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// breaker is one circuit breaker as the instance's metrics report it.
+type breaker struct {
+	name   string
+	state  circuitbreaker.State
+	opened int
+}
+
+func runBreakers(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("breakers", flag.ContinueOnError)
+
+	url := fs.String("url", "http://localhost:8080", "base URL of the instance")
+	timeout := fs.Duration("timeout", 5*time.Second, "request timeout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+
+	resp, err := client.Get(strings.TrimRight(*url, "/") + "/metrics")
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metrics: %s", resp.Status)
+	}
+
+	breakers, err := parseBreakers(resp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "BREAKER\tSTATE\tTIMES OPENED")
+
+	for _, b := range breakers {
+
+		opened := fmt.Sprint(b.opened)
+
+		if b.opened < 0 {
+			opened = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", b.name, b.state, opened)
+	}
+
+	return w.Flush()
+}
+
+// parseBreakers reads provider and Redis breaker states from metrics in
+// the Prometheus text format. Times opened count since the instance
+// started; the Redis breaker does not report them.
+func parseBreakers(r io.Reader) ([]breaker, error) {
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+
+	families, err := parser.TextToMetricFamilies(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+
+	opened := map[string]int{}
+
+	if f, ok := families["hynek_poi_circuit_breaker_transitions_total"]; ok {
+		for _, m := range f.GetMetric() {
+			if label(m, "to") == circuitbreaker.StateOpen.String() {
+				opened[label(m, "provider")] += int(m.GetCounter().GetValue())
+			}
+		}
+	}
+
+	var breakers []breaker
+
+	if f, ok := families["hynek_poi_circuit_breaker_state"]; ok {
+		for _, m := range f.GetMetric() {
+
+			name := label(m, "provider")
+
+			breakers = append(breakers, breaker{
+				name:   name,
+				state:  circuitbreaker.State(m.GetGauge().GetValue()),
+				opened: opened[name],
+			})
+		}
+	}
+
+	sort.Slice(breakers, func(i, j int) bool { return breakers[i].name < breakers[j].name })
+
+	if f, ok := families["hynek_poi_redis_circuit_breaker_state"]; ok && len(f.GetMetric()) > 0 {
+		breakers = append(breakers, breaker{
+			name:   "redis",
+			state:  circuitbreaker.State(f.GetMetric()[0].GetGauge().GetValue()),
+			opened: -1,
+		})
+	}
+
+	return breakers, nil
+}
+
+func label(m *dto.Metric, name string) string {
+
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheTimeout bounds each cache command; scans of large keyspaces are
+// the slowest.
+const cacheTimeout = time.Minute
+
+func openCache(cfg *config.Config) (*cache.RedisCache, redis.UniversalClient, error) {
+
+	client, err := cache.NewRedisClient(cfg.Redis)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serializer, err := cache.NewSerializer(cfg.Cache.Codec, cfg.Cache.Compression, cfg.Cache.CompressThreshold)
+
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	return cache.NewRedisCache(client, serializer, cfg.Redis), client, nil
+}
+
+// queryFlags are the search parameters of commands that work out the
+// cache keys of a query.
+type queryFlags struct {
+	lat, lng   *float64
+	radius     *int
+	categories *string
+	bbox       *string
+}
+
+func addQueryFlags(fs *flag.FlagSet) queryFlags {
+
+	return queryFlags{
+		lat:        fs.Float64("lat", 0, "latitude of a query whose keys to use"),
+		lng:        fs.Float64("lng", 0, "longitude of a query whose keys to use"),
+		radius:     fs.Int("radius", 1000, "radius in meters of the query; the API always uses 1000"),
+		categories: fs.String("categories", "", "comma separated categories of the query"),
+		bbox:       fs.String("bbox", "", "min_lat,min_lng,max_lat,max_lng of the query"),
+	}
+}
+
+// query returns the query the flags describe, or nil if none was given.
+func (q queryFlags) query(fs *flag.FlagSet) (*domain.SearchQuery, error) {
+
+	given := false
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "lat" || f.Name == "lng" || f.Name == "bbox" {
+			given = true
+		}
+	})
+
+	if !given {
+		return nil, nil
+	}
+
+	query := &domain.SearchQuery{
+		Latitude:  *q.lat,
+		Longitude: *q.lng,
+		Radius:    *q.radius,
+	}
+
+	if *q.categories != "" {
+		query.Categories = strings.Split(*q.categories, ",")
+	}
+
+	if *q.bbox != "" {
+
+		bbox, err := parseBBox(*q.bbox)
+
+		if err != nil {
+			return nil, err
+		}
+
+		query.BBox = bbox
+	}
+
+	return query, nil
+}
+
+func runCacheKeys(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("cache keys", flag.ContinueOnError)
+
+	configPath := configFlag(fs)
+	match := fs.String("match", "poi:*", "SCAN pattern of keys to list")
+	limit := fs.Int("limit", 100, "maximum keys to list; 0 lists all")
+	q := addQueryFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query, err := q.query(fs)
+
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+
+	if err != nil {
+		return err
+	}
+
+	rc, client, err := openCache(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+
+	// each entry lists the keys one tile may be cached under, or a single
+	// matched key
+	var entries [][]string
+
+	if query != nil {
+
+		cover, err := cache.CoverQuery(*query)
+
+		if err != nil {
+			return err
+		}
+
+		for _, hash := range cover.Hashes {
+
+			key := cache.TileKey(hash, query.Categories)
+
+			entries = append(entries, []string{key, cache.DegradedKey(key), cache.FailedKey(key)})
+		}
+
+		fmt.Fprintf(out, "%d tiles at precision %d\n\n", len(entries), cover.Precision)
+
+	} else {
+
+		keys, err := rc.Keys(ctx, *match, *limit)
+
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			entries = append(entries, []string{key})
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "KEY\tTTL\tBYTES\tPOIS")
+
+	for _, keys := range entries {
+
+		cached := false
+
+		for _, key := range keys {
+
+			entry, found, err := rc.Inspect(ctx, key)
+
+			switch {
+
+			case err != nil && !found:
+				return err
+
+			case !found:
+				continue
+
+			case err != nil:
+				fmt.Fprintf(w, "%s\t%s\t%d\tundecodable\n", key, ttl(entry.TTL), entry.Bytes)
+
+			default:
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", key, ttl(entry.TTL), entry.Bytes, len(entry.POIs))
+			}
+
+			cached = true
+		}
+
+		if !cached {
+			fmt.Fprintf(w, "%s\t-\t-\tmissing\n", keys[0])
+		}
+	}
+
+	w.Flush()
+
+	if query == nil && *limit > 0 && len(entries) == *limit {
+		fmt.Fprintf(out, "\nfirst %d keys; raise -limit for more\n", *limit)
+	}
+
+	return nil
+}
+
+func runCacheInspect(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("cache inspect", flag.ContinueOnError)
+
+	configPath := configFlag(fs)
+	asJSON := fs.Bool("json", false, "print the POIs as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("cache inspect: exactly one key is required")
+	}
+
+	key := fs.Arg(0)
+
+	cfg, err := loadConfig(*configPath)
+
+	if err != nil {
+		return err
+	}
+
+	rc, client, err := openCache(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+
+	entry, found, err := rc.Inspect(ctx, key)
+
+	if !found && err == nil {
+		return fmt.Errorf("%s: not cached", key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	fmt.Fprintf(out, "key    %s\n", entry.Key)
+
+	if hash, categories, ok := cache.ParseTileKey(key); ok {
+		fmt.Fprintf(out, "tile   %s, categories %s\n", hash, orAll(categories))
+	}
+
+	fmt.Fprintf(out, "ttl    %s\n", ttl(entry.TTL))
+	fmt.Fprintf(out, "bytes  %d (%s, %s)\n", entry.Bytes, cfg.Cache.Codec, cfg.Cache.Compression)
+	fmt.Fprintf(out, "pois   %d\n\n", len(entry.POIs))
+
+	if *asJSON {
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		return enc.Encode(entry.POIs)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SOURCE\tID\tNAME\tCATEGORY\tLAT\tLNG")
+
+	for _, poi := range entry.POIs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.6f\t%.6f\n", poi.Source, poi.ID, poi.Name, poi.Category, poi.Latitude, poi.Longitude)
+	}
+
+	return w.Flush()
+}
+
+func runCachePurge(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("cache purge", flag.ContinueOnError)
+
+	configPath := configFlag(fs)
+
+	var sel cache.Selector
+
+	fs.StringVar(&sel.Key, "key", "", "purge one key")
+	fs.StringVar(&sel.Prefix, "prefix", "", "purge keys with this prefix")
+	fs.StringVar(&sel.Geohash, "geohash", "", "purge tiles overlapping this geohash")
+	fs.StringVar(&sel.Category, "category", "", "purge entries holding this category")
+	fs.StringVar(&sel.Source, "source", "", "purge entries holding POIs from this provider")
+	bbox := fs.String("bbox", "", "purge tiles overlapping min_lat,min_lng,max_lat,max_lng")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *bbox != "" {
+
+		b, err := parseBBox(*bbox)
+
+		if err != nil {
+			return err
+		}
+
+		sel.BBox = b
+	}
+
+	if err := sel.Validate(); err != nil {
+		return fmt.Errorf("cache purge: %w", err)
+	}
+
+	cfg, err := loadConfig(*configPath)
+
+	if err != nil {
+		return err
+	}
+
+	rc, client, err := openCache(cfg)
+
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheTimeout)
+	defer cancel()
+
+	removed, err := rc.Invalidate(sel)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "removed %d Redis entries\n", removed)
+
+	// replicas drop their in-memory copies and index entries on broadcast
+	if err := cache.NewInvalidationBus(client).Publish(ctx, sel); err != nil {
+		return fmt.Errorf("broadcast to replicas: %w", err)
+	}
+
+	fmt.Fprintln(out, "broadcast to replicas")
+
+	return nil
+}
+
+func ttl(d time.Duration) string {
+
+	if d < 0 {
+		return "none"
+	}
+
+	return d.Round(time.Second).String()
+}
+
+func orAll(categories []string) string {
+
+	if len(categories) == 0 {
+		return "all"
+	}
+
+	return strings.Join(categories, ",")
+}
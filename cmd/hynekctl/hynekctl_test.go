@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+)
+
+func writeConfig(t *testing.T, content string) string {
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestSearch_RawAndMapped(t *testing.T) {
+
+	fixtures, _ := filepath.Abs("../../internal/orchestrator/testdata/fixtures")
+
+	path := writeConfig(t, "providers:\n  fixtures:\n    mode: replay\n    dir: "+fixtures+"\n")
+
+	var out strings.Builder
+
+	err := run([]string{
+		"search", "-config", path, "-provider", "google",
+		"-lat", "50.0870", "-lng", "14.4208", "-radius", "300", "-limit", "10", "-categories", "restaurant",
+	}, &out)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := out.String()
+
+	for _, want := range []string{"== raw: GET https://maps.googleapis.com", "key=REDACTED", `"user_ratings_total": 6231`, "== mapped: 3 POIs", `"rating_count": 6231`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got\n%s", want, got)
+		}
+	}
+}
+
+func TestSearch_UnknownProvider(t *testing.T) {
+
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	err := run([]string{"search", "-config", path, "-provider", "here"}, &strings.Builder{})
+
+	if err == nil || !strings.Contains(err.Error(), `unknown provider "here"`) {
+		t.Errorf("Expected an unknown provider error, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+
+	path := writeConfig(t, "cache:\n  tll: 10m\n")
+
+	var out strings.Builder
+
+	if err := run([]string{"config", "validate", path}, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "unknown key cache.tll") || !strings.Contains(out.String(), "valid") {
+		t.Errorf("Expected a valid config with an unknown key, got %s", out.String())
+	}
+
+	if err := run([]string{"config", "validate", "-strict", path}, &strings.Builder{}); err == nil {
+		t.Error("Expected -strict to reject unknown keys, got nil")
+	}
+
+	invalid := writeConfig(t, "providers:\n  osm:\n    enabled: false\n")
+
+	if err := run([]string{"config", "validate", invalid}, &strings.Builder{}); err == nil || !strings.Contains(err.Error(), "no provider enabled") {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
+
+func TestParseBreakers(t *testing.T) {
+
+	metrics := `# TYPE hynek_poi_circuit_breaker_state gauge
+hynek_poi_circuit_breaker_state{provider="osm"} 0
+hynek_poi_circuit_breaker_state{provider="google"} 1
+# TYPE hynek_poi_circuit_breaker_transitions_total counter
+hynek_poi_circuit_breaker_transitions_total{from="closed",provider="google",to="open"} 2
+hynek_poi_circuit_breaker_transitions_total{from="half_open",provider="google",to="open"} 1
+hynek_poi_circuit_breaker_transitions_total{from="open",provider="google",to="half_open"} 2
+# TYPE hynek_poi_redis_circuit_breaker_state gauge
+hynek_poi_redis_circuit_breaker_state 2
+`
+
+	breakers, err := parseBreakers(strings.NewReader(metrics))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []breaker{
+		{name: "google", state: circuitbreaker.StateOpen, opened: 3},
+		{name: "osm", state: circuitbreaker.StateClosed},
+		{name: "redis", state: circuitbreaker.StateHalfOpen, opened: -1},
+	}
+
+	if len(breakers) != len(want) {
+		t.Fatalf("Expected %d breakers, got %v", len(want), breakers)
+	}
+
+	for i := range want {
+		if breakers[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], breakers[i])
+		}
+	}
+}
+
+func TestCachePurge_RequiresOneSelector(t *testing.T) {
+
+	err := run([]string{"cache", "purge", "-key", "a", "-source", "osm"}, &strings.Builder{})
+
+	if err == nil || !strings.Contains(err.Error(), "exactly one") {
+		t.Errorf("Expected a selector error, got %v", err)
+	}
+}
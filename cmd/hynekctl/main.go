@@ -0,0 +1,111 @@
+// Command hynekctl is the on-call tool for Hynek POI: it searches single
+// providers past the cache, inspects and purges cache entries, shows
+// circuit breaker states and validates config files.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+const usage = `usage: hynekctl <command> [flags]
+
+commands:
+  search           search one provider directly, bypassing the cache
+  cache keys       list cache keys, or the keys a query uses
+  cache inspect    show a cache entry
+  cache purge      purge cache entries on every replica
+  breakers         show circuit breaker states of a running instance
+  config validate  validate a config file
+
+Run "hynekctl <command> -h" for the flags of a command.
+`
+
+func main() {
+
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "hynekctl:", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+
+	command, rest := args[0], args[1:]
+
+	if (command == "cache" || command == "config") && len(rest) > 0 {
+		command, rest = command+" "+rest[0], rest[1:]
+	}
+
+	switch command {
+
+	case "search":
+		return runSearch(rest, out)
+
+	case "cache keys":
+		return runCacheKeys(rest, out)
+
+	case "cache inspect":
+		return runCacheInspect(rest, out)
+
+	case "cache purge":
+		return runCachePurge(rest, out)
+
+	case "breakers":
+		return runBreakers(rest, out)
+
+	case "config validate":
+		return runConfigValidate(rest, out)
+
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(out, usage)
+		return nil
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// configFlag registers -config, defaulting like the service to
+// HYNEK_POI_CONFIG_FILE or config.yaml.
+func configFlag(fs *flag.FlagSet) *string {
+
+	path := os.Getenv("HYNEK_POI_CONFIG_FILE")
+
+	if path == "" {
+		path = "config.yaml"
+	}
+
+	return fs.String("config", path, "config file")
+}
+
+// loadConfig reads and validates the config file at path.
+func loadConfig(path string) (*config.Config, error) {
+
+	cfg, _, err := config.LoadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
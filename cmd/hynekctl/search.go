@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/fixture"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+func runSearch(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+
+	configPath := configFlag(fs)
+	name := fs.String("provider", "", "provider to search: osm, google or foursquare")
+	lat := fs.Float64("lat", 0, "latitude")
+	lng := fs.Float64("lng", 0, "longitude")
+	radius := fs.Int("radius", 1000, "radius in meters")
+	limit := fs.Int("limit", 50, "maximum results")
+	categories := fs.String("categories", "", "comma separated categories")
+	bbox := fs.String("bbox", "", "min_lat,min_lng,max_lat,max_lng instead of lat, lng and radius")
+	output := fs.String("output", "both", "raw, mapped or both")
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("search: -provider is required")
+	}
+
+	if *output != "raw" && *output != "mapped" && *output != "both" {
+		return fmt.Errorf("search: -output must be raw, mapped or both, got %q", *output)
+	}
+
+	cfg, err := loadConfig(*configPath)
+
+	if err != nil {
+		return err
+	}
+
+	p, err := provider.NewBase(*name, cfg.Providers)
+
+	if err != nil {
+		return err
+	}
+
+	query := domain.SearchQuery{
+		Latitude:  *lat,
+		Longitude: *lng,
+		Radius:    *radius,
+		Limit:     *limit,
+	}
+
+	if *categories != "" {
+		query.Categories = strings.Split(*categories, ",")
+	}
+
+	if *bbox != "" {
+
+		if query.BBox, err = parseBBox(*bbox); err != nil {
+			return err
+		}
+	}
+
+	// keep what the provider sent and got, on top of any fixture transport
+	next := fixture.Transport(fixture.Mode(cfg.Providers.Fixtures.Mode), cfg.Providers.Fixtures.Dir, *name, nil)
+
+	tap := &tap{next: next}
+
+	if setter, ok := p.(interface{ SetTransport(http.RoundTripper) }); ok {
+		setter.SetTransport(tap)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	pois, searchErr := provider.SearchWithContext(ctx, p, query)
+
+	elapsed := time.Since(start)
+
+	if *output != "mapped" {
+
+		for _, ex := range tap.exchanges() {
+			ex.print(out)
+		}
+	}
+
+	if searchErr != nil {
+		return fmt.Errorf("search %s: %w", *name, searchErr)
+	}
+
+	if *output != "raw" {
+
+		fmt.Fprintf(out, "== mapped: %d POIs in %s\n", len(pois), elapsed.Round(time.Millisecond))
+
+		if pois == nil {
+			pois = []domain.POI{}
+		}
+
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		return enc.Encode(pois)
+	}
+
+	return nil
+}
+
+func parseBBox(s string) (*domain.BBox, error) {
+
+	parts := strings.Split(s, ",")
+
+	if len(parts) != 4 {
+		return nil, errors.New("bbox needs min_lat,min_lng,max_lat,max_lng")
+	}
+
+	var v [4]float64
+
+	for i, part := range parts {
+
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("bbox: %w", err)
+		}
+
+		v[i] = f
+	}
+
+	return &domain.BBox{MinLat: v[0], MinLng: v[1], MaxLat: v[2], MaxLng: v[3]}, nil
+}
+
+// exchange is one upstream request and the response to it, credentials
+// scrubbed.
+type exchange struct {
+	request fixture.Request
+	status  int
+	latency time.Duration
+	body    []byte
+	err     error
+}
+
+func (e exchange) print(out io.Writer) {
+
+	if e.err != nil {
+		fmt.Fprintf(out, "== raw: %s %s failed after %s: %v\n", e.request.Method, e.request.URL, e.latency.Round(time.Millisecond), e.err)
+		return
+	}
+
+	fmt.Fprintf(out, "== raw: %s %s (%d, %s, %d bytes)\n", e.request.Method, e.request.URL, e.status, e.latency.Round(time.Millisecond), len(e.body))
+
+	if e.request.Body != "" {
+		fmt.Fprintf(out, "-- request body\n%s\n-- response body\n", readable(e.request))
+	}
+
+	var pretty bytes.Buffer
+
+	if json.Indent(&pretty, e.body, "", "  ") == nil {
+		pretty.WriteTo(out)
+	} else {
+		out.Write(e.body)
+	}
+
+	fmt.Fprintln(out)
+}
+
+// readable decodes form bodies, such as Overpass queries, for display.
+func readable(req fixture.Request) string {
+
+	for _, ct := range req.Headers["Content-Type"] {
+
+		if !strings.HasPrefix(ct, "application/x-www-form-urlencoded") {
+			continue
+		}
+
+		form, err := url.ParseQuery(req.Body)
+
+		if err != nil {
+			break
+		}
+
+		var lines []string
+
+		for name, values := range form {
+			for _, v := range values {
+				lines = append(lines, name+"="+v)
+			}
+		}
+
+		sort.Strings(lines)
+
+		return strings.Join(lines, "\n")
+	}
+
+	return req.Body
+}
+
+// tap is a RoundTripper keeping every exchange it passes on.
+type tap struct {
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	seen []exchange
+}
+
+func (t *tap) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	next := t.next
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var body []byte
+
+	if req.Body != nil {
+
+		var err error
+
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+
+		req.Body.Close()
+
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	ex := exchange{request: fixture.Scrub(req, body)}
+
+	start := time.Now()
+
+	resp, err := next.RoundTrip(req)
+
+	ex.latency = time.Since(start)
+
+	if err == nil {
+
+		ex.status = resp.StatusCode
+
+		ex.body, err = io.ReadAll(resp.Body)
+
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(ex.body))
+	}
+
+	ex.err = err
+
+	t.mu.Lock()
+	t.seen = append(t.seen, ex)
+	t.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *tap) exchanges() []exchange {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]exchange(nil), t.seen...)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+func runConfigValidate(args []string, out io.Writer) error {
+
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+
+	configPath := configFlag(fs)
+	strict := fs.Bool("strict", false, "treat unknown keys as errors")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// a positional path reads more naturally than -config here
+	path := *configPath
+
+	if fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	cfg, unknown, err := config.LoadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range unknown {
+		fmt.Fprintf(out, "%s: unknown key %s\n", path, key)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if *strict && len(unknown) > 0 {
+		return fmt.Errorf("%s: %d unknown keys", path, len(unknown))
+	}
+
+	var enabled []string
+
+	for _, p := range []struct {
+		name string
+		on   bool
+	}{
+		{"osm", cfg.Providers.OSM.Enabled},
+		{"google", cfg.Providers.Google.Enabled},
+		{"foursquare", cfg.Providers.Foursquare.Enabled},
+	} {
+		if p.on {
+			enabled = append(enabled, p.name)
+		}
+	}
+
+	fmt.Fprintf(out, "%s: valid; providers %s; redis %s; fixtures %s\n", path, strings.Join(enabled, ", "), cfg.Redis.Mode, cfg.Providers.Fixtures.Mode)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+// Command replay plays a traffic capture written by the API back against
+// an instance and reports latency percentiles, error rates and the cache
+// hit ratio. Given a second instance it sends every request to both and
+// compares their result sets, exiting non-zero if any differ.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/capture"
+)
+
+func main() {
+
+	var opts options
+
+	file := flag.String("file", "data/requests.jsonl", "capture file written with capture.enabled")
+	flag.StringVar(&opts.target, "target", "http://localhost:8080", "base URL of the instance to replay against")
+	flag.StringVar(&opts.compare, "compare", "", "base URL of a second instance whose results are compared with target's")
+	flag.Float64Var(&opts.rate, "rate", 0, "requests per second; 0 keeps the captured pacing")
+	flag.Float64Var(&opts.speedup, "speedup", 1, "speed-up of the captured pacing when -rate is 0; 0 sends as fast as possible")
+	flag.IntVar(&opts.concurrency, "concurrency", 64, "maximum requests in flight per instance")
+	flag.IntVar(&opts.limit, "limit", 0, "replay at most this many requests; 0 replays all")
+	flag.StringVar(&opts.apiKey, "api-key", "", "sent as X-API-Key to every instance")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "per-request timeout")
+	diffs := flag.Int("diffs", 10, "differing requests to list")
+
+	flag.Parse()
+
+	if opts.rate < 0 || opts.speedup < 0 || opts.concurrency < 1 {
+		log.Fatal("replay: -rate and -speedup must not be negative, -concurrency must be at least 1")
+	}
+
+	f, err := os.Open(*file)
+
+	if err != nil {
+		log.Fatalf("replay: %v", err)
+	}
+
+	var records []capture.Record
+
+	err = capture.Read(f, func(r capture.Record) error {
+
+		if opts.limit == 0 || len(records) < opts.limit {
+			records = append(records, r)
+		}
+
+		return nil
+	})
+
+	f.Close()
+
+	if err != nil {
+		log.Fatalf("replay: %s: %v", *file, err)
+	}
+
+	if len(records) == 0 {
+		log.Fatalf("replay: %s holds no requests", *file)
+	}
+
+	client := &http.Client{Timeout: opts.timeout}
+
+	report := replay(client, records, opts)
+
+	report.print(os.Stdout, *diffs)
+
+	if opts.compare != "" && len(report.differences) > 0 {
+		fmt.Fprintf(os.Stderr, "replay: %d of %d compared requests differ\n", len(report.differences), report.compared)
+		os.Exit(1)
+	}
+}
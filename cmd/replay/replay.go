@@ -0,0 +1,149 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/capture"
+)
+
+type options struct {
+	target      string
+	compare     string
+	rate        float64
+	speedup     float64
+	concurrency int
+	limit       int
+	apiKey      string
+	timeout     time.Duration
+}
+
+// outcome is how one instance answered one request.
+type outcome struct {
+	latency time.Duration
+	status  int
+	err     error
+	cache   string
+	body    []byte
+}
+
+// replay sends records to the target, and to the compare instance if
+// set, on the schedule opts asks for and collects the outcomes. An
+// instance that falls behind holds up the schedule rather than exceeding
+// opts.concurrency.
+func replay(client *http.Client, records []capture.Record, opts options) *report {
+
+	rep := newReport(opts)
+
+	slots := make(chan struct{}, opts.concurrency)
+
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for i, record := range records {
+
+		if wait := time.Until(start.Add(offset(records, i, opts))); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		slots <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if opts.compare == "" {
+				rep.add(record, send(client, record.URL(opts.target), opts.apiKey, false), nil)
+				return
+			}
+
+			var other outcome
+
+			done := make(chan struct{})
+
+			go func() {
+				other = send(client, record.URL(opts.compare), opts.apiKey, true)
+				close(done)
+			}()
+
+			target := send(client, record.URL(opts.target), opts.apiKey, true)
+
+			<-done
+
+			rep.add(record, target, &other)
+		}()
+	}
+
+	wg.Wait()
+
+	rep.elapsed = time.Since(start)
+
+	return rep
+}
+
+// offset is when the i-th record is due after the replay starts.
+func offset(records []capture.Record, i int, opts options) time.Duration {
+
+	if opts.rate > 0 {
+		return time.Duration(float64(i) / opts.rate * float64(time.Second))
+	}
+
+	if opts.speedup == 0 {
+		return 0
+	}
+
+	captured := records[i].Time.Sub(records[0].Time)
+
+	if captured < 0 {
+		return 0
+	}
+
+	return time.Duration(float64(captured) / opts.speedup)
+}
+
+// send makes one request. The body is kept only for comparison; it is
+// always read so connections are reused.
+func send(client *http.Client, url string, apiKey string, keepBody bool) outcome {
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return outcome{err: err}
+	}
+
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	start := time.Now()
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return outcome{latency: time.Since(start), err: err}
+	}
+
+	defer resp.Body.Close()
+
+	var body []byte
+
+	if keepBody {
+		body, err = io.ReadAll(resp.Body)
+	} else {
+		_, err = io.Copy(io.Discard, resp.Body)
+	}
+
+	return outcome{
+		latency: time.Since(start),
+		status:  resp.StatusCode,
+		err:     err,
+		cache:   resp.Header.Get("X-Hynek-Cache"),
+		body:    body,
+	}
+}
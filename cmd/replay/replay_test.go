@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/capture"
+)
+
+// instance answers searches at lat=1 from the cache and fails those at
+// lat=9; extra adds a place to every answer.
+func instance(extra bool) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Query().Get("lat") == "9" {
+			http.Error(w, "all providers failed or timeout", http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.URL.Query().Get("lat") == "1" {
+			w.Header().Set("X-Hynek-Cache", "hit")
+		} else {
+			w.Header().Set("X-Hynek-Cache", "miss")
+		}
+
+		body := `{"total":1,"data":[{"source":"osm","id":"1"}]}`
+
+		if extra {
+			body = `{"total":2,"data":[{"source":"osm","id":"1"},{"source":"google","id":"g"}]}`
+		}
+
+		w.Write([]byte(body))
+	}))
+}
+
+func records(queries ...string) []capture.Record {
+
+	var out []capture.Record
+
+	for _, q := range queries {
+		out = append(out, capture.Record{Path: "/v1/search", Query: q})
+	}
+
+	return out
+}
+
+func TestReplay_Stats(t *testing.T) {
+
+	target := instance(false)
+	defer target.Close()
+
+	rep := replay(http.DefaultClient, records("lat=1&lng=1", "lat=1&lng=1", "lat=2&lng=2", "lat=9&lng=9"), options{
+		target:      target.URL,
+		concurrency: 2,
+	})
+
+	s := rep.target
+
+	if len(s.latencies) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(s.latencies))
+	}
+
+	if s.errors["503"] != 1 || s.failed() != 1 {
+		t.Errorf("Expected one 503, got %v", s.errors)
+	}
+
+	if s.hits != 2 || s.misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %d and %d", s.hits, s.misses)
+	}
+}
+
+func TestReplay_Compare(t *testing.T) {
+
+	target := instance(false)
+	defer target.Close()
+
+	other := instance(true)
+	defer other.Close()
+
+	rep := replay(http.DefaultClient, records("lat=1&lng=1", "lat=9&lng=9"), options{
+		target:      target.URL,
+		compare:     other.URL,
+		concurrency: 4,
+	})
+
+	if rep.compared != 2 {
+		t.Fatalf("Expected 2 compared requests, got %d", rep.compared)
+	}
+
+	// both failing lat=9 alike is not a difference
+	if len(rep.differences) != 1 {
+		t.Fatalf("Expected 1 difference, got %v", rep.differences)
+	}
+
+	if reason := rep.differences[0].reason; !strings.Contains(reason, "1 only on compare") || !strings.Contains(reason, "google/g") {
+		t.Errorf("Expected the extra place to be named, got %s", reason)
+	}
+
+	var out strings.Builder
+
+	rep.print(&out, 10)
+
+	if !strings.Contains(out.String(), "differing  1") {
+		t.Errorf("Expected the report to list the difference, got\n%s", out.String())
+	}
+}
+
+func TestCompare_Order(t *testing.T) {
+
+	a := outcome{status: 200, body: []byte(`{"total":2,"data":[{"source":"osm","id":"1"},{"source":"osm","id":"2"}]}`)}
+	b := outcome{status: 200, body: []byte(`{"total":2,"data":[{"source":"osm","id":"2"},{"source":"osm","id":"1"}]}`)}
+
+	if reason := compare(a, b); reason != "same places in a different order" {
+		t.Errorf("Expected an order difference, got %q", reason)
+	}
+
+	if reason := compare(a, a); reason != "" {
+		t.Errorf("Expected no difference, got %q", reason)
+	}
+}
+
+func TestOffset(t *testing.T) {
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	recs := []capture.Record{{Time: start}, {Time: start.Add(10 * time.Second)}}
+
+	if got := offset(recs, 1, options{speedup: 10}); got != time.Second {
+		t.Errorf("Expected 1s at ten times speed, got %s", got)
+	}
+
+	if got := offset(recs, 1, options{rate: 4, speedup: 10}); got != 250*time.Millisecond {
+		t.Errorf("Expected 250ms at 4 requests per second, got %s", got)
+	}
+
+	if got := offset(recs, 1, options{}); got != 0 {
+		t.Errorf("Expected no pause without speed-up, got %s", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+
+	var sorted []time.Duration
+
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+
+	if got := percentile(sorted, 99); got != 99*time.Millisecond {
+		t.Errorf("Expected p99 of 99ms, got %s", got)
+	}
+
+	if got := percentile(sorted, 50); got != 50*time.Millisecond {
+		t.Errorf("Expected p50 of 50ms, got %s", got)
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/capture"
+)
+
+// stats summarises how one instance answered.
+type stats struct {
+	name      string
+	url       string
+	latencies []time.Duration
+
+	// errors counts failed requests by HTTP status, or "transport"
+	errors map[string]int
+
+	hits   int
+	misses int
+}
+
+func (s *stats) add(o outcome) {
+
+	s.latencies = append(s.latencies, o.latency)
+
+	switch {
+
+	case o.err != nil:
+		s.errors["transport"]++
+
+	case o.status >= 400:
+		s.errors[strconv.Itoa(o.status)]++
+
+	case o.cache == "hit":
+		s.hits++
+
+	case o.cache == "miss":
+		s.misses++
+	}
+}
+
+func (s *stats) failed() int {
+
+	n := 0
+
+	for _, count := range s.errors {
+		n += count
+	}
+
+	return n
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
+// difference is a request the two instances answered differently.
+type difference struct {
+	record capture.Record
+	reason string
+}
+
+type report struct {
+	mu sync.Mutex
+
+	target *stats
+	other  *stats
+
+	compared    int
+	differences []difference
+
+	elapsed time.Duration
+}
+
+func newReport(opts options) *report {
+
+	rep := &report{
+		target: &stats{name: "target", url: opts.target, errors: map[string]int{}},
+	}
+
+	if opts.compare != "" {
+		rep.other = &stats{name: "compare", url: opts.compare, errors: map[string]int{}}
+	}
+
+	return rep
+}
+
+func (r *report) add(record capture.Record, target outcome, other *outcome) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.target.add(target)
+
+	if other == nil {
+		return
+	}
+
+	r.other.add(*other)
+
+	// nothing to compare when either side never answered
+	if target.err != nil || other.err != nil {
+		return
+	}
+
+	r.compared++
+
+	if reason := compare(target, *other); reason != "" {
+		r.differences = append(r.differences, difference{record: record, reason: reason})
+	}
+}
+
+// resultSet is the part of a search response compared between instances.
+type resultSet struct {
+	Total int `json:"total"`
+	Data  []struct {
+		Source string `json:"source"`
+		ID     string `json:"id"`
+	} `json:"data"`
+}
+
+// compare explains how two answers to the same request differ, or
+// returns "" if they do not.
+func compare(target outcome, other outcome) string {
+
+	if target.status != other.status {
+		return fmt.Sprintf("status %d on target, %d on compare", target.status, other.status)
+	}
+
+	if target.status != 200 {
+		return ""
+	}
+
+	var a, b resultSet
+
+	if err := json.Unmarshal(target.body, &a); err != nil {
+		return "unreadable target response: " + err.Error()
+	}
+
+	if err := json.Unmarshal(other.body, &b); err != nil {
+		return "unreadable compare response: " + err.Error()
+	}
+
+	keys := func(set resultSet) []string {
+
+		out := make([]string, len(set.Data))
+
+		for i, poi := range set.Data {
+			out[i] = poi.Source + "/" + poi.ID
+		}
+
+		return out
+	}
+
+	ka, kb := keys(a), keys(b)
+
+	onlyTarget, onlyOther := missing(ka, kb), missing(kb, ka)
+
+	switch {
+
+	case len(onlyTarget) > 0 || len(onlyOther) > 0:
+		return fmt.Sprintf("%d only on target, %d only on compare (%s)", len(onlyTarget), len(onlyOther), sample(onlyTarget, onlyOther))
+
+	case a.Total != b.Total:
+		return fmt.Sprintf("total %d on target, %d on compare", a.Total, b.Total)
+
+	case strings.Join(ka, ",") != strings.Join(kb, ","):
+		return "same places in a different order"
+	}
+
+	return ""
+}
+
+// missing lists the keys of a not in b.
+func missing(a []string, b []string) []string {
+
+	in := make(map[string]bool, len(b))
+
+	for _, k := range b {
+		in[k] = true
+	}
+
+	var out []string
+
+	for _, k := range a {
+		if !in[k] {
+			out = append(out, k)
+		}
+	}
+
+	return out
+}
+
+func sample(onlyTarget []string, onlyOther []string) string {
+
+	var parts []string
+
+	if len(onlyTarget) > 0 {
+		parts = append(parts, "e.g. +"+onlyTarget[0])
+	}
+
+	if len(onlyOther) > 0 {
+		parts = append(parts, "e.g. -"+onlyOther[0])
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (r *report) print(w io.Writer, diffs int) {
+
+	for _, s := range []*stats{r.target, r.other} {
+
+		if s != nil {
+			s.print(w, r.elapsed)
+		}
+	}
+
+	if r.other == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "results\n")
+	fmt.Fprintf(w, "  compared   %d\n", r.compared)
+	fmt.Fprintf(w, "  identical  %d\n", r.compared-len(r.differences))
+	fmt.Fprintf(w, "  differing  %d\n", len(r.differences))
+
+	for i, d := range r.differences {
+
+		if i == diffs {
+			fmt.Fprintf(w, "  ... and %d more\n", len(r.differences)-diffs)
+			break
+		}
+
+		fmt.Fprintf(w, "  %s: %s\n", d.record.URL(""), d.reason)
+	}
+}
+
+func (s *stats) print(w io.Writer, elapsed time.Duration) {
+
+	sorted := append([]time.Duration(nil), s.latencies...)
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	total := len(sorted)
+	failed := s.failed()
+
+	fmt.Fprintf(w, "%s %s\n", s.name, s.url)
+	fmt.Fprintf(w, "  requests   %d in %s (%.1f/s)\n", total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	fmt.Fprintf(w, "  errors     %d (%.1f%%)%s\n", failed, ratio(failed, total), s.breakdown())
+
+	fmt.Fprintf(w, "  latency    p50 %s  p90 %s  p95 %s  p99 %s  max %s\n",
+		ms(percentile(sorted, 50)),
+		ms(percentile(sorted, 90)),
+		ms(percentile(sorted, 95)),
+		ms(percentile(sorted, 99)),
+		ms(percentile(sorted, 100)),
+	)
+
+	fmt.Fprintf(w, "  cache hit  %.1f%% of %d answered\n", ratio(s.hits, s.hits+s.misses), s.hits+s.misses)
+}
+
+// breakdown lists error counts by cause, most frequent first.
+func (s *stats) breakdown() string {
+
+	if len(s.errors) == 0 {
+		return ""
+	}
+
+	causes := make([]string, 0, len(s.errors))
+
+	for cause := range s.errors {
+		causes = append(causes, cause)
+	}
+
+	sort.Slice(causes, func(i, j int) bool {
+
+		if s.errors[causes[i]] != s.errors[causes[j]] {
+			return s.errors[causes[i]] > s.errors[causes[j]]
+		}
+
+		return causes[i] < causes[j]
+	})
+
+	parts := make([]string, len(causes))
+
+	for i, cause := range causes {
+		parts[i] = fmt.Sprintf("%s: %d", cause, s.errors[cause])
+	}
+
+	return "  " + strings.Join(parts, ", ")
+}
+
+func ratio(n int, of int) float64 {
+
+	if of == 0 {
+		return 0
+	}
+
+	return 100 * float64(n) / float64(of)
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+}
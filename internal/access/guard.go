@@ -0,0 +1,89 @@
+package access
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/ratelimit"
+)
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid API key")
+	ErrRateLimited     = errors.New("client rate limit exceeded")
+)
+
+// Guard applies the API key and per-client rate limit policy shared by
+// every public transport. The zero policy (no keys, no rate) admits all.
+type Guard struct {
+	keys    [][sha256.Size]byte
+	limiter *ratelimit.Keyed
+}
+
+// NewGuard requires one of keys when any are given, and limits each client
+// to perSecond requests with bursts of burst when perSecond is positive.
+func NewGuard(keys []string, perSecond float64, burst int) *Guard {
+
+	g := &Guard{}
+
+	for _, key := range keys {
+		g.keys = append(g.keys, sha256.Sum256([]byte(key)))
+	}
+
+	if perSecond > 0 {
+		g.limiter = ratelimit.NewKeyed(perSecond, burst)
+	}
+
+	return g
+}
+
+// Check admits a request carrying apiKey from client, which identifies
+// the caller for rate limiting when no API key is in use.
+func (g *Guard) Check(transport, apiKey, client string) error {
+
+	if len(g.keys) > 0 && !g.validKey(apiKey) {
+		metrics.AccessDenied.WithLabelValues(transport, "unauthenticated").Inc()
+		return ErrUnauthenticated
+	}
+
+	if g.limiter == nil {
+		return nil
+	}
+
+	// authenticated callers are limited per key, so clients behind a
+	// shared proxy do not starve each other
+	id := "ip:" + client
+
+	if apiKey != "" && len(g.keys) > 0 {
+		id = "key:" + apiKey
+	}
+
+	if !g.limiter.Allow(id) {
+		metrics.AccessDenied.WithLabelValues(transport, "rate_limited").Inc()
+		return ErrRateLimited
+	}
+
+	return nil
+}
+
+// validKey compares digests in constant time so response timing does not
+// leak how much of a key matched.
+func (g *Guard) validKey(apiKey string) bool {
+
+	if apiKey == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(apiKey))
+
+	valid := false
+
+	for _, key := range g.keys {
+		if subtle.ConstantTimeCompare(sum[:], key[:]) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
@@ -0,0 +1,90 @@
+package access
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuard_OpenByDefault(t *testing.T) {
+
+	g := NewGuard(nil, 0, 0)
+
+	for i := 0; i < 100; i++ {
+		if err := g.Check("http", "", "10.0.0.1"); err != nil {
+			t.Fatalf("Expected open guard to admit, got %v", err)
+		}
+	}
+}
+
+func TestGuard_RequiresKey(t *testing.T) {
+
+	g := NewGuard([]string{"secret"}, 0, 0)
+
+	if err := g.Check("http", "", "10.0.0.1"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated without key, got %v", err)
+	}
+
+	if err := g.Check("http", "wrong", "10.0.0.1"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected ErrUnauthenticated with wrong key, got %v", err)
+	}
+
+	if err := g.Check("http", "secret", "10.0.0.1"); err != nil {
+		t.Errorf("Expected valid key to be admitted, got %v", err)
+	}
+}
+
+func TestGuard_RateLimitsPerClient(t *testing.T) {
+
+	g := NewGuard(nil, 1, 1)
+
+	if err := g.Check("http", "", "10.0.0.1"); err != nil {
+		t.Fatalf("Expected first request admitted, got %v", err)
+	}
+
+	if err := g.Check("http", "", "10.0.0.1"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+
+	if err := g.Check("http", "", "10.0.0.2"); err != nil {
+		t.Errorf("Expected other client admitted, got %v", err)
+	}
+}
+
+func TestMiddleware_StatusCodes(t *testing.T) {
+
+	g := NewGuard([]string{"secret"}, 1, 1)
+
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"missing key", "", "", http.StatusUnauthorized},
+		{"bearer token", "Authorization", "Bearer secret", http.StatusOK},
+		{"over limit", "X-API-Key", "secret", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/search", nil)
+
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package access
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Middleware rejects HTTP requests the guard does not admit with 401 or
+// 429. Health and metrics endpoints should not be wrapped.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		err := g.Check("http", apiKeyFromRequest(r), clientHost(r.RemoteAddr))
+
+		switch {
+
+		case errors.Is(err, ErrUnauthenticated):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+
+		case errors.Is(err, ErrRateLimited):
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiKeyFromRequest reads the key from X-API-Key or a bearer token.
+func apiKeyFromRequest(r *http.Request) string {
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	return BearerToken(r.Header.Get("Authorization"))
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(header string) string {
+
+	const prefix = "bearer "
+
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+
+	return ""
+}
+
+// clientHost strips the port from a remote address.
+func clientHost(addr string) string {
+
+	host, _, err := net.SplitHostPort(addr)
+
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
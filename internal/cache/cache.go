@@ -11,3 +11,9 @@ type Cache interface {
 
 	Set(key string, value []domain.POI, ttl time.Duration)
 }
+
+// TTLGetter is implemented by caches that can report how long an entry
+// has left, so copies made into another layer expire with the original.
+type TTLGetter interface {
+	GetWithTTL(key string) ([]domain.POI, time.Duration, bool)
+}
@@ -0,0 +1,315 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	cachev1 "github.com/hynek-systems/hynek-poi/api/cache/v1"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoded entries start with a four byte header: entryMagic, the format
+// version, the codec and the compression. JSON never starts with
+// entryMagic, so values without the header are read as legacy JSON.
+const (
+	entryMagic   = 0xff
+	entryVersion = 1
+	headerSize   = 4
+)
+
+// maxDecodedSize caps decompressed entries so a corrupt value cannot
+// exhaust memory.
+const maxDecodedSize = 64 << 20
+
+// Codec serializes a cached POI list.
+type Codec interface {
+	Name() string
+	Marshal(pois []domain.POI) ([]byte, error)
+	Unmarshal(data []byte) ([]domain.POI, error)
+}
+
+type codecID byte
+
+const (
+	codecJSON codecID = iota + 1
+	codecProtobuf
+)
+
+type compressionID byte
+
+const (
+	compressionNone compressionID = iota
+	compressionZstd
+	compressionSnappy
+)
+
+var codecs = map[codecID]Codec{
+	codecJSON:     JSONCodec{},
+	codecProtobuf: ProtobufCodec{},
+}
+
+var compressionNames = map[compressionID]string{
+	compressionNone:   "none",
+	compressionZstd:   "zstd",
+	compressionSnappy: "snappy",
+}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedSize))
+)
+
+// Serializer encodes entries with one codec and compression, and decodes
+// entries written with any of them, including headerless JSON, so the
+// format can change during a rolling deploy.
+type Serializer struct {
+	codecID     codecID
+	compression compressionID
+	threshold   int
+}
+
+// NewSerializer accepts codec "json" or "protobuf" and compression
+// "none", "zstd" or "snappy". Payloads smaller than threshold bytes are
+// stored uncompressed.
+func NewSerializer(codec string, compression string, threshold int) (*Serializer, error) {
+
+	s := &Serializer{threshold: threshold}
+
+	found := false
+
+	for id, c := range codecs {
+		if c.Name() == codec {
+			s.codecID, found = id, true
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown cache codec %q", codec)
+	}
+
+	found = false
+
+	for id, name := range compressionNames {
+		if name == compression {
+			s.compression, found = id, true
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown cache compression %q", compression)
+	}
+
+	return s, nil
+}
+
+// Encode returns the header followed by the serialized, possibly
+// compressed, POIs.
+func (s *Serializer) Encode(pois []domain.POI) ([]byte, error) {
+
+	codec := codecs[s.codecID]
+
+	payload, err := codec.Marshal(pois)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compression := compressionNone
+
+	if s.compression != compressionNone && len(payload) >= s.threshold {
+		compression = s.compression
+	}
+
+	out := make([]byte, headerSize, headerSize+len(payload))
+	out[0], out[1], out[2], out[3] = entryMagic, entryVersion, byte(s.codecID), byte(compression)
+
+	switch compression {
+
+	case compressionZstd:
+		out = zstdEncoder.EncodeAll(payload, out)
+
+	case compressionSnappy:
+		out = append(out, snappy.Encode(nil, payload)...)
+
+	default:
+		out = append(out, payload...)
+	}
+
+	metrics.CacheEntryBytes.WithLabelValues(codec.Name(), "serialized").Observe(float64(len(payload)))
+	metrics.CacheEntryBytes.WithLabelValues(codec.Name(), "stored").Observe(float64(len(out)))
+
+	return out, nil
+}
+
+// Decode reads entries in any supported format.
+func (s *Serializer) Decode(data []byte) ([]domain.POI, error) {
+
+	if len(data) == 0 || data[0] != entryMagic {
+		return JSONCodec{}.Unmarshal(data)
+	}
+
+	if len(data) < headerSize {
+		return nil, errors.New("cache entry: truncated header")
+	}
+
+	if data[1] != entryVersion {
+		return nil, fmt.Errorf("cache entry: unsupported version %d", data[1])
+	}
+
+	codec, ok := codecs[codecID(data[2])]
+
+	if !ok {
+		return nil, fmt.Errorf("cache entry: unknown codec %d", data[2])
+	}
+
+	payload := data[headerSize:]
+
+	var err error
+
+	switch compressionID(data[3]) {
+
+	case compressionNone:
+
+	case compressionZstd:
+		payload, err = zstdDecoder.DecodeAll(payload, nil)
+
+	case compressionSnappy:
+
+		var n int
+
+		if n, err = snappy.DecodedLen(payload); err == nil && n > maxDecodedSize {
+			err = errors.New("decoded size too large")
+		}
+
+		if err == nil {
+			payload, err = snappy.Decode(nil, payload)
+		}
+
+	default:
+		return nil, fmt.Errorf("cache entry: unknown compression %d", data[3])
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cache entry: %w", err)
+	}
+
+	return codec.Unmarshal(payload)
+}
+
+// JSONCodec is the original cache format.
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string {
+	return "json"
+}
+
+func (JSONCodec) Marshal(pois []domain.POI) ([]byte, error) {
+	return json.Marshal(pois)
+}
+
+func (JSONCodec) Unmarshal(data []byte) ([]domain.POI, error) {
+
+	var pois []domain.POI
+
+	err := json.Unmarshal(data, &pois)
+
+	return pois, err
+}
+
+// ProtobufCodec uses the cachev1.Entry schema.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (ProtobufCodec) Marshal(pois []domain.POI) ([]byte, error) {
+
+	entry := &cachev1.Entry{Pois: make([]*cachev1.POI, 0, len(pois))}
+
+	for _, p := range pois {
+		entry.Pois = append(entry.Pois, &cachev1.POI{
+			Id:        p.ID,
+			Name:      p.Name,
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+			Category:  p.Category,
+			Source:    p.Source,
+
+			Rating:       p.Rating,
+			RatingCount:  int32(p.RatingCount),
+			Website:      p.Website,
+			Phone:        p.Phone,
+			OpeningHours: p.OpeningHours,
+			Cuisine:      p.Cuisine,
+			PriceLevel:   int32(p.PriceLevel),
+			MenuUrl:      p.MenuURL,
+
+			Address:              p.Address,
+			Description:          p.Description,
+			Email:                p.Email,
+			OpenNow:              p.OpenNow,
+			WheelchairAccessible: p.WheelchairAccessible,
+			OutdoorSeating:       p.OutdoorSeating,
+			Takeaway:             p.Takeaway,
+			Delivery:             p.Delivery,
+			Verified:             p.Verified,
+			Popularity:           p.Popularity,
+
+			CanonicalId: p.CanonicalID,
+		})
+	}
+
+	return proto.Marshal(entry)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte) ([]domain.POI, error) {
+
+	var entry cachev1.Entry
+
+	if err := proto.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	pois := make([]domain.POI, 0, len(entry.Pois))
+
+	for _, p := range entry.Pois {
+		pois = append(pois, domain.POI{
+			ID:        p.Id,
+			Name:      p.Name,
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+			Category:  p.Category,
+			Source:    p.Source,
+
+			Rating:       p.Rating,
+			RatingCount:  int(p.RatingCount),
+			Website:      p.Website,
+			Phone:        p.Phone,
+			OpeningHours: p.OpeningHours,
+			Cuisine:      p.Cuisine,
+			PriceLevel:   int(p.PriceLevel),
+			MenuURL:      p.MenuUrl,
+
+			Address:              p.Address,
+			Description:          p.Description,
+			Email:                p.Email,
+			OpenNow:              p.OpenNow,
+			WheelchairAccessible: p.WheelchairAccessible,
+			OutdoorSeating:       p.OutdoorSeating,
+			Takeaway:             p.Takeaway,
+			Delivery:             p.Delivery,
+			Verified:             p.Verified,
+			Popularity:           p.Popularity,
+
+			CanonicalID: p.CanonicalId,
+		})
+	}
+
+	return pois, nil
+}
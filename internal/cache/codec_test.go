@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func codecTestPOIs() []domain.POI {
+	open := true
+
+	pois := make([]domain.POI, 0, 50)
+
+	for i := 0; i < 50; i++ {
+		pois = append(pois, domain.POI{
+			ID:           "osm:" + strings.Repeat("1", i+1),
+			Name:         "Cafe",
+			Latitude:     59.33,
+			Longitude:    18.06,
+			Category:     "cafe",
+			Source:       "osm",
+			RatingCount:  12,
+			OpeningHours: []string{"Mo-Fr 08:00-18:00", "Sa 10:00-16:00"},
+			OpenNow:      &open,
+			CanonicalID:  "hp_" + strings.Repeat("a", 20),
+		})
+	}
+
+	return pois
+}
+
+func TestSerializer_RoundTrip(t *testing.T) {
+	pois := codecTestPOIs()
+
+	for _, codec := range []string{"json", "protobuf"} {
+		for _, compression := range []string{"none", "zstd", "snappy"} {
+			t.Run(codec+"/"+compression, func(t *testing.T) {
+				s, err := NewSerializer(codec, compression, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				data, err := s.Encode(pois)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := s.Decode(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !reflect.DeepEqual(got, pois) {
+					t.Errorf("Expected round trip to preserve POIs, got %+v", got[0])
+				}
+			})
+		}
+	}
+}
+
+func TestSerializer_ReadsLegacyJSON(t *testing.T) {
+	pois := codecTestPOIs()
+
+	legacy, _ := json.Marshal(pois)
+
+	s, _ := NewSerializer("protobuf", "zstd", 0)
+
+	got, err := s.Decode(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, pois) {
+		t.Error("Expected legacy JSON entry to decode")
+	}
+}
+
+func TestSerializer_ReadsOtherFormats(t *testing.T) {
+	pois := codecTestPOIs()
+
+	writer, _ := NewSerializer("json", "snappy", 0)
+	reader, _ := NewSerializer("protobuf", "zstd", 0)
+
+	data, _ := writer.Encode(pois)
+
+	if _, err := reader.Decode(data); err != nil {
+		t.Errorf("Expected entry from another codec to decode, got %v", err)
+	}
+}
+
+func TestSerializer_CompressesAboveThreshold(t *testing.T) {
+	s, _ := NewSerializer("protobuf", "zstd", 1<<20)
+
+	data, _ := s.Encode(codecTestPOIs())
+
+	if data[3] != byte(compressionNone) {
+		t.Errorf("Expected entry below threshold to stay uncompressed, got compression %d", data[3])
+	}
+
+	s, _ = NewSerializer("protobuf", "zstd", 0)
+
+	compressed, _ := s.Encode(codecTestPOIs())
+
+	if len(compressed) >= len(data) {
+		t.Errorf("Expected compressed entry smaller than %d bytes, got %d", len(data), len(compressed))
+	}
+}
+
+func TestSerializer_RejectsUnknownVersion(t *testing.T) {
+	s, _ := NewSerializer("protobuf", "none", 0)
+
+	if _, err := s.Decode([]byte{entryMagic, 9, byte(codecProtobuf), 0}); err == nil {
+		t.Error("Expected error for unknown version, got nil")
+	}
+}
+
+func TestNewSerializer_RejectsUnknownCodec(t *testing.T) {
+	if _, err := NewSerializer("msgpack", "none", 0); err == nil {
+		t.Error("Expected error for unknown codec, got nil")
+	}
+}
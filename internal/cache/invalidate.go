@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/mmcloughlin/geohash"
+)
+
+// keyspace is the prefix of every cache key. Other data sharing the Redis
+// instance lives outside it and must never be invalidated.
+const keyspace = "poi:"
+
+// tileKeyPrefix starts every key built by TileKey.
+const tileKeyPrefix = keyspace + "tile:"
+
+// Selector picks the cache entries to invalidate. Exactly one field must
+// be set. Geohash and BBox match tiles overlapping the area; Category and
+// Source match entries holding a POI of that category or provider, and
+// Category also matches tiles filtered on it.
+type Selector struct {
+	Key      string       `json:"key,omitempty"`
+	Prefix   string       `json:"prefix,omitempty"`
+	Geohash  string       `json:"geohash,omitempty"`
+	BBox     *domain.BBox `json:"bbox,omitempty"`
+	Category string       `json:"category,omitempty"`
+	Source   string       `json:"source,omitempty"`
+}
+
+// Invalidator is implemented by caches that can drop entries on demand.
+// Invalidate returns the number of entries removed.
+type Invalidator interface {
+	Invalidate(sel Selector) (int, error)
+}
+
+func (s Selector) Validate() error {
+
+	set := 0
+
+	for _, v := range []bool{
+		s.Key != "",
+		s.Prefix != "",
+		s.Geohash != "",
+		s.BBox != nil,
+		s.Category != "",
+		s.Source != "",
+	} {
+		if v {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return errors.New("exactly one of key, prefix, geohash, bbox, category or source is required")
+	}
+
+	if s.Key != "" && !strings.HasPrefix(s.Key, keyspace) {
+		return errors.New("key must start with " + keyspace)
+	}
+
+	if s.Prefix != "" && !strings.HasPrefix(s.Prefix, keyspace) {
+		return errors.New("prefix must start with " + keyspace)
+	}
+
+	if s.Geohash != "" {
+		if err := geohash.Validate(s.Geohash); err != nil {
+			return err
+		}
+	}
+
+	if b := s.BBox; b != nil && (b.MinLat > b.MaxLat || b.MinLng > b.MaxLng) {
+		return errors.New("bbox min must not exceed max")
+	}
+
+	return nil
+}
+
+// needsValues reports whether matching depends on the cached POIs and
+// not only on the key.
+func (s Selector) needsValues() bool {
+
+	return s.Source != "" || s.Category != ""
+}
+
+// Match reports whether the entry stored under key with value is selected.
+func (s Selector) Match(key string, value []domain.POI) bool {
+
+	switch {
+
+	case s.Key != "":
+		return key == s.Key
+
+	case s.Prefix != "":
+		return strings.HasPrefix(key, s.Prefix)
+
+	case s.Geohash != "", s.BBox != nil:
+
+		hash, _, ok := parseTileKey(key)
+
+		return ok && s.overlapsTile(hash)
+
+	case s.Category != "":
+
+		if _, categories, ok := parseTileKey(key); ok {
+			for _, c := range strings.Split(categories, ",") {
+				if c == strings.ToLower(s.Category) {
+					return true
+				}
+			}
+		}
+
+		return s.matchAny(value)
+
+	default:
+		return s.matchAny(value)
+	}
+}
+
+// MatchPOI reports whether a single POI is selected. Key and prefix
+// selectors never match individual POIs.
+func (s Selector) MatchPOI(poi domain.POI) bool {
+
+	switch {
+
+	case s.Geohash != "":
+		return strings.HasPrefix(geohash.EncodeWithPrecision(poi.Latitude, poi.Longitude, uint(len(s.Geohash))), s.Geohash)
+
+	case s.BBox != nil:
+		return Contains(domain.SearchQuery{BBox: s.BBox}, poi)
+
+	case s.Category != "":
+		return strings.EqualFold(poi.Category, s.Category)
+
+	case s.Source != "":
+		return poi.Source == s.Source
+	}
+
+	return false
+}
+
+func (s Selector) matchAny(value []domain.POI) bool {
+
+	for _, poi := range value {
+		if s.MatchPOI(poi) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlapsTile reports whether the geohash cell hash intersects the
+// selected area.
+func (s Selector) overlapsTile(hash string) bool {
+
+	if s.Geohash != "" {
+		return strings.HasPrefix(hash, s.Geohash) || strings.HasPrefix(s.Geohash, hash)
+	}
+
+	box := geohash.BoundingBox(hash)
+
+	return box.MinLat <= s.BBox.MaxLat && box.MaxLat >= s.BBox.MinLat &&
+		box.MinLng <= s.BBox.MaxLng && box.MaxLng >= s.BBox.MinLng
+}
+
+// parseTileKey splits a key built by TileKey, ignoring a :degraded suffix.
+func parseTileKey(key string) (hash string, categories string, ok bool) {
+
+	rest, found := strings.CutPrefix(key, tileKeyPrefix)
+
+	if !found {
+		return "", "", false
+	}
+
+	rest = strings.TrimSuffix(rest, ":degraded")
+	rest = strings.TrimSuffix(rest, ":failed")
+
+	hash, categories, ok = strings.Cut(rest, ":")
+
+	return hash, categories, ok
+}
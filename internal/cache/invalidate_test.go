@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func TestSelector_Validate(t *testing.T) {
+
+	if err := (Selector{}).Validate(); err == nil {
+		t.Error("Expected error for empty selector")
+	}
+
+	if err := (Selector{Key: "a", Prefix: "b"}).Validate(); err == nil {
+		t.Error("Expected error for two criteria")
+	}
+
+	if err := (Selector{Geohash: "u6sc!"}).Validate(); err == nil {
+		t.Error("Expected error for invalid geohash")
+	}
+
+	// other data shares the Redis instance
+	if err := (Selector{Prefix: "hynek-poi:"}).Validate(); err == nil {
+		t.Error("Expected error for a prefix outside the cache")
+	}
+
+	if err := (Selector{Key: "hynek-poi:cost:google"}).Validate(); err == nil {
+		t.Error("Expected error for a key outside the cache")
+	}
+
+	if err := (Selector{Source: "osm"}).Validate(); err != nil {
+		t.Errorf("Expected valid selector, got %v", err)
+	}
+}
+
+func TestSelector_Match(t *testing.T) {
+
+	stockholm := TileKey("u6sce", []string{"cafe"})
+	all := TileKey("u6scd", nil)
+
+	cafe := []domain.POI{{ID: "1", Source: "osm", Category: "cafe", Latitude: 59.33, Longitude: 18.07}}
+
+	tests := []struct {
+		name  string
+		sel   Selector
+		key   string
+		value []domain.POI
+		want  bool
+	}{
+		{"exact key", Selector{Key: stockholm}, stockholm, nil, true},
+		{"exact key other", Selector{Key: stockholm}, all, nil, false},
+		{"prefix", Selector{Prefix: "poi:tile:u6s"}, all, nil, true},
+		{"geohash contains tile", Selector{Geohash: "u6s"}, stockholm, nil, true},
+		{"geohash inside tile", Selector{Geohash: "u6sce1"}, stockholm, nil, true},
+		{"geohash elsewhere", Selector{Geohash: "u4"}, stockholm, nil, false},
+		{"geohash degraded key", Selector{Geohash: "u6sce"}, stockholm + ":degraded", nil, true},
+		{"bbox overlaps", Selector{BBox: &domain.BBox{MinLat: 59, MinLng: 17, MaxLat: 60, MaxLng: 19}}, stockholm, nil, true},
+		{"bbox elsewhere", Selector{BBox: &domain.BBox{MinLat: 1, MinLng: 1, MaxLat: 2, MaxLng: 2}}, stockholm, nil, false},
+		{"category key", Selector{Category: "Cafe"}, stockholm, nil, true},
+		{"category content", Selector{Category: "cafe"}, all, cafe, true},
+		{"category absent", Selector{Category: "bar"}, all, cafe, false},
+		{"source content", Selector{Source: "osm"}, all, cafe, true},
+		{"source absent", Selector{Source: "google"}, all, cafe, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sel.Match(tt.key, tt.value); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSelector_ScanPattern(t *testing.T) {
+
+	tests := []struct {
+		name string
+		sel  Selector
+		want string
+	}{
+		{"prefix", Selector{Prefix: "poi:tile:u6s"}, "poi:tile:u6s*"},
+		{"short geohash", Selector{Geohash: "u6"}, "poi:tile:u6*"},
+		{"long geohash", Selector{Geohash: "u6sce1"}, "poi:tile:u6s*"},
+		{"bbox", Selector{BBox: &domain.BBox{MinLat: 59, MinLng: 17, MaxLat: 60, MaxLng: 19}}, "poi:tile:*"},
+		{"source", Selector{Source: "osm"}, "poi:*"},
+		{"category", Selector{Category: "cafe"}, "poi:*"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sel.scanPattern(); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	// every tile the geohash selector matches falls under its pattern
+	sel := Selector{Geohash: "u6sce1"}
+
+	for _, hash := range []string{"u6s", "u6sc", "u6sce", "u6sce1x"} {
+		if key := TileKey(hash, nil); !sel.Match(key, nil) || !strings.HasPrefix(key, "poi:tile:u6s") {
+			t.Errorf("Expected %s to match and fall under the scan pattern", key)
+		}
+	}
+}
+
+func TestLayeredCache_InvalidateBothLayers(t *testing.T) {
+
+	l1 := NewMemoryCache()
+	l2 := NewMemoryCache()
+	c := NewLayeredCache(l1, l2)
+
+	osm := []domain.POI{{ID: "1", Source: "osm"}}
+	google := []domain.POI{{ID: "2", Source: "google"}}
+
+	c.Set("poi:tile:u6sce:all", osm, time.Hour)
+	c.Set("poi:tile:u6scd:all", google, time.Hour)
+
+	removed, err := c.Invalidate(Selector{Source: "osm"})
+
+	if err != nil || removed != 1 {
+		t.Fatalf("Expected 1 removed, got %d (%v)", removed, err)
+	}
+
+	for _, layer := range []Cache{l1, l2} {
+
+		if _, found := layer.Get("poi:tile:u6sce:all"); found {
+			t.Error("Expected osm entry removed from every layer")
+		}
+
+		if _, found := layer.Get("poi:tile:u6scd:all"); !found {
+			t.Error("Expected google entry kept")
+		}
+	}
+}
+
+func TestPOIIndex_Invalidate(t *testing.T) {
+
+	index := NewPOIIndex(time.Hour, 10)
+
+	index.Add([]domain.POI{{ID: "1", Source: "osm"}, {ID: "2", Source: "google"}})
+
+	if removed, _ := index.Invalidate(Selector{Source: "osm"}); removed != 1 {
+		t.Errorf("Expected 1 removed, got %d", removed)
+	}
+
+	if _, found := index.Get("osm", "1"); found {
+		t.Error("Expected osm POI removed")
+	}
+
+	if _, found := index.Get("google", "2"); !found {
+		t.Error("Expected google POI kept")
+	}
+}
+
+func TestInvalidationBus_AppliesRemoteMessages(t *testing.T) {
+
+	l1 := NewMemoryCache()
+
+	bus := &InvalidationBus{origin: "self", local: []Invalidator{l1}}
+
+	l1.Set("poi:tile:u6sce:all", nil, time.Hour)
+
+	bus.handle(`{"origin":"self","selector":{"key":"poi:tile:u6sce:all"}}`)
+
+	if _, found := l1.Get("poi:tile:u6sce:all"); !found {
+		t.Fatal("Expected own broadcast to be ignored")
+	}
+
+	bus.handle(`{"origin":"other","selector":{"key":"poi:tile:u6sce:all"}}`)
+
+	if _, found := l1.Get("poi:tile:u6sce:all"); found {
+		t.Error("Expected remote broadcast to invalidate L1")
+	}
+}
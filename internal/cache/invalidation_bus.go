@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// InvalidationChannel is the Redis pub/sub channel invalidations are
+// broadcast on.
+const InvalidationChannel = "hynek-poi:invalidate"
+
+type invalidationMessage struct {
+	Origin   string   `json:"origin"`
+	Selector Selector `json:"selector"`
+}
+
+// InvalidationBus broadcasts invalidations to every replica so their
+// instance-local caches drop the same entries. The shared Redis layer is
+// invalidated once by the instance handling the request.
+type InvalidationBus struct {
+	client redis.UniversalClient
+	origin string
+	local  []Invalidator
+}
+
+// NewInvalidationBus applies broadcasts from other replicas to local,
+// typically the L1 cache and the POI index.
+func NewInvalidationBus(client redis.UniversalClient, local ...Invalidator) *InvalidationBus {
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return &InvalidationBus{
+		client: client,
+		origin: hex.EncodeToString(id),
+		local:  local,
+	}
+}
+
+// Publish tells the other replicas to apply sel.
+func (b *InvalidationBus) Publish(ctx context.Context, sel Selector) error {
+
+	payload, err := json.Marshal(invalidationMessage{Origin: b.origin, Selector: sel})
+
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(ctx, InvalidationChannel, payload).Err()
+}
+
+// Run applies broadcasts until ctx is done. go-redis resubscribes after
+// connection loss, but messages published meanwhile are lost; entries
+// they covered expire with their TTL.
+func (b *InvalidationBus) Run(ctx context.Context) {
+
+	sub := b.client.Subscribe(ctx, InvalidationChannel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+
+	for {
+
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-messages:
+
+			if !ok {
+				return
+			}
+
+			b.handle(msg.Payload)
+		}
+	}
+}
+
+func (b *InvalidationBus) handle(payload string) {
+
+	var msg invalidationMessage
+
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		log.Printf("cache invalidation: bad message: %v", err)
+		return
+	}
+
+	// the publisher already invalidated its own layers
+	if msg.Origin == b.origin {
+		return
+	}
+
+	if err := msg.Selector.Validate(); err != nil {
+		log.Printf("cache invalidation: bad selector: %v", err)
+		return
+	}
+
+	for _, local := range b.local {
+		if _, err := local.Invalidate(msg.Selector); err != nil {
+			log.Printf("cache invalidation: %v", err)
+		}
+	}
+
+	metrics.CacheInvalidations.WithLabelValues("broadcast").Inc()
+}
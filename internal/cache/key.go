@@ -11,6 +11,9 @@ import (
 
 const precision = 6
 
+// BuildKey identifies the area and categories of a query, with the center
+// rounded to a geohash cell. Results are not cached under it but per tile
+// (see CoverQuery and TileKey).
 func BuildKey(query domain.SearchQuery) string {
 
 	categoryPart := normalizeCategories(query.Categories)
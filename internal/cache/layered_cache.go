@@ -26,15 +26,37 @@ func (c *LayeredCache) Get(key string) ([]domain.POI, bool) {
 	}
 
 	// Try L2 (redis)
-	if value, found := c.l2.Get(key); found {
+	value, ttl, found := c.getL2(key)
 
-		// populate L1
-		c.l1.Set(key, value, 5*time.Minute)
+	if !found {
+		return nil, false
+	}
 
-		return value, true
+	// populate L1, never outliving the L2 entry
+	c.l1.Set(key, value, min(ttl, maxPromotionTTL))
+
+	return value, true
+}
+
+// maxPromotionTTL caps how long an L2 hit is kept in L1.
+const maxPromotionTTL = 5 * time.Minute
+
+func (c *LayeredCache) getL2(key string) ([]domain.POI, time.Duration, bool) {
+
+	if l2, ok := c.l2.(TTLGetter); ok {
+		return l2.GetWithTTL(key)
 	}
 
-	return nil, false
+	value, found := c.l2.Get(key)
+
+	return value, maxPromotionTTL, found
+}
+
+// GetWithTTL implements TTLGetter, reporting the entry's remaining TTL in
+// the shared L2. It does not copy the entry into L1.
+func (c *LayeredCache) GetWithTTL(key string) ([]domain.POI, time.Duration, bool) {
+
+	return c.getL2(key)
 }
 
 func (c *LayeredCache) Set(key string, value []domain.POI, ttl time.Duration) {
@@ -44,4 +66,25 @@ func (c *LayeredCache) Set(key string, value []domain.POI, ttl time.Duration) {
 	c.l2.Set(key, value, ttl)
 }
 
+// Invalidate drops matching entries from every layer that supports it,
+// returning the count from the shared L2. Other replicas' L1 caches are
+// not reached; see InvalidationBus.
+func (c *LayeredCache) Invalidate(sel Selector) (int, error) {
+
+	if l1, ok := c.l1.(Invalidator); ok {
+		if _, err := l1.Invalidate(sel); err != nil {
+			return 0, err
+		}
+	}
+
+	l2, ok := c.l2.(Invalidator)
+
+	if !ok {
+		return 0, nil
+	}
+
+	return l2.Invalidate(sel)
+}
+
 var _ Cache = (*LayeredCache)(nil)
+var _ Invalidator = (*LayeredCache)(nil)
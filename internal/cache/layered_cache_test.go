@@ -103,3 +103,21 @@ func TestLayeredCache_SetBothLayers(t *testing.T) {
 		t.Error("Incorrect data in L2")
 	}
 }
+
+func TestLayeredCache_L2PromotionKeepsRemainingTTL(t *testing.T) {
+	l1 := NewMemoryCache()
+	l2 := NewMemoryCache()
+	cache := NewLayeredCache(l1, l2)
+
+	l2.Set("short", []domain.POI{{ID: "1"}}, 2*time.Second)
+
+	if _, found := cache.Get("short"); !found {
+		t.Fatal("Expected cache hit from L2")
+	}
+
+	_, ttl, found := l1.GetWithTTL("short")
+
+	if !found || ttl > 2*time.Second {
+		t.Errorf("Expected L1 copy to expire with the L2 entry, got ttl %s", ttl)
+	}
+}
@@ -40,6 +40,25 @@ func (c *MemoryCache) Get(key string) ([]domain.POI, bool) {
 	return item.value, true
 }
 
+func (c *MemoryCache) GetWithTTL(key string) ([]domain.POI, time.Duration, bool) {
+
+	c.mu.RLock()
+	item, found := c.items[key]
+	c.mu.RUnlock()
+
+	if !found {
+		return nil, 0, false
+	}
+
+	ttl := time.Until(item.expiration)
+
+	if ttl <= 0 {
+		return nil, 0, false
+	}
+
+	return item.value, ttl, true
+}
+
 func (c *MemoryCache) Set(key string, value []domain.POI, ttl time.Duration) {
 
 	c.mu.Lock()
@@ -51,3 +70,20 @@ func (c *MemoryCache) Set(key string, value []domain.POI, ttl time.Duration) {
 
 	c.mu.Unlock()
 }
+
+func (c *MemoryCache) Invalidate(sel Selector) (int, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+
+	for key, item := range c.items {
+		if sel.Match(key, item.value) {
+			delete(c.items, key)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
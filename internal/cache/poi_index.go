@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// POIIndex remembers recently returned POIs by source and provider ID so
+// they can be looked up individually. It is best effort and local to the
+// instance: a POI is only found if this instance served it within ttl.
+type POIIndex struct {
+	mu         sync.RWMutex
+	items      map[string]indexedPOI
+	ttl        time.Duration
+	maxEntries int
+}
+
+type indexedPOI struct {
+	poi        domain.POI
+	expiration time.Time
+}
+
+func NewPOIIndex(ttl time.Duration, maxEntries int) *POIIndex {
+	return &POIIndex{
+		items:      make(map[string]indexedPOI),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+func (i *POIIndex) Add(pois []domain.POI) {
+
+	expiration := time.Now().Add(i.ttl)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, poi := range pois {
+		i.items[indexKey(poi.Source, poi.ID)] = indexedPOI{
+			poi:        poi,
+			expiration: expiration,
+		}
+	}
+
+	if len(i.items) > i.maxEntries {
+		i.evict()
+	}
+}
+
+func (i *POIIndex) Get(source string, id string) (domain.POI, bool) {
+
+	i.mu.RLock()
+	item, found := i.items[indexKey(source, id)]
+	i.mu.RUnlock()
+
+	if !found || time.Now().After(item.expiration) {
+		return domain.POI{}, false
+	}
+
+	return item.poi, true
+}
+
+// Invalidate drops indexed POIs matching sel.
+func (i *POIIndex) Invalidate(sel Selector) (int, error) {
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	removed := 0
+
+	for key, item := range i.items {
+		if sel.MatchPOI(item.poi) {
+			delete(i.items, key)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// evict drops expired entries, then arbitrary ones until under capacity.
+// Must be called with mu held.
+func (i *POIIndex) evict() {
+
+	now := time.Now()
+
+	for key, item := range i.items {
+		if now.After(item.expiration) {
+			delete(i.items, key)
+		}
+	}
+
+	for key := range i.items {
+
+		if len(i.items) <= i.maxEntries {
+			return
+		}
+
+		delete(i.items, key)
+	}
+}
+
+func indexKey(source string, id string) string {
+	return source + ":" + id
+}
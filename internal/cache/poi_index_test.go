@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func TestPOIIndex_AddGet(t *testing.T) {
+	index := NewPOIIndex(time.Hour, 100)
+
+	index.Add([]domain.POI{
+		{ID: "1", Source: "osm", Name: "OSM One"},
+		{ID: "1", Source: "google", Name: "Google One"},
+	})
+
+	poi, found := index.Get("google", "1")
+	if !found {
+		t.Fatal("Expected POI to be found")
+	}
+
+	if poi.Name != "Google One" {
+		t.Errorf("Expected Google One, got %s", poi.Name)
+	}
+
+	if _, found := index.Get("foursquare", "1"); found {
+		t.Error("Expected miss for unknown source")
+	}
+}
+
+func TestPOIIndex_Capacity(t *testing.T) {
+	index := NewPOIIndex(time.Hour, 2)
+
+	index.Add([]domain.POI{
+		{ID: "1", Source: "osm"},
+		{ID: "2", Source: "osm"},
+		{ID: "3", Source: "osm"},
+	})
+
+	if len(index.items) != 2 {
+		t.Errorf("Expected index capped at 2 entries, got %d", len(index.items))
+	}
+}
+
+func TestPOIIndex_Expiration(t *testing.T) {
+	index := NewPOIIndex(50*time.Millisecond, 100)
+
+	index.Add([]domain.POI{{ID: "1", Source: "osm"}})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, found := index.Get("osm", "1"); found {
+		t.Error("Expected expired POI to be missing")
+	}
+}
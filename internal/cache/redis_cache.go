@@ -2,64 +2,421 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
+// RedisCache is the shared L2 layer. Every call is bounded by an
+// operation timeout, and a circuit breaker turns reads into misses and
+// drops writes while Redis keeps failing, so an outage degrades the hit
+// rate instead of stalling searches.
 type RedisCache struct {
-	client *redis.Client
-	ctx    context.Context
+	client     redis.UniversalClient
+	serializer *Serializer
+	opTimeout  time.Duration
+	breaker    *circuitbreaker.CircuitBreaker
 }
 
-func (c *RedisCache) Client() *redis.Client {
+func (c *RedisCache) Client() redis.UniversalClient {
 	return c.client
 }
 
-func NewRedisCache(addr string, password string, db int) *RedisCache {
+func NewRedisCache(client redis.UniversalClient, serializer *Serializer, cfg config.RedisConfig) *RedisCache {
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: password,
-		DB:       db,
+	metrics.RedisCircuitBreakerState.Set(float64(circuitbreaker.StateClosed))
+
+	breaker := circuitbreaker.New("redis", circuitbreaker.Config{
+		Window:           cfg.CircuitBreaker.Window,
+		FailureRate:      cfg.CircuitBreaker.FailureRate,
+		MinRequests:      cfg.CircuitBreaker.MinRequests,
+		OpenTimeout:      cfg.CircuitBreaker.ResetTimeout,
+		HalfOpenProbes:   cfg.CircuitBreaker.HalfOpenProbes,
+		SlowCallDuration: cfg.CircuitBreaker.SlowCallDuration,
+		SlowCallRate:     cfg.CircuitBreaker.SlowCallRate,
+		OnStateChange: func(name string, from, to circuitbreaker.State) {
+			log.Printf("circuit breaker %s: %s -> %s", name, from, to)
+			metrics.RedisCircuitBreakerState.Set(float64(to))
+		},
 	})
 
 	return &RedisCache{
-		client: rdb,
-		ctx:    context.Background(),
+		client:     client,
+		serializer: serializer,
+		opTimeout:  cfg.OpTimeout,
+		breaker:    breaker,
 	}
 }
 
 func (c *RedisCache) Get(key string) ([]domain.POI, bool) {
 
-	val, err := c.client.Get(c.ctx, key).Result()
+	pois, _, found := c.GetWithTTL(key)
+
+	return pois, found
+}
+
+// GetWithTTL reads an entry and its remaining lifetime in one round trip.
+func (c *RedisCache) GetWithTTL(key string) ([]domain.POI, time.Duration, bool) {
+
+	done, err := c.breaker.Allow()
 
 	if err != nil {
-		return nil, false
+		metrics.RedisOperations.WithLabelValues("get", "bypassed").Inc()
+		return nil, 0, false
 	}
 
-	var pois []domain.POI
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
 
-	err = json.Unmarshal([]byte(val), &pois)
+	start := time.Now()
+
+	pipe := c.client.Pipeline()
+
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+
+	_, err = pipe.Exec(ctx)
+
+	if errors.Is(err, redis.Nil) {
+		done(circuitbreaker.Success, time.Since(start))
+		metrics.RedisOperations.WithLabelValues("get", "miss").Inc()
+		return nil, 0, false
+	}
 
 	if err != nil {
-		return nil, false
+		done(circuitbreaker.Failure, time.Since(start))
+		metrics.RedisOperations.WithLabelValues("get", "error").Inc()
+		return nil, 0, false
 	}
 
-	return pois, true
+	done(circuitbreaker.Success, time.Since(start))
+	metrics.RedisOperations.WithLabelValues("get", "hit").Inc()
+
+	pois, err := c.serializer.Decode([]byte(get.Val()))
+
+	if err != nil {
+		metrics.RedisOperations.WithLabelValues("get", "undecodable").Inc()
+		return nil, 0, false
+	}
+
+	// PTTL is negative for keys without expiry
+	remaining := ttl.Val()
+
+	if remaining < 0 {
+		remaining = maxPromotionTTL
+	}
+
+	return pois, remaining, true
 }
 
 func (c *RedisCache) Set(key string, value []domain.POI, ttl time.Duration) {
 
-	data, err := json.Marshal(value)
+	data, err := c.serializer.Encode(value)
 
 	if err != nil {
 		return
 	}
 
-	c.client.Set(c.ctx, key, data, ttl)
+	done, err := c.breaker.Allow()
+
+	if err != nil {
+		metrics.RedisOperations.WithLabelValues("set", "bypassed").Inc()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		done(circuitbreaker.Failure, time.Since(start))
+		metrics.RedisOperations.WithLabelValues("set", "error").Inc()
+		return
+	}
+
+	done(circuitbreaker.Success, time.Since(start))
+	metrics.RedisOperations.WithLabelValues("set", "ok").Inc()
+}
+
+// BreakerState reports whether reads and writes currently reach Redis.
+func (c *RedisCache) BreakerState() circuitbreaker.State {
+	return c.breaker.State()
+}
+
+// scanBatch is the SCAN page size used when invalidating.
+const scanBatch = 500
+
+// Invalidate scans for matching keys and deletes them. Source and
+// category selectors read each candidate value, so they cost more than
+// key, prefix and area selectors. In cluster mode every master is
+// scanned. Invalidation is an explicit admin action, so it skips the
+// circuit breaker and the per-operation timeout.
+func (c *RedisCache) Invalidate(sel Selector) (int, error) {
+
+	ctx := context.Background()
+
+	if sel.Key != "" {
+
+		n, err := c.client.Del(ctx, sel.Key).Result()
+
+		return int(n), err
+	}
+
+	pattern := sel.scanPattern()
+
+	cluster, ok := c.client.(*redis.ClusterClient)
+
+	if !ok {
+		return c.invalidateNode(ctx, c.client, pattern, sel)
+	}
+
+	var removed atomic.Int64
+
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+
+		n, err := c.invalidateNode(ctx, node, pattern, sel)
+
+		removed.Add(int64(n))
+
+		return err
+	})
+
+	return int(removed.Load()), err
+}
+
+// invalidateNode scans one node. Reads and deletes go through the main
+// client one key per command, which keeps them valid across cluster
+// slots.
+func (c *RedisCache) invalidateNode(ctx context.Context, node redis.Cmdable, pattern string, sel Selector) (int, error) {
+
+	removed := 0
+
+	var cursor uint64
+
+	for {
+
+		keys, next, err := node.Scan(ctx, cursor, pattern, scanBatch).Result()
+
+		if err != nil {
+			return removed, err
+		}
+
+		matched, err := c.matchKeys(ctx, sel, keys)
+
+		if err != nil {
+			return removed, err
+		}
+
+		n, err := c.deleteKeys(ctx, matched)
+
+		removed += n
+
+		if err != nil {
+			return removed, err
+		}
+
+		if next == 0 {
+			return removed, nil
+		}
+
+		cursor = next
+	}
+}
+
+func (c *RedisCache) matchKeys(ctx context.Context, sel Selector, keys []string) ([]string, error) {
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values := make([]string, len(keys))
+
+	if sel.needsValues() {
+
+		pipe := c.client.Pipeline()
+
+		cmds := make([]*redis.StringCmd, len(keys))
+
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+
+		_, _ = pipe.Exec(ctx)
+
+		for i, cmd := range cmds {
+
+			if err := cmd.Err(); err != nil && !errors.Is(err, redis.Nil) {
+				return nil, err
+			}
+
+			values[i] = cmd.Val()
+		}
+	}
+
+	var matched []string
+
+	for i, key := range keys {
+
+		var pois []domain.POI
+
+		if values[i] != "" {
+			pois, _ = c.serializer.Decode([]byte(values[i]))
+		}
+
+		if sel.Match(key, pois) {
+			matched = append(matched, key)
+		}
+	}
+
+	return matched, nil
+}
+
+func (c *RedisCache) deleteKeys(ctx context.Context, keys []string) (int, error) {
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	pipe := c.client.Pipeline()
+
+	cmds := make([]*redis.IntCmd, len(keys))
+
+	for i, key := range keys {
+		cmds[i] = pipe.Del(ctx, key)
+	}
+
+	_, err := pipe.Exec(ctx)
+
+	removed := 0
+
+	for _, cmd := range cmds {
+		removed += int(cmd.Val())
+	}
+
+	return removed, err
+}
+
+// Entry is an L2 cache entry as stored, for inspection. TTL is negative
+// for entries without expiry.
+type Entry struct {
+	Key   string
+	TTL   time.Duration
+	Bytes int
+	POIs  []domain.POI
+}
+
+// Keys lists up to limit keys matching the SCAN pattern, scanning every
+// master in cluster mode; zero means no limit. Like Invalidate it is an
+// admin action and skips the circuit breaker.
+func (c *RedisCache) Keys(ctx context.Context, pattern string, limit int) ([]string, error) {
+
+	var mu sync.Mutex
+
+	var keys []string
+
+	scan := func(ctx context.Context, node redis.Cmdable) error {
+
+		iter := node.Scan(ctx, 0, pattern, scanBatch).Iterator()
+
+		for iter.Next(ctx) {
+
+			mu.Lock()
+
+			if limit > 0 && len(keys) >= limit {
+				mu.Unlock()
+				return nil
+			}
+
+			keys = append(keys, iter.Val())
+
+			mu.Unlock()
+		}
+
+		return iter.Err()
+	}
+
+	var err error
+
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+	} else {
+		err = scan(ctx, c.client)
+	}
+
+	sort.Strings(keys)
+
+	return keys, err
+}
+
+// Inspect reads the entry stored under key, skipping the circuit
+// breaker. found is false if there is none.
+func (c *RedisCache) Inspect(ctx context.Context, key string) (entry Entry, found bool, err error) {
+
+	pipe := c.client.Pipeline()
+
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+
+	_, err = pipe.Exec(ctx)
+
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, false, nil
+	}
+
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	data := []byte(get.Val())
+
+	pois, err := c.serializer.Decode(data)
+
+	if err != nil {
+		return Entry{}, true, err
+	}
+
+	return Entry{Key: key, TTL: ttl.Val(), Bytes: len(data), POIs: pois}, true, nil
+}
+
+// scanPattern narrows the SCAN to the keys sel can match: area selectors
+// only reach tiles, and every tile overlapping a geohash shares its first
+// minTilePrecision characters with it.
+func (s Selector) scanPattern() string {
+
+	switch {
+
+	case s.Prefix != "":
+		return globEscape(s.Prefix) + "*"
+
+	case s.Geohash != "":
+		return tileKeyPrefix + globEscape(s.Geohash[:min(len(s.Geohash), minTilePrecision)]) + "*"
+
+	case s.BBox != nil:
+		return tileKeyPrefix + "*"
+	}
+
+	return keyspace + "*"
+}
+
+// globEscape quotes the characters SCAN MATCH treats as patterns.
+func globEscape(s string) string {
+
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
 }
 
 var _ Cache = (*RedisCache)(nil)
+var _ TTLGetter = (*RedisCache)(nil)
+var _ Invalidator = (*RedisCache)(nil)
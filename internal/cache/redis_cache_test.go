@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// unreachableRedis returns an address nothing listens on.
+func unreachableRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := listener.Addr().String()
+	listener.Close()
+
+	return addr
+}
+
+func TestRedisCache_BreakerBypassesUnhealthyRedis(t *testing.T) {
+	cfg := config.RedisConfig{
+		Mode:        "standalone",
+		Addr:        unreachableRedis(t),
+		DialTimeout: 100 * time.Millisecond,
+		OpTimeout:   200 * time.Millisecond,
+		CircuitBreaker: config.CircuitBreakerConfig{
+			Window:         time.Minute,
+			FailureRate:    0.5,
+			MinRequests:    2,
+			ResetTimeout:   time.Minute,
+			HalfOpenProbes: 1,
+			SlowCallRate:   1,
+		},
+	}
+
+	client, err := NewRedisClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	serializer, _ := NewSerializer("json", "none", 0)
+
+	c := NewRedisCache(client, serializer, cfg)
+
+	c.Set("poi:a", []domain.POI{{ID: "1"}}, time.Minute)
+
+	if _, found := c.Get("poi:a"); found {
+		t.Fatal("Expected miss from unreachable redis")
+	}
+
+	if c.BreakerState() != circuitbreaker.StateOpen {
+		t.Fatalf("Expected breaker open, got %s", c.BreakerState())
+	}
+
+	start := time.Now()
+
+	if _, found := c.Get("poi:a"); found {
+		t.Fatal("Expected miss while bypassed")
+	}
+
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected bypassed read to return immediately, took %s", elapsed)
+	}
+}
+
+func TestNewRedisClient_RejectsMissingCA(t *testing.T) {
+	_, err := NewRedisClient(config.RedisConfig{
+		Mode: "standalone",
+		Addr: "localhost:6379",
+		TLS:  config.RedisTLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"},
+	})
+
+	if err == nil {
+		t.Fatal("Expected error for missing CA file, got nil")
+	}
+}
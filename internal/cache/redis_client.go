@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisClient connects to a standalone server, a Sentinel-managed
+// master or a Redis Cluster depending on cfg.Mode.
+func NewRedisClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+
+	tlsConfig, err := redisTLS(cfg.TLS)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Endpoints(),
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		TLSConfig:        tlsConfig,
+	}
+
+	switch cfg.Mode {
+
+	case "sentinel":
+		opts.MasterName = cfg.MasterName
+
+	case "cluster":
+		opts.IsClusterMode = true
+	}
+
+	return redis.NewUniversalClient(opts), nil
+}
+
+func redisTLS(cfg config.RedisTLSConfig) (*tls.Config, error) {
+
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+
+		pem, err := os.ReadFile(cfg.CAFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("redis tls ca: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("redis tls ca: no certificates found")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("redis tls client cert: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
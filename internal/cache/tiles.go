@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/geo"
+	"github.com/mmcloughlin/geohash"
+)
+
+const (
+	// Tiles are geohash cells between about 156km (3) and 153m (7) wide.
+	minTilePrecision = 3
+	maxTilePrecision = 7
+
+	// maxTiles bounds the cells one query is split into; the finest
+	// precision that stays within it is used. The precision depends only
+	// on the area's size, so panned views of the same size share tiles.
+	maxTiles = 36
+
+	metersPerDegree = 111320.0
+)
+
+// TileCover is the set of geohash tiles covering a query's area. Tiles
+// partition the plane, so each POI belongs to exactly one of them.
+type TileCover struct {
+	Precision uint
+	Hashes    []string
+}
+
+// ErrAreaTooLarge means a query's area needs more than maxTiles tiles
+// even at the coarsest precision.
+var ErrAreaTooLarge = errors.New("query area too large")
+
+// CoverQuery returns the tiles covering query's bounding box, or the
+// circle around its center for radius queries. It fails with
+// ErrAreaTooLarge rather than cover an area with more than maxTiles.
+func CoverQuery(query domain.SearchQuery) (TileCover, error) {
+
+	bounds := queryBounds(query)
+
+	for precision := uint(maxTilePrecision); precision >= minTilePrecision; precision-- {
+
+		if maxTileCount(bounds, precision) <= maxTiles {
+			return TileCover{Precision: precision, Hashes: coverTiles(bounds, precision)}, nil
+		}
+	}
+
+	return TileCover{}, ErrAreaTooLarge
+}
+
+// CheckArea reports whether query's area can be covered by tiles, so
+// callers can reject it before searching.
+func CheckArea(query domain.SearchQuery) error {
+
+	if maxTileCount(queryBounds(query), minTilePrecision) > maxTiles {
+		return ErrAreaTooLarge
+	}
+
+	return nil
+}
+
+// TileKey is the cache key of one tile for a category filter.
+func TileKey(hash string, categories []string) string {
+
+	return fmt.Sprintf("%s%s:%s", tileKeyPrefix, hash, normalizeCategories(categories))
+}
+
+// DegradedKey is where a tile filled from an incomplete answer is cached,
+// apart from its TileKey so a complete answer always takes precedence.
+func DegradedKey(tileKey string) string {
+	return tileKey + ":degraded"
+}
+
+// FailedKey marks a tile whose last fetch reached no provider.
+func FailedKey(tileKey string) string {
+	return tileKey + ":failed"
+}
+
+// ParseTileKey reverses TileKey. Categories are nil for the unfiltered
+// "all" key.
+func ParseTileKey(key string) (hash string, categories []string, ok bool) {
+
+	hash, cats, ok := parseTileKey(key)
+
+	if !ok || cats == "all" {
+		return hash, nil, ok
+	}
+
+	return hash, strings.Split(cats, ","), true
+}
+
+// TileOf returns the tile at precision containing poi.
+func TileOf(poi domain.POI, precision uint) string {
+
+	return geohash.EncodeWithPrecision(poi.Latitude, poi.Longitude, precision)
+}
+
+// FetchQuery builds the upstream query for the given tiles of query. It
+// carries both the tiles' bounding box and the circle around it, since
+// some providers only search by center and radius. The limit is the
+// query's for every tile, so outer tiles are not starved by inner ones;
+// each provider clamps it and the radius to its own limits.
+func FetchQuery(query domain.SearchQuery, hashes []string) domain.SearchQuery {
+
+	var bounds domain.BBox
+
+	for i, hash := range hashes {
+
+		box := geohash.BoundingBox(hash)
+
+		if i == 0 {
+			bounds = domain.BBox{MinLat: box.MinLat, MinLng: box.MinLng, MaxLat: box.MaxLat, MaxLng: box.MaxLng}
+			continue
+		}
+
+		bounds.MinLat = math.Min(bounds.MinLat, box.MinLat)
+		bounds.MinLng = math.Min(bounds.MinLng, box.MinLng)
+		bounds.MaxLat = math.Max(bounds.MaxLat, box.MaxLat)
+		bounds.MaxLng = math.Max(bounds.MaxLng, box.MaxLng)
+	}
+
+	lat := (bounds.MinLat + bounds.MaxLat) / 2
+	lng := (bounds.MinLng + bounds.MaxLng) / 2
+
+	radius := geo.DistanceMeters(lat, lng, bounds.MaxLat, bounds.MaxLng)
+
+	return domain.SearchQuery{
+		Latitude:   lat,
+		Longitude:  lng,
+		BBox:       &bounds,
+		Radius:     int(math.Ceil(radius)),
+		Limit:      query.Limit * len(hashes),
+		Categories: query.Categories,
+	}
+}
+
+// Contains reports whether poi lies within the exact query geometry.
+func Contains(query domain.SearchQuery, poi domain.POI) bool {
+
+	if b := query.BBox; b != nil {
+		return poi.Latitude >= b.MinLat && poi.Latitude <= b.MaxLat &&
+			poi.Longitude >= b.MinLng && poi.Longitude <= b.MaxLng
+	}
+
+	if query.Radius <= 0 {
+		return true
+	}
+
+	return geo.DistanceMeters(query.Latitude, query.Longitude, poi.Latitude, poi.Longitude) <= float64(query.Radius)
+}
+
+func queryBounds(query domain.SearchQuery) domain.BBox {
+
+	if query.BBox != nil {
+		return *query.BBox
+	}
+
+	dLat := float64(query.Radius) / metersPerDegree
+
+	dLng := dLat / math.Max(math.Cos(query.Latitude*math.Pi/180), 0.01)
+
+	return domain.BBox{
+		MinLat: math.Max(query.Latitude-dLat, -90),
+		MinLng: math.Max(query.Longitude-dLng, -180),
+		MaxLat: math.Min(query.Latitude+dLat, 90),
+		MaxLng: math.Min(query.Longitude+dLng, 180),
+	}
+}
+
+// cellSize returns the height and width in degrees of a geohash cell.
+// Longitude gets the extra bit when 5*precision is odd.
+func cellSize(precision uint) (float64, float64) {
+
+	bits := 5 * precision
+
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lngBits))
+}
+
+// gridRange returns the first and last cell index covering [min, max] on
+// an axis starting at origin with the given cell size.
+func gridRange(min, max, origin, size float64) (int, int) {
+
+	return int(math.Floor((min - origin) / size)), int(math.Floor((max - origin) / size))
+}
+
+// maxTileCount is the most cells an area the size of bounds can touch at
+// precision, whatever its alignment to the grid.
+func maxTileCount(bounds domain.BBox, precision uint) int {
+
+	height, width := cellSize(precision)
+
+	rows := math.Ceil((bounds.MaxLat-bounds.MinLat)/height) + 1
+	cols := math.Ceil((bounds.MaxLng-bounds.MinLng)/width) + 1
+
+	return int(rows * cols)
+}
+
+func coverTiles(bounds domain.BBox, precision uint) []string {
+
+	height, width := cellSize(precision)
+
+	r0, r1 := gridRange(bounds.MinLat, bounds.MaxLat, -90, height)
+	c0, c1 := gridRange(bounds.MinLng, bounds.MaxLng, -180, width)
+
+	var hashes []string
+
+	seen := map[string]bool{}
+
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+
+			// encode the cell center so rounding never picks a neighbour
+			lat := -90 + (float64(r)+0.5)*height
+			lng := -180 + (float64(c)+0.5)*width
+
+			hash := geohash.EncodeWithPrecision(lat, lng, precision)
+
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/mmcloughlin/geohash"
+)
+
+func mustCover(t *testing.T, query domain.SearchQuery) TileCover {
+
+	t.Helper()
+
+	cover, err := CoverQuery(query)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return cover
+}
+
+func TestCoverQuery_CoversArea(t *testing.T) {
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+	cover := mustCover(t, query)
+
+	if len(cover.Hashes) == 0 || len(cover.Hashes) > maxTiles {
+		t.Fatalf("Expected 1..%d tiles, got %d", maxTiles, len(cover.Hashes))
+	}
+
+	bounds := queryBounds(query)
+
+	corners := [][2]float64{
+		{bounds.MinLat, bounds.MinLng},
+		{bounds.MinLat, bounds.MaxLng},
+		{bounds.MaxLat, bounds.MinLng},
+		{bounds.MaxLat, bounds.MaxLng},
+		{query.Latitude, query.Longitude},
+	}
+
+	tiles := map[string]bool{}
+
+	for _, h := range cover.Hashes {
+		tiles[h] = true
+	}
+
+	for _, c := range corners {
+
+		hash := geohash.EncodeWithPrecision(c[0], c[1], cover.Precision)
+
+		if !tiles[hash] {
+			t.Errorf("Expected tile %s covering %v to be in cover", hash, c)
+		}
+	}
+}
+
+func TestCoverQuery_PanningSharesTiles(t *testing.T) {
+
+	a := mustCover(t, domain.SearchQuery{BBox: &domain.BBox{MinLat: 59.32, MinLng: 18.05, MaxLat: 59.34, MaxLng: 18.08}})
+	b := mustCover(t, domain.SearchQuery{BBox: &domain.BBox{MinLat: 59.321, MinLng: 18.052, MaxLat: 59.341, MaxLng: 18.082}})
+
+	if a.Precision != b.Precision {
+		t.Fatalf("Expected same precision, got %d and %d", a.Precision, b.Precision)
+	}
+
+	shared := 0
+
+	inA := map[string]bool{}
+
+	for _, h := range a.Hashes {
+		inA[h] = true
+	}
+
+	for _, h := range b.Hashes {
+		if inA[h] {
+			shared++
+		}
+	}
+
+	if shared == 0 {
+		t.Error("Expected panned views to share tiles")
+	}
+}
+
+func TestCoverQuery_LargerAreaCoarserTiles(t *testing.T) {
+
+	small := mustCover(t, domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 500})
+	large := mustCover(t, domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 20000})
+
+	if large.Precision >= small.Precision {
+		t.Errorf("Expected coarser tiles for larger radius, got %d vs %d", large.Precision, small.Precision)
+	}
+}
+
+func TestCoverQuery_RejectsOversizedArea(t *testing.T) {
+
+	world := domain.SearchQuery{BBox: &domain.BBox{MinLat: -80, MinLng: -170, MaxLat: 80, MaxLng: 170}}
+
+	if _, err := CoverQuery(world); err != ErrAreaTooLarge {
+		t.Errorf("Expected ErrAreaTooLarge, got %v", err)
+	}
+
+	if err := CheckArea(world); err != ErrAreaTooLarge {
+		t.Errorf("Expected CheckArea to reject the area, got %v", err)
+	}
+
+	// the coarsest precision still covers a few degrees
+	region := domain.SearchQuery{BBox: &domain.BBox{MinLat: 55, MinLng: 11, MaxLat: 60, MaxLng: 16}}
+
+	cover := mustCover(t, region)
+
+	if cover.Precision != minTilePrecision || len(cover.Hashes) > maxTiles {
+		t.Errorf("Expected at most %d tiles at precision %d, got %d at %d", maxTiles, minTilePrecision, len(cover.Hashes), cover.Precision)
+	}
+
+	if err := CheckArea(region); err != nil {
+		t.Errorf("Expected CheckArea to accept the area, got %v", err)
+	}
+}
+
+func TestFetchQuery_CoversTiles(t *testing.T) {
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 50, Categories: []string{"cafe"}}
+
+	cover := mustCover(t, query)
+
+	fetch := FetchQuery(query, cover.Hashes)
+
+	for _, h := range cover.Hashes {
+
+		box := geohash.BoundingBox(h)
+
+		if box.MinLat < fetch.BBox.MinLat || box.MaxLng > fetch.BBox.MaxLng {
+			t.Errorf("Expected fetch bbox to contain tile %s", h)
+		}
+	}
+
+	if fetch.Limit != 50*len(cover.Hashes) || len(fetch.Categories) != 1 || fetch.Radius <= 1000 {
+		t.Errorf("Expected the limit per tile, categories and enclosing radius carried over, got %+v", fetch)
+	}
+}
+
+func TestContains(t *testing.T) {
+
+	radius := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 100}
+
+	if !Contains(radius, domain.POI{Latitude: 59.3293, Longitude: 18.0687}) {
+		t.Error("Expected nearby POI inside radius")
+	}
+
+	if Contains(radius, domain.POI{Latitude: 59.34, Longitude: 18.0686}) {
+		t.Error("Expected far POI outside radius")
+	}
+
+	bbox := domain.SearchQuery{BBox: &domain.BBox{MinLat: 1, MinLng: 1, MaxLat: 2, MaxLng: 2}}
+
+	if Contains(bbox, domain.POI{Latitude: 2.5, Longitude: 1.5}) {
+		t.Error("Expected POI outside bbox to be excluded")
+	}
+}
+
+func TestTileKey_NormalizesCategories(t *testing.T) {
+
+	if TileKey("u6sce", []string{"Cafe", "bar"}) != TileKey("u6sce", []string{"bar", "cafe"}) {
+		t.Error("Expected category order and case not to matter")
+	}
+}
+
+func TestParseTileKey_RoundTrip(t *testing.T) {
+
+	hash, cats, ok := ParseTileKey(TileKey("u6sce", []string{"Cafe", "bar"}))
+
+	if !ok || hash != "u6sce" || len(cats) != 2 || cats[0] != "bar" || cats[1] != "cafe" {
+		t.Errorf("Expected u6sce [bar cafe], got %q %v %v", hash, cats, ok)
+	}
+
+	if _, cats, _ := ParseTileKey(TileKey("u6sce", nil)); cats != nil {
+		t.Errorf("Expected nil categories for unfiltered tile, got %v", cats)
+	}
+}
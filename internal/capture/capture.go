@@ -0,0 +1,263 @@
+// Package capture samples API search requests to a JSON lines file that
+// cmd/replay plays back against an instance.
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// Record is one captured request and how it was answered. Query is the
+// raw query string; credentials travel in headers, which are not kept.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query"`
+	Status     int       `json:"status"`
+	DurationMs float64   `json:"duration_ms"`
+
+	// Cache and Result mirror the X-Hynek-Cache and X-Hynek-Result headers.
+	Cache  string `json:"cache,omitempty"`
+	Result string `json:"result,omitempty"`
+
+	Bytes int `json:"bytes"`
+}
+
+// URL is the request's URL against the instance at base.
+func (r Record) URL(base string) string {
+
+	u := strings.TrimRight(base, "/") + r.Path
+
+	if r.Query != "" {
+		u += "?" + r.Query
+	}
+
+	return u
+}
+
+// queueSize bounds records waiting to be written; more are dropped so a
+// slow disk never holds up requests.
+const queueSize = 1024
+
+// Writer appends sampled records to a file from a background goroutine.
+type Writer struct {
+	file  *os.File
+	queue chan Record
+	done  chan struct{}
+
+	// queueMu guards queue against Close closing it under add.
+	queueMu sync.RWMutex
+	closed  bool
+
+	sampleRate float64
+	maxBytes   int64
+	size       int64
+}
+
+// Open appends to the capture file at path, creating it and its
+// directory if needed. sampleRate of requests are captured until the file
+// reaches maxBytes; zero means no limit.
+func Open(path string, sampleRate float64, maxBytes int64) (*Writer, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	w := &Writer{
+		file:       file,
+		queue:      make(chan Record, queueSize),
+		done:       make(chan struct{}),
+		sampleRate: sampleRate,
+		maxBytes:   maxBytes,
+		size:       info.Size(),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// Middleware captures a sample of the requests next serves.
+func (w *Writer) Middleware(next http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+
+		if rand.Float64() >= w.sampleRate {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		start := time.Now()
+
+		rec := &recorder{ResponseWriter: rw, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		w.add(Record{
+			Time:       start.UTC(),
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Status:     rec.status,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Cache:      rw.Header().Get("X-Hynek-Cache"),
+			Result:     rw.Header().Get("X-Hynek-Result"),
+			Bytes:      rec.bytes,
+		})
+	})
+}
+
+func (w *Writer) add(record Record) {
+
+	w.queueMu.RLock()
+	defer w.queueMu.RUnlock()
+
+	if w.closed {
+		metrics.CaptureRecords.WithLabelValues("dropped").Inc()
+		return
+	}
+
+	select {
+
+	case w.queue <- record:
+
+	default:
+		metrics.CaptureRecords.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Close writes the queued records and closes the file. Records of
+// requests still being served are dropped.
+func (w *Writer) Close() error {
+
+	w.queueMu.Lock()
+
+	w.closed = true
+	close(w.queue)
+
+	w.queueMu.Unlock()
+
+	<-w.done
+
+	return w.file.Close()
+}
+
+func (w *Writer) run() {
+
+	defer close(w.done)
+
+	full := false
+
+	for record := range w.queue {
+
+		line, err := json.Marshal(record)
+
+		if err != nil {
+			metrics.CaptureRecords.WithLabelValues("failed").Inc()
+			continue
+		}
+
+		line = append(line, '\n')
+
+		if w.maxBytes > 0 && w.size+int64(len(line)) > w.maxBytes {
+
+			if !full {
+				log.Printf("capture: %s reached max_bytes, capture stopped", w.file.Name())
+				full = true
+			}
+
+			metrics.CaptureRecords.WithLabelValues("dropped").Inc()
+			continue
+		}
+
+		n, err := w.file.Write(line)
+
+		w.size += int64(n)
+
+		if err != nil {
+			log.Printf("capture: %v", err)
+			metrics.CaptureRecords.WithLabelValues("failed").Inc()
+			continue
+		}
+
+		metrics.CaptureRecords.WithLabelValues("written").Inc()
+	}
+}
+
+// recorder notes the status and size of a response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+
+	n, err := r.ResponseWriter.Write(b)
+
+	r.bytes += n
+
+	return n, err
+}
+
+// Read calls fn with every record in a capture file, in order. Blank
+// lines are skipped.
+func Read(r io.Reader, fn func(Record) error) error {
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	line := 0
+
+	for scanner.Scan() {
+
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		var record Record
+
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,119 @@
+package capture
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriter_CapturesRequests(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "capture", "requests.jsonl")
+
+	w, err := Open(path, 1, 0)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := w.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Hynek-Cache", "hit")
+		rw.Header().Set("X-Hynek-Result", "complete")
+		rw.Write([]byte(`{"data":[]}`))
+	}))
+
+	for _, q := range []string{"lat=50.08&lng=14.42", "lat=59.33&lng=18.06&categories=cafe"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/search?"+q, nil))
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	defer file.Close()
+
+	var records []Record
+
+	if err := Read(file, func(r Record) error { records = append(records, r); return nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+
+	r := records[1]
+
+	if r.Path != "/v1/search" || r.Query != "lat=59.33&lng=18.06&categories=cafe" {
+		t.Errorf("Expected the request path and query, got %s %s", r.Path, r.Query)
+	}
+
+	if r.Status != http.StatusOK || r.Cache != "hit" || r.Result != "complete" || r.Bytes != 11 {
+		t.Errorf("Expected status 200, cache hit, complete, 11 bytes, got %+v", r)
+	}
+
+	if got := r.URL("http://localhost:8080/"); got != "http://localhost:8080/v1/search?lat=59.33&lng=18.06&categories=cafe" {
+		t.Errorf("Expected the replay URL, got %s", got)
+	}
+}
+
+func TestWriter_StopsAtMaxBytes(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+
+	w, err := Open(path, 1, 300)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := w.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/search?lat=1&lng=2", nil))
+	}
+
+	w.Close()
+
+	info, _ := os.Stat(path)
+
+	if info.Size() > 300 {
+		t.Errorf("Expected at most 300 bytes, got %d", info.Size())
+	}
+}
+
+func TestWriter_DropsRecordsAfterClose(t *testing.T) {
+
+	w, err := Open(filepath.Join(t.TempDir(), "requests.jsonl"), 1, 0)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := w.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a request still in flight at shutdown finishes without a record
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/search", nil))
+}
+
+func TestRead_ReportsBadLine(t *testing.T) {
+
+	err := Read(strings.NewReader("{\"path\":\"/v1/search\"}\n\nnot json\n"), func(Record) error { return nil })
+
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error on line 3, got %v", err)
+	}
+}
@@ -14,72 +14,352 @@ const (
 	StateHalfOpen
 )
 
+func (s State) String() string {
+
+	switch s {
+
+	case StateClosed:
+		return "closed"
+
+	case StateOpen:
+		return "open"
+
+	case StateHalfOpen:
+		return "half_open"
+
+	default:
+		return "unknown"
+	}
+}
+
+// Outcome is the result of a call admitted by Allow.
+type Outcome int
+
+const (
+	Success Outcome = iota
+	Failure
+
+	// Ignored releases the call without counting it, e.g. when the caller
+	// gave up before the provider answered.
+	Ignored
+)
+
+// Config controls when a breaker trips and how it recovers.
+type Config struct {
+	// Window is the length of the sliding window over which failure and
+	// slow-call rates are computed.
+	Window time.Duration
+
+	// FailureRate (0..1] trips the breaker once reached within Window.
+	FailureRate float64
+
+	// MinRequests is the call volume required in Window before rates are
+	// evaluated, so a single early failure does not trip the breaker.
+	MinRequests int
+
+	// OpenTimeout is how long the breaker stays open before probing.
+	OpenTimeout time.Duration
+
+	// HalfOpenProbes is both the number of concurrent probe calls allowed
+	// while half-open and the number of successes required to close.
+	HalfOpenProbes int
+
+	// SlowCallDuration marks successful calls at or above it as slow.
+	// Zero disables slow-call detection.
+	SlowCallDuration time.Duration
+
+	// SlowCallRate (0..1] trips the breaker once reached within Window.
+	SlowCallRate float64
+
+	// OnStateChange, if set, is called after every transition. It runs
+	// with the breaker unlocked.
+	OnStateChange func(name string, from State, to State)
+}
+
+const windowBuckets = 10
+
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+	slow     int
+}
+
 type CircuitBreaker struct {
 	mu sync.Mutex
 
-	state State
+	name   string
+	config Config
 
-	failures    int
-	lastFailure time.Time
+	state    State
+	openedAt time.Time
 
-	failureThreshold int
-	openTimeout      time.Duration
+	// generation changes on every transition so outcomes of calls started
+	// in an earlier state are ignored.
+	generation uint64
+
+	buckets [windowBuckets]bucket
+
+	probesInFlight int
+	probeSuccesses int
+
+	now func() time.Time
 }
 
-func New(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+func New(name string, config Config) *CircuitBreaker {
+
 	return &CircuitBreaker{
-		state:            StateClosed,
-		failureThreshold: failureThreshold,
-		openTimeout:      openTimeout,
+		name:   name,
+		config: withDefaults(config),
+		state:  StateClosed,
+		now:    time.Now,
 	}
 }
 
-func (cb *CircuitBreaker) Allow() bool {
+// SetConfig replaces the breaker's config, keeping its state and the
+// calls recorded so far.
+func (cb *CircuitBreaker) SetConfig(config Config) {
 
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	cb.config = withDefaults(config)
+}
+
+func withDefaults(config Config) Config {
+
+	if config.HalfOpenProbes < 1 {
+		config.HalfOpenProbes = 1
+	}
+
+	if config.MinRequests < 1 {
+		config.MinRequests = 1
+	}
+
+	return config
+}
+
+// State returns the current state without side effects.
+func (cb *CircuitBreaker) State() State {
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		return StateHalfOpen
+	}
+
+	return cb.state
+}
+
+// Allow reports whether a call may proceed. When it returns a non-nil
+// done func, the caller must invoke it exactly once with the outcome.
+func (cb *CircuitBreaker) Allow() (done func(outcome Outcome, elapsed time.Duration), err error) {
+
+	cb.mu.Lock()
+
+	now := cb.now()
+
+	var transition func()
+
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		transition = cb.setState(StateHalfOpen, now)
+	}
+
+	switch cb.state {
+
+	case StateOpen:
+		cb.mu.Unlock()
+		return nil, ErrCircuitOpen
+
+	case StateHalfOpen:
+
+		if cb.probesInFlight >= cb.config.HalfOpenProbes {
+			cb.mu.Unlock()
+			runTransition(transition)
+			return nil, ErrCircuitOpen
+		}
+
+		cb.probesInFlight++
+	}
+
+	generation := cb.generation
+
+	cb.mu.Unlock()
+
+	runTransition(transition)
+
+	return func(outcome Outcome, elapsed time.Duration) {
+		cb.record(generation, outcome, elapsed)
+	}, nil
+}
+
+func (cb *CircuitBreaker) record(generation uint64, outcome Outcome, elapsed time.Duration) {
+
+	cb.mu.Lock()
+
+	if generation != cb.generation {
+		cb.mu.Unlock()
+		return
+	}
+
+	if outcome == Ignored {
+
+		if cb.state == StateHalfOpen {
+			cb.probesInFlight--
+		}
+
+		cb.mu.Unlock()
+		return
+	}
+
+	now := cb.now()
+
+	success := outcome == Success
+
+	slow := success &&
+		cb.config.SlowCallDuration > 0 &&
+		elapsed >= cb.config.SlowCallDuration
+
+	var transition func()
+
 	switch cb.state {
 
 	case StateClosed:
-		return true
 
-	case StateOpen:
+		b := cb.currentBucket(now)
 
-		if time.Since(cb.lastFailure) > cb.openTimeout {
-			cb.state = StateHalfOpen
-			return true
+		b.total++
+
+		if !success {
+			b.failures++
 		}
 
-		return false
+		if slow {
+			b.slow++
+		}
+
+		if cb.shouldTrip(now) {
+			transition = cb.setState(StateOpen, now)
+		}
 
 	case StateHalfOpen:
-		return true
 
-	default:
-		return false
+		cb.probesInFlight--
+
+		if !success || slow {
+			transition = cb.setState(StateOpen, now)
+			break
+		}
+
+		cb.probeSuccesses++
+
+		if cb.probeSuccesses >= cb.config.HalfOpenProbes {
+			transition = cb.setState(StateClosed, now)
+		}
 	}
+
+	cb.mu.Unlock()
+
+	runTransition(transition)
 }
 
-func (cb *CircuitBreaker) Success() {
+func (cb *CircuitBreaker) currentBucket(now time.Time) *bucket {
 
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	width := cb.bucketWidth()
+
+	start := now.Truncate(width)
 
-	cb.failures = 0
-	cb.state = StateClosed
+	b := &cb.buckets[(start.UnixNano()/int64(width))%windowBuckets]
+
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+
+	return b
 }
 
-func (cb *CircuitBreaker) Failure() {
+func (cb *CircuitBreaker) bucketWidth() time.Duration {
 
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	width := cb.config.Window / windowBuckets
+
+	if width <= 0 {
+		width = time.Millisecond
+	}
+
+	return width
+}
+
+func (cb *CircuitBreaker) shouldTrip(now time.Time) bool {
+
+	cutoff := now.Add(-cb.config.Window)
+
+	var total, failures, slow int
+
+	for _, b := range cb.buckets {
+
+		if b.start.IsZero() || !b.start.After(cutoff) {
+			continue
+		}
+
+		total += b.total
+		failures += b.failures
+		slow += b.slow
+	}
+
+	if total < cb.config.MinRequests {
+		return false
+	}
+
+	if cb.config.FailureRate > 0 &&
+		float64(failures)/float64(total) >= cb.config.FailureRate {
+		return true
+	}
+
+	if cb.config.SlowCallDuration > 0 && cb.config.SlowCallRate > 0 &&
+		float64(slow)/float64(total) >= cb.config.SlowCallRate {
+		return true
+	}
+
+	return false
+}
+
+// setState must be called with mu held. It returns the state-change
+// callback to run once mu is released.
+func (cb *CircuitBreaker) setState(to State, now time.Time) func() {
+
+	from := cb.state
+
+	cb.state = to
+	cb.generation++
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+
+	switch to {
+
+	case StateOpen:
+		cb.openedAt = now
+
+	case StateClosed:
+		cb.buckets = [windowBuckets]bucket{}
+	}
+
+	if cb.config.OnStateChange == nil {
+		return nil
+	}
+
+	name := cb.name
+	onChange := cb.config.OnStateChange
+
+	return func() {
+		onChange(name, from, to)
+	}
+}
 
-	cb.failures++
-	cb.lastFailure = time.Now()
+func runTransition(transition func()) {
 
-	if cb.failures >= cb.failureThreshold {
-		cb.state = StateOpen
+	if transition != nil {
+		transition()
 	}
 }
 
@@ -0,0 +1,255 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestBreaker(cfg Config) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	cb := New("test", cfg)
+	cb.now = clock.now
+	return cb, clock
+}
+
+func defaultConfig() Config {
+	return Config{
+		Window:         10 * time.Second,
+		FailureRate:    0.5,
+		MinRequests:    4,
+		OpenTimeout:    5 * time.Second,
+		HalfOpenProbes: 2,
+		SlowCallRate:   0.5,
+	}
+}
+
+func call(t *testing.T, cb *CircuitBreaker, success bool, elapsed time.Duration) {
+	t.Helper()
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Expected call to be allowed, got %v", err)
+	}
+	if success {
+		done(Success, elapsed)
+	} else {
+		done(Failure, elapsed)
+	}
+}
+
+func TestCircuitBreaker_MinimumVolume(t *testing.T) {
+	cb, _ := newTestBreaker(defaultConfig())
+
+	for i := 0; i < 3; i++ {
+		call(t, cb, false, 0)
+	}
+
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed below minimum volume, got %s", cb.State())
+	}
+
+	call(t, cb, false, 0)
+
+	if cb.State() != StateOpen {
+		t.Fatalf("Expected open after reaching minimum volume, got %s", cb.State())
+	}
+
+	if _, err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_FailureRateBelowThreshold(t *testing.T) {
+	cb, _ := newTestBreaker(defaultConfig())
+
+	call(t, cb, false, 0)
+	call(t, cb, true, 0)
+	call(t, cb, true, 0)
+	call(t, cb, true, 0)
+
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed at 25%% failure rate, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_WindowSlides(t *testing.T) {
+	cb, clock := newTestBreaker(defaultConfig())
+
+	call(t, cb, false, 0)
+	call(t, cb, false, 0)
+	call(t, cb, false, 0)
+
+	clock.advance(11 * time.Second)
+
+	call(t, cb, false, 0)
+
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected old failures to fall out of the window, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbeLimit(t *testing.T) {
+	cb, clock := newTestBreaker(defaultConfig())
+
+	for i := 0; i < 4; i++ {
+		call(t, cb, false, 0)
+	}
+
+	clock.advance(6 * time.Second)
+
+	done1, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Expected first probe allowed, got %v", err)
+	}
+
+	done2, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Expected second probe allowed, got %v", err)
+	}
+
+	if _, err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected third concurrent probe rejected, got %v", err)
+	}
+
+	done1(Success, 0)
+
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("Expected half-open after a single probe success, got %s", cb.State())
+	}
+
+	done2(Success, 0)
+
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed after all probes succeeded, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	cb, clock := newTestBreaker(defaultConfig())
+
+	for i := 0; i < 4; i++ {
+		call(t, cb, false, 0)
+	}
+
+	clock.advance(6 * time.Second)
+
+	call(t, cb, false, 0)
+
+	if cb.State() != StateOpen {
+		t.Fatalf("Expected open after failed probe, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_SlowCalls(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.SlowCallDuration = time.Second
+	cb, _ := newTestBreaker(cfg)
+
+	call(t, cb, true, 2*time.Second)
+	call(t, cb, true, 2*time.Second)
+	call(t, cb, true, 10*time.Millisecond)
+	call(t, cb, true, 10*time.Millisecond)
+
+	if cb.State() != StateOpen {
+		t.Fatalf("Expected open at 50%% slow-call rate, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_StaleOutcomeIgnored(t *testing.T) {
+	cb, clock := newTestBreaker(defaultConfig())
+
+	stale, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		call(t, cb, false, 0)
+	}
+
+	clock.advance(6 * time.Second)
+
+	probe, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Expected probe allowed, got %v", err)
+	}
+
+	// The call started while closed must not count as a probe.
+	stale(Success, 0)
+	stale(Success, 0)
+
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("Expected stale outcomes to be ignored, got %s", cb.State())
+	}
+
+	probe(Success, 0)
+}
+
+func TestCircuitBreaker_StateChangeCallback(t *testing.T) {
+	cfg := defaultConfig()
+
+	var transitions []string
+	cfg.OnStateChange = func(name string, from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+
+	cb, clock := newTestBreaker(cfg)
+
+	for i := 0; i < 4; i++ {
+		call(t, cb, false, 0)
+	}
+
+	clock.advance(6 * time.Second)
+
+	call(t, cb, true, 0)
+	call(t, cb, true, 0)
+
+	want := []string{"closed->open", "open->half_open", "half_open->closed"}
+
+	if len(transitions) != len(want) {
+		t.Fatalf("Expected transitions %v, got %v", want, transitions)
+	}
+
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("Transition %d: expected %s, got %s", i, want[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_IgnoredReleasesProbe(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HalfOpenProbes = 1
+	cb, clock := newTestBreaker(cfg)
+
+	for i := 0; i < 4; i++ {
+		call(t, cb, false, 0)
+	}
+
+	clock.advance(6 * time.Second)
+
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("Expected probe allowed, got %v", err)
+	}
+
+	done(Ignored, 0)
+
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("Expected ignored probe not to change state, got %s", cb.State())
+	}
+
+	call(t, cb, true, 0)
+
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed after probe slot was released, got %s", cb.State())
+	}
+}
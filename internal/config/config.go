@@ -1,8 +1,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,50 +19,362 @@ type Config struct {
 	Redis     RedisConfig
 	Cache     CacheConfig
 	Providers ProvidersConfig
+	GraphQL   GraphQLConfig
+	GRPC      GRPCConfig
+	Batch     BatchConfig
+	Admin     AdminConfig
+	Warming   WarmingConfig
+	Store     StoreConfig
+	Overrides OverridesConfig
+	Identity  IdentityConfig
+	Capture   CaptureConfig
+	OpenAPI   OpenAPIConfig
+	Log       LogConfig
+	Health    HealthConfig
+	Costs     CostsConfig
+	Router    RouterConfig
 }
 
 type ServerConfig struct {
 	Port int
+
+	// APIKeys, when set, are required on every API request over HTTP and
+	// gRPC. RateLimit caps requests per second per API key, or per client
+	// IP without one; zero disables the limit.
+	APIKeys        []string
+	RateLimit      float64
+	RateLimitBurst int
+}
+
+// BatchConfig limits POST /v1/search/batch. ProviderBudget caps upstream
+// provider calls per batch; zero means unlimited.
+type BatchConfig struct {
+	MaxQueries     int
+	Concurrency    int
+	ProviderBudget int
+}
+
+// AdminConfig enables the /admin API, which only accepts APIKeys.
+type AdminConfig struct {
+	Enabled bool
+	APIKeys []string
+}
+
+// WarmingConfig controls cache warming. Every Interval the configured
+// Regions and the TopN most requested tiles are re-fetched, spending at
+// most ProviderBudget provider calls. QuietHours ("22:00-06:00", server
+// local time) pauses warming.
+type WarmingConfig struct {
+	Enabled        bool
+	Interval       time.Duration
+	TopN           int
+	ProviderBudget int
+	Limit          int
+	QuietHours     string
+	Regions        []WarmRegion
 }
 
+// WarmRegion is an area kept warm regardless of traffic: either BBox
+// (min_lat, min_lng, max_lat, max_lng) or a center and Radius in meters.
+type WarmRegion struct {
+	Latitude   float64   `mapstructure:"latitude"`
+	Longitude  float64   `mapstructure:"longitude"`
+	Radius     int       `mapstructure:"radius"`
+	BBox       []float64 `mapstructure:"bbox"`
+	Categories []string  `mapstructure:"categories"`
+}
+
+// StoreConfig controls the persistent POI store at Path. Records no
+// provider returned within Retention (zero keeps them forever) are pruned
+// every CompactionInterval, and the file is compacted once mostly free.
+// With Fallback the store answers searches no provider answered.
+type StoreConfig struct {
+	Enabled            bool
+	Path               string
+	Retention          time.Duration
+	CompactionInterval time.Duration
+	Fallback           bool
+}
+
+// OverridesConfig enables curated overrides. Rules are read from Path,
+// if set, on start and every config reload; rules added through the admin
+// API are shared in Redis and picked up every RefreshInterval.
+type OverridesConfig struct {
+	Enabled         bool
+	Path            string
+	RefreshInterval time.Duration
+}
+
+// IdentityConfig enables canonical POI IDs. The cross-reference of
+// provider IDs lives in Redis; CacheSize links are also kept in memory.
+type IdentityConfig struct {
+	Enabled   bool
+	CacheSize int
+}
+
+// CaptureConfig enables traffic capture: SampleRate of /v1/search
+// requests are appended to Path as JSON lines for cmd/replay. Capture
+// stops once the file reaches MaxBytes; zero means no limit.
+type CaptureConfig struct {
+	Enabled    bool
+	Path       string
+	SampleRate float64
+	MaxBytes   int64
+}
+
+// OpenAPIConfig sets how HTTP traffic is checked against the OpenAPI
+// document: off, log (violations are logged and counted) or strict
+// (violations are rejected).
+type OpenAPIConfig struct {
+	Validation string
+}
+
+// LogConfig sets up logging. Level is debug, info, warn or error and
+// Format json or text. Request lines of successful requests are logged
+// at SampleRate; failed and degraded requests, and those slower than
+// SlowRequest, are always logged. Zero SlowRequest disables the latter.
+type LogConfig struct {
+	Level       string
+	Format      string
+	SampleRate  float64
+	SlowRequest time.Duration
+}
+
+// HealthConfig sets how provider health is judged for /v1/status and
+// /ready. A provider is unhealthy while its circuit breaker is not
+// closed, or when less than MinSuccessRate of its last Window calls
+// succeeded. Readiness is none (providers do not affect readiness), any
+// (ready while at least one provider is healthy) or all; any and all
+// need canaries.
+type HealthConfig struct {
+	Window         int
+	MinSuccessRate float64
+	Readiness      string
+	Canary         CanaryConfig
+}
+
+// CanaryConfig enables synthetic searches: every Interval, each provider
+// is asked for Radius metres around Latitude, Longitude, so health stays
+// current on idle replicas. Canaries are real provider calls.
+type CanaryConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	Latitude  float64
+	Longitude float64
+	Radius    int
+}
+
+// CostsConfig enables cost accounting for the paid providers. Their
+// calls are priced per SKU and counted per UTC day and month in Redis,
+// shared by all replicas; counts are flushed every FlushInterval. A
+// provider is skipped once it reaches a budget or call limit, or for
+// QuotaCooldown after it reports its upstream quota exhausted.
+type CostsConfig struct {
+	Enabled       bool
+	Currency      string
+	FlushInterval time.Duration
+	QuotaCooldown time.Duration
+
+	Google     ProviderCostConfig
+	Foursquare ProviderCostConfig
+}
+
+// ProviderCostConfig prices one provider's calls and caps them. Prices
+// maps each SKU a call is billed as to its price per call. Zero budgets
+// and call limits mean no cap.
+type ProviderCostConfig struct {
+	Prices        map[string]float64
+	DailyBudget   float64
+	MonthlyBudget float64
+	DailyCalls    int64
+	MonthlyCalls  int64
+}
+
+// CostSKUs lists the SKUs each paid provider bills its searches as.
+var CostSKUs = map[string][]string{
+	"google":     {"nearby_search", "contact_data", "atmosphere_data"},
+	"foursquare": {"places_search", "premium_fields"},
+}
+
+// RouterConfig sets how searches are spread over the providers. With the
+// parallel strategy every provider is asked at once; with tiered,
+// providers are asked in order of their tier and a search only moves on
+// to the next tier when Tiered says the answers so far fall short.
+// Timeout is the deadline of the whole search, across tiers.
+type RouterConfig struct {
+	Strategy string
+	Timeout  time.Duration
+	Tiered   TieredConfig
+}
+
+// TieredConfig is when a tiered search escalates: while it found fewer
+// than MinResults places, while less than MinCompleteness of them have
+// every one of CompletenessFields, or always for Categories.
+// EscalationTime is kept back from the deadline for each later tier.
+type TieredConfig struct {
+	MinResults         int
+	MinCompleteness    float64
+	CompletenessFields []string
+	Categories         []string
+	EscalationTime     time.Duration
+}
+
+// TierFields are the POI fields tiered routing can require.
+var TierFields = []string{"rating", "opening_hours", "website", "phone", "address"}
+
+type GRPCConfig struct {
+	Enabled bool
+	Port    int
+}
+
+// RedisConfig selects how the shared L2 cache is reached. Mode is
+// standalone, sentinel or cluster; sentinel and cluster use Addrs, and
+// sentinel also needs MasterName.
 type RedisConfig struct {
-	Addr     string
-	Password string
-	DB       int
+	Mode             string
+	Addr             string
+	Addrs            []string
+	MasterName       string
+	Username         string
+	Password         string
+	SentinelPassword string
+	DB               int
+
+	TLS RedisTLSConfig
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// OpTimeout bounds each cache read or write, retries included, so a
+	// slow Redis never holds up a search for long.
+	OpTimeout time.Duration
+
+	// CircuitBreaker bypasses Redis while it keeps failing; the service
+	// then runs on the in-memory cache alone.
+	CircuitBreaker CircuitBreakerConfig
+
+	// Required makes /ready fail while Redis is unreachable. When false
+	// the service stays ready and reports itself degraded instead.
+	Required bool
+}
+
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// Endpoints returns the addresses to dial, falling back to Addr when
+// Addrs is empty.
+func (c RedisConfig) Endpoints() []string {
+
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+
+	return []string{c.Addr}
+}
+
+type GraphQLConfig struct {
+	Enabled       bool
+	MaxComplexity int
 }
 
+// CacheConfig also selects the L2 entry format. Codec is json or
+// protobuf; Compression is none, zstd or snappy and applies to entries of
+// at least CompressThreshold bytes. Entries in any format stay readable.
 type CacheConfig struct {
-	TTL time.Duration
+	TTL         time.Duration
+	DegradedTTL time.Duration
+
+	// EmptyTTL applies to areas where every provider found nothing.
+	// Searches no provider answered are cached as failures for
+	// NegativeTTL, doubling per consecutive failure up to NegativeMaxTTL;
+	// zero disables that.
+	EmptyTTL       time.Duration
+	NegativeTTL    time.Duration
+	NegativeMaxTTL time.Duration
+
+	Codec             string
+	Compression       string
+	CompressThreshold int
 }
 
 type ProvidersConfig struct {
-	OSM        ProviderConfig       `mapstructure:"osm"`
-	Google     GoogleProviderConfig `mapstructure:"google"`
-	HERE       ProviderConfig       `mapstructure:"here"`
+	OSM        ProviderConfig           `mapstructure:"osm"`
+	Google     GoogleProviderConfig     `mapstructure:"google"`
+	HERE       ProviderConfig           `mapstructure:"here"`
 	Foursquare FoursquareProviderConfig `mapstructure:"foursquare"`
+
+	Fixtures FixturesConfig `mapstructure:"fixtures"`
+}
+
+// FixturesConfig sets whether providers record their HTTP traffic to
+// fixture files under Dir, replay it from them without a network, or
+// neither.
+type FixturesConfig struct {
+	Mode string `mapstructure:"mode"`
+	Dir  string `mapstructure:"dir"`
+}
+
+type CircuitBreakerConfig struct {
+	Window           time.Duration `mapstructure:"window"`
+	FailureRate      float64       `mapstructure:"failure_rate"`
+	MinRequests      int           `mapstructure:"min_requests"`
+	ResetTimeout     time.Duration `mapstructure:"reset_timeout"`
+	HalfOpenProbes   int           `mapstructure:"half_open_probes"`
+	SlowCallDuration time.Duration `mapstructure:"slow_call_duration"`
+	SlowCallRate     float64       `mapstructure:"slow_call_rate"`
+}
+
+type HedgeConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	Percentile        float64       `mapstructure:"percentile"`
+	MinDelay          time.Duration `mapstructure:"min_delay"`
+	Budget            float64       `mapstructure:"budget"`
+	AlternateEndpoint string        `mapstructure:"alternate_endpoint"`
 }
 
 type ProviderConfig struct {
-	Enabled  bool          `mapstructure:"enabled"`
-	Priority int           `mapstructure:"priority"`
-	Timeout  time.Duration `mapstructure:"timeout"`
-	Retries  int           `mapstructure:"retries"`
+	Enabled   bool          `mapstructure:"enabled"`
+	Priority  int           `mapstructure:"priority"`
+	Tier      int           `mapstructure:"tier"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	Retries   int           `mapstructure:"retries"`
+	RateLimit float64       `mapstructure:"rate_limit"`
+
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"cb"`
+	Hedge          HedgeConfig          `mapstructure:"hedge"`
 }
 
 type GoogleProviderConfig struct {
-	Enabled  bool          `mapstructure:"enabled"`
-	ApiKey   string        `mapstructure:"api_key"`
-	Priority int           `mapstructure:"priority"`
-	Timeout  time.Duration `mapstructure:"timeout"`
-	Retries  int           `mapstructure:"retries"`
+	Enabled   bool          `mapstructure:"enabled"`
+	ApiKey    string        `mapstructure:"api_key"`
+	Priority  int           `mapstructure:"priority"`
+	Tier      int           `mapstructure:"tier"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	Retries   int           `mapstructure:"retries"`
+	RateLimit float64       `mapstructure:"rate_limit"`
+
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"cb"`
+	Hedge          HedgeConfig          `mapstructure:"hedge"`
 }
 
 type FoursquareProviderConfig struct {
-	Enabled  bool          `mapstructure:"enabled"`
-	ApiKey   string        `mapstructure:"api_key"`
-	Priority int           `mapstructure:"priority"`
-	Timeout  time.Duration `mapstructure:"timeout"`
-	Retries  int           `mapstructure:"retries"`
+	Enabled   bool          `mapstructure:"enabled"`
+	ApiKey    string        `mapstructure:"api_key"`
+	Priority  int           `mapstructure:"priority"`
+	Tier      int           `mapstructure:"tier"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	Retries   int           `mapstructure:"retries"`
+	RateLimit float64       `mapstructure:"rate_limit"`
+
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"cb"`
+	Hedge          HedgeConfig          `mapstructure:"hedge"`
 }
 
 func Load() *Config {
@@ -68,29 +385,7 @@ func Load() *Config {
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("./config")
 
-	viper.SetDefault("server.port", 8080)
-
-	viper.SetDefault("redis.addr", "localhost:6379")
-	viper.SetDefault("redis.password", "")
-	viper.SetDefault("redis.db", 0)
-	viper.SetDefault("providers.osm.enabled", true)
-	viper.SetDefault("providers.osm.weight", 10)
-	viper.SetDefault("providers.osm.priority", 10)
-	viper.SetDefault("providers.osm.timeout", "5s")
-	viper.SetDefault("providers.osm.retries", 1)
-	viper.SetDefault("providers.google.enabled", false)
-	viper.SetDefault("providers.google.weight", 10)
-	viper.SetDefault("providers.google.priority", 1)
-	viper.SetDefault("providers.google.timeout", "2s")
-	viper.SetDefault("providers.google.retries", 2)
-
-	viper.SetDefault("providers.foursquare.enabled", false)
-	viper.SetDefault("providers.foursquare.weight", 10)
-	viper.SetDefault("providers.foursquare.priority", 5)
-	viper.SetDefault("providers.foursquare.timeout", "3s")
-	viper.SetDefault("providers.foursquare.retries", 2)
-
-	viper.SetDefault("cache.ttl", "5m")
+	setDefaults(viper.GetViper())
 
 	viper.SetEnvPrefix("HYNEK_POI")
 
@@ -103,44 +398,1031 @@ func Load() *Config {
 		log.Println("No config file found, using defaults")
 	}
 
-	cfg := &Config{
+	return build(viper.GetViper())
+}
+
+// LoadFile reads the config file at path like Load, with defaults and
+// environment overrides applied, but fails if the file cannot be read.
+// It also returns the keys in the file the service does not know, which
+// are usually typos silently replaced by defaults. The config is not
+// validated.
+func LoadFile(path string) (*Config, []string, error) {
+
+	viper.SetConfigType("yaml")
+	viper.SetConfigFile(path)
+
+	setDefaults(viper.GetViper())
+
+	viper.SetEnvPrefix("HYNEK_POI")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("read config: %w", err)
+	}
+
+	file := viper.New()
+	file.SetConfigType("yaml")
+	file.SetConfigFile(path)
+
+	if err := file.ReadInConfig(); err != nil {
+		return nil, nil, fmt.Errorf("read config: %w", err)
+	}
+
+	known := viper.New()
+	setDefaults(known)
+
+	knownKeys := map[string]bool{}
+
+	for _, key := range append(known.AllKeys(), optionalKeys...) {
+		knownKeys[key] = true
+	}
+
+	var unknown []string
+
+	for _, key := range file.AllKeys() {
+		if !knownKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	return build(viper.GetViper()), unknown, nil
+}
+
+// optionalKeys are read without a default.
+var optionalKeys = []string{
+	"providers.google.api_key",
+	"providers.foursquare.api_key",
+	"redis.username",
+	"redis.sentinel_password",
+	"redis.tls.ca_file",
+	"redis.tls.cert_file",
+	"redis.tls.key_file",
+	"redis.tls.server_name",
+	"redis.tls.insecure_skip_verify",
+	"warming.regions",
+}
+
+// setDefaults registers the default of every setting on v.
+func setDefaults(v *viper.Viper) {
+
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.api_keys", []string{})
+	v.SetDefault("server.rate_limit", 0)
+	v.SetDefault("server.rate_limit_burst", 0)
+
+	v.SetDefault("redis.addr", "localhost:6379")
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.db", 0)
+	v.SetDefault("redis.mode", "standalone")
+	v.SetDefault("redis.addrs", []string{})
+	v.SetDefault("redis.master_name", "")
+	v.SetDefault("redis.tls.enabled", false)
+	v.SetDefault("redis.dial_timeout", "1s")
+	v.SetDefault("redis.read_timeout", "500ms")
+	v.SetDefault("redis.write_timeout", "500ms")
+	v.SetDefault("redis.op_timeout", "500ms")
+	v.SetDefault("redis.required", true)
+	v.SetDefault("redis.cb.window", "30s")
+	v.SetDefault("redis.cb.failure_rate", 0.5)
+	v.SetDefault("redis.cb.min_requests", 5)
+	v.SetDefault("redis.cb.reset_timeout", "10s")
+	v.SetDefault("redis.cb.half_open_probes", 1)
+	v.SetDefault("redis.cb.slow_call_duration", "0s")
+	v.SetDefault("redis.cb.slow_call_rate", 0.8)
+
+	v.SetDefault("providers.osm.enabled", true)
+	v.SetDefault("providers.osm.weight", 10)
+	v.SetDefault("providers.osm.priority", 10)
+	v.SetDefault("providers.osm.tier", 1)
+	v.SetDefault("providers.osm.timeout", "5s")
+	v.SetDefault("providers.osm.retries", 1)
+	v.SetDefault("providers.osm.rate_limit", 0)
+	v.SetDefault("providers.google.enabled", false)
+	v.SetDefault("providers.google.weight", 10)
+	v.SetDefault("providers.google.priority", 1)
+	v.SetDefault("providers.google.tier", 2)
+	v.SetDefault("providers.google.timeout", "2s")
+	v.SetDefault("providers.google.retries", 2)
+	v.SetDefault("providers.google.rate_limit", 0)
+
+	v.SetDefault("providers.foursquare.enabled", false)
+	v.SetDefault("providers.foursquare.weight", 10)
+	v.SetDefault("providers.foursquare.priority", 5)
+	v.SetDefault("providers.foursquare.tier", 2)
+	v.SetDefault("providers.foursquare.timeout", "3s")
+	v.SetDefault("providers.foursquare.retries", 2)
+	v.SetDefault("providers.foursquare.rate_limit", 0)
+
+	v.SetDefault("providers.fixtures.mode", "off")
+	v.SetDefault("providers.fixtures.dir", "testdata/fixtures")
+
+	for _, name := range []string{"osm", "google", "foursquare"} {
+
+		prefix := "providers." + name + ".cb."
+
+		v.SetDefault(prefix+"window", "60s")
+		v.SetDefault(prefix+"failure_rate", 0.5)
+		v.SetDefault(prefix+"min_requests", 10)
+		v.SetDefault(prefix+"reset_timeout", "30s")
+		v.SetDefault(prefix+"half_open_probes", 3)
+		v.SetDefault(prefix+"slow_call_duration", "0s")
+		v.SetDefault(prefix+"slow_call_rate", 0.8)
+
+		hedge := "providers." + name + ".hedge."
+
+		v.SetDefault(hedge+"enabled", false)
+		v.SetDefault(hedge+"percentile", 0.95)
+		v.SetDefault(hedge+"min_delay", "50ms")
+		v.SetDefault(hedge+"budget", 0.1)
+		v.SetDefault(hedge+"alternate_endpoint", "")
+	}
+
+	v.SetDefault("cache.ttl", "5m")
+	v.SetDefault("cache.degraded_ttl", "30s")
+	v.SetDefault("cache.empty_ttl", "30m")
+	v.SetDefault("cache.negative_ttl", "5s")
+	v.SetDefault("cache.negative_max_ttl", "1m")
+	v.SetDefault("cache.codec", "protobuf")
+	v.SetDefault("cache.compression", "zstd")
+	v.SetDefault("cache.compress_threshold", 1024)
+
+	v.SetDefault("graphql.enabled", true)
+	v.SetDefault("graphql.max_complexity", 2000)
+
+	v.SetDefault("batch.max_queries", 100)
+	v.SetDefault("batch.concurrency", 8)
+	v.SetDefault("batch.provider_budget", 200)
+
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.api_keys", []string{})
+
+	v.SetDefault("warming.enabled", false)
+	v.SetDefault("warming.interval", "4m")
+	v.SetDefault("warming.top_n", 100)
+	v.SetDefault("warming.provider_budget", 200)
+	v.SetDefault("warming.limit", 50)
+	v.SetDefault("warming.quiet_hours", "")
+
+	v.SetDefault("store.enabled", false)
+	v.SetDefault("store.path", "data/poi.db")
+	v.SetDefault("store.retention", "2160h")
+	v.SetDefault("store.compaction_interval", "1h")
+	v.SetDefault("store.fallback", true)
+
+	v.SetDefault("overrides.enabled", false)
+	v.SetDefault("overrides.path", "")
+	v.SetDefault("overrides.refresh_interval", "10s")
+
+	v.SetDefault("identity.enabled", false)
+	v.SetDefault("identity.cache_size", 100000)
+
+	v.SetDefault("capture.enabled", false)
+	v.SetDefault("capture.path", "data/requests.jsonl")
+	v.SetDefault("capture.sample_rate", 0.01)
+	v.SetDefault("capture.max_bytes", 100<<20)
+
+	v.SetDefault("openapi.validation", "off")
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+	v.SetDefault("log.sample_rate", 1.0)
+	v.SetDefault("log.slow_request", "1s")
+
+	v.SetDefault("health.window", 20)
+	v.SetDefault("health.min_success_rate", 0.5)
+	v.SetDefault("health.readiness", "none")
+	v.SetDefault("health.canary.enabled", false)
+	v.SetDefault("health.canary.interval", "1m")
+	v.SetDefault("health.canary.lat", 50.0875)
+	v.SetDefault("health.canary.lng", 14.4213)
+	v.SetDefault("health.canary.radius", 500)
+
+	v.SetDefault("costs.enabled", false)
+	v.SetDefault("costs.currency", "USD")
+	v.SetDefault("costs.flush_interval", "10s")
+	v.SetDefault("costs.quota_cooldown", "15m")
+
+	// list prices; check them against your contract
+	v.SetDefault("costs.google.prices.nearby_search", 0.032)
+	v.SetDefault("costs.google.prices.contact_data", 0.003)
+	v.SetDefault("costs.google.prices.atmosphere_data", 0.005)
+	v.SetDefault("costs.foursquare.prices.places_search", 0.015)
+	v.SetDefault("costs.foursquare.prices.premium_fields", 0.02)
+
+	for name := range CostSKUs {
+
+		prefix := "costs." + name + "."
+
+		v.SetDefault(prefix+"daily_budget", 0)
+		v.SetDefault(prefix+"monthly_budget", 0)
+		v.SetDefault(prefix+"daily_calls", 0)
+		v.SetDefault(prefix+"monthly_calls", 0)
+	}
+
+	v.SetDefault("router.strategy", "parallel")
+	v.SetDefault("router.timeout", "3s")
+	v.SetDefault("router.tiered.min_results", 10)
+	v.SetDefault("router.tiered.min_completeness", 0.5)
+	v.SetDefault("router.tiered.completeness_fields", []string{"rating", "opening_hours"})
+	v.SetDefault("router.tiered.categories", []string{})
+	v.SetDefault("router.tiered.escalation_time", "1s")
+
+	v.SetDefault("grpc.enabled", true)
+	v.SetDefault("grpc.port", 9090)
+}
+
+// Reload re-reads the config file Load found and returns the resulting
+// config, which has already been validated. The file is read into a
+// fresh viper instance, since the global one is re-read concurrently by
+// the file watcher.
+func Reload() (*Config, error) {
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	setDefaults(v)
+
+	v.SetEnvPrefix("HYNEK_POI")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg := build(v)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func build(v *viper.Viper) *Config {
+
+	return &Config{
 		Server: ServerConfig{
-			Port: viper.GetInt("server.port"),
+			Port:           v.GetInt("server.port"),
+			APIKeys:        splitList(v.GetStringSlice("server.api_keys")),
+			RateLimit:      v.GetFloat64("server.rate_limit"),
+			RateLimitBurst: v.GetInt("server.rate_limit_burst"),
 		},
 
 		Redis: RedisConfig{
-			Addr:     viper.GetString("redis.addr"),
-			Password: viper.GetString("redis.password"),
-			DB:       viper.GetInt("redis.db"),
+			Mode:             v.GetString("redis.mode"),
+			Addr:             v.GetString("redis.addr"),
+			Addrs:            splitList(v.GetStringSlice("redis.addrs")),
+			MasterName:       v.GetString("redis.master_name"),
+			Username:         v.GetString("redis.username"),
+			Password:         v.GetString("redis.password"),
+			SentinelPassword: v.GetString("redis.sentinel_password"),
+			DB:               v.GetInt("redis.db"),
+
+			TLS: RedisTLSConfig{
+				Enabled:            v.GetBool("redis.tls.enabled"),
+				CAFile:             v.GetString("redis.tls.ca_file"),
+				CertFile:           v.GetString("redis.tls.cert_file"),
+				KeyFile:            v.GetString("redis.tls.key_file"),
+				ServerName:         v.GetString("redis.tls.server_name"),
+				InsecureSkipVerify: v.GetBool("redis.tls.insecure_skip_verify"),
+			},
+
+			DialTimeout:  v.GetDuration("redis.dial_timeout"),
+			ReadTimeout:  v.GetDuration("redis.read_timeout"),
+			WriteTimeout: v.GetDuration("redis.write_timeout"),
+			OpTimeout:    v.GetDuration("redis.op_timeout"),
+
+			CircuitBreaker: buildCircuitBreaker(v, "redis.cb."),
+			Required:       v.GetBool("redis.required"),
 		},
 
 		Cache: CacheConfig{
-			TTL: viper.GetDuration("cache.ttl"),
+			TTL:         v.GetDuration("cache.ttl"),
+			DegradedTTL: v.GetDuration("cache.degraded_ttl"),
+
+			EmptyTTL:       v.GetDuration("cache.empty_ttl"),
+			NegativeTTL:    v.GetDuration("cache.negative_ttl"),
+			NegativeMaxTTL: v.GetDuration("cache.negative_max_ttl"),
+
+			Codec:             v.GetString("cache.codec"),
+			Compression:       v.GetString("cache.compression"),
+			CompressThreshold: v.GetInt("cache.compress_threshold"),
+		},
+
+		GraphQL: GraphQLConfig{
+			Enabled:       v.GetBool("graphql.enabled"),
+			MaxComplexity: v.GetInt("graphql.max_complexity"),
+		},
+
+		Batch: BatchConfig{
+			MaxQueries:     v.GetInt("batch.max_queries"),
+			Concurrency:    v.GetInt("batch.concurrency"),
+			ProviderBudget: v.GetInt("batch.provider_budget"),
+		},
+
+		Admin: AdminConfig{
+			Enabled: v.GetBool("admin.enabled"),
+			APIKeys: splitList(v.GetStringSlice("admin.api_keys")),
+		},
+
+		Warming: buildWarming(v),
+
+		Store: StoreConfig{
+			Enabled:            v.GetBool("store.enabled"),
+			Path:               v.GetString("store.path"),
+			Retention:          v.GetDuration("store.retention"),
+			CompactionInterval: v.GetDuration("store.compaction_interval"),
+			Fallback:           v.GetBool("store.fallback"),
+		},
+
+		Overrides: OverridesConfig{
+			Enabled:         v.GetBool("overrides.enabled"),
+			Path:            v.GetString("overrides.path"),
+			RefreshInterval: v.GetDuration("overrides.refresh_interval"),
+		},
+
+		Identity: IdentityConfig{
+			Enabled:   v.GetBool("identity.enabled"),
+			CacheSize: v.GetInt("identity.cache_size"),
+		},
+
+		Capture: CaptureConfig{
+			Enabled:    v.GetBool("capture.enabled"),
+			Path:       v.GetString("capture.path"),
+			SampleRate: v.GetFloat64("capture.sample_rate"),
+			MaxBytes:   v.GetInt64("capture.max_bytes"),
+		},
+
+		OpenAPI: OpenAPIConfig{
+			Validation: v.GetString("openapi.validation"),
+		},
+
+		Log: LogConfig{
+			Level:       v.GetString("log.level"),
+			Format:      v.GetString("log.format"),
+			SampleRate:  v.GetFloat64("log.sample_rate"),
+			SlowRequest: v.GetDuration("log.slow_request"),
+		},
+
+		Health: HealthConfig{
+			Window:         v.GetInt("health.window"),
+			MinSuccessRate: v.GetFloat64("health.min_success_rate"),
+			Readiness:      v.GetString("health.readiness"),
+
+			Canary: CanaryConfig{
+				Enabled:   v.GetBool("health.canary.enabled"),
+				Interval:  v.GetDuration("health.canary.interval"),
+				Latitude:  v.GetFloat64("health.canary.lat"),
+				Longitude: v.GetFloat64("health.canary.lng"),
+				Radius:    v.GetInt("health.canary.radius"),
+			},
+		},
+
+		Costs: CostsConfig{
+			Enabled:       v.GetBool("costs.enabled"),
+			Currency:      v.GetString("costs.currency"),
+			FlushInterval: v.GetDuration("costs.flush_interval"),
+			QuotaCooldown: v.GetDuration("costs.quota_cooldown"),
+
+			Google:     buildProviderCost(v, "google"),
+			Foursquare: buildProviderCost(v, "foursquare"),
+		},
+
+		Router: RouterConfig{
+			Strategy: v.GetString("router.strategy"),
+			Timeout:  v.GetDuration("router.timeout"),
+			Tiered: TieredConfig{
+				MinResults:         v.GetInt("router.tiered.min_results"),
+				MinCompleteness:    v.GetFloat64("router.tiered.min_completeness"),
+				CompletenessFields: splitList(v.GetStringSlice("router.tiered.completeness_fields")),
+				Categories:         splitList(v.GetStringSlice("router.tiered.categories")),
+				EscalationTime:     v.GetDuration("router.tiered.escalation_time"),
+			},
+		},
+
+		GRPC: GRPCConfig{
+			Enabled: v.GetBool("grpc.enabled"),
+			Port:    v.GetInt("grpc.port"),
 		},
 
 		Providers: ProvidersConfig{
 			OSM: ProviderConfig{
-				Enabled:  viper.GetBool("providers.osm.enabled"),
-				Priority: viper.GetInt("providers.osm.priority"),
-				Timeout:  viper.GetDuration("providers.osm.timeout"),
-				Retries:  viper.GetInt("providers.osm.retries"),
+				Enabled:   v.GetBool("providers.osm.enabled"),
+				Priority:  v.GetInt("providers.osm.priority"),
+				Tier:      v.GetInt("providers.osm.tier"),
+				Timeout:   v.GetDuration("providers.osm.timeout"),
+				Retries:   v.GetInt("providers.osm.retries"),
+				RateLimit: v.GetFloat64("providers.osm.rate_limit"),
+
+				CircuitBreaker: buildCircuitBreaker(v, "providers.osm.cb."),
+				Hedge:          buildHedge(v, "providers.osm.hedge."),
 			},
 			Google: GoogleProviderConfig{
-				Enabled:  viper.GetBool("providers.google.enabled"),
-				ApiKey:   viper.GetString("providers.google.api_key"),
-				Priority: viper.GetInt("providers.google.priority"),
-				Timeout:  viper.GetDuration("providers.google.timeout"),
-				Retries:  viper.GetInt("providers.google.retries"),
+				Enabled:   v.GetBool("providers.google.enabled"),
+				ApiKey:    v.GetString("providers.google.api_key"),
+				Priority:  v.GetInt("providers.google.priority"),
+				Tier:      v.GetInt("providers.google.tier"),
+				Timeout:   v.GetDuration("providers.google.timeout"),
+				Retries:   v.GetInt("providers.google.retries"),
+				RateLimit: v.GetFloat64("providers.google.rate_limit"),
+
+				CircuitBreaker: buildCircuitBreaker(v, "providers.google.cb."),
+				Hedge:          buildHedge(v, "providers.google.hedge."),
 			},
 			Foursquare: FoursquareProviderConfig{
-				Enabled:  viper.GetBool("providers.foursquare.enabled"),
-				ApiKey:   viper.GetString("providers.foursquare.api_key"),
-				Priority: viper.GetInt("providers.foursquare.priority"),
-				Timeout:  viper.GetDuration("providers.foursquare.timeout"),
-				Retries:  viper.GetInt("providers.foursquare.retries"),
+				Enabled:   v.GetBool("providers.foursquare.enabled"),
+				ApiKey:    v.GetString("providers.foursquare.api_key"),
+				Priority:  v.GetInt("providers.foursquare.priority"),
+				Tier:      v.GetInt("providers.foursquare.tier"),
+				Timeout:   v.GetDuration("providers.foursquare.timeout"),
+				Retries:   v.GetInt("providers.foursquare.retries"),
+				RateLimit: v.GetFloat64("providers.foursquare.rate_limit"),
+
+				CircuitBreaker: buildCircuitBreaker(v, "providers.foursquare.cb."),
+				Hedge:          buildHedge(v, "providers.foursquare.hedge."),
+			},
+
+			Fixtures: FixturesConfig{
+				Mode: v.GetString("providers.fixtures.mode"),
+				Dir:  v.GetString("providers.fixtures.dir"),
 			},
 		},
 	}
+}
+
+// splitList accepts both YAML lists and comma separated environment
+// values, dropping empty entries.
+func splitList(values []string) []string {
+
+	var out []string
+
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+
+	return out
+}
+
+func buildCircuitBreaker(v *viper.Viper, prefix string) CircuitBreakerConfig {
+
+	return CircuitBreakerConfig{
+		Window:           v.GetDuration(prefix + "window"),
+		FailureRate:      v.GetFloat64(prefix + "failure_rate"),
+		MinRequests:      v.GetInt(prefix + "min_requests"),
+		ResetTimeout:     v.GetDuration(prefix + "reset_timeout"),
+		HalfOpenProbes:   v.GetInt(prefix + "half_open_probes"),
+		SlowCallDuration: v.GetDuration(prefix + "slow_call_duration"),
+		SlowCallRate:     v.GetFloat64(prefix + "slow_call_rate"),
+	}
+}
+
+func buildWarming(v *viper.Viper) WarmingConfig {
+
+	var regions []WarmRegion
+
+	if err := v.UnmarshalKey("warming.regions", &regions); err != nil {
+		log.Printf("warming.regions ignored: %v", err)
+	}
+
+	return WarmingConfig{
+		Enabled:        v.GetBool("warming.enabled"),
+		Interval:       v.GetDuration("warming.interval"),
+		TopN:           v.GetInt("warming.top_n"),
+		ProviderBudget: v.GetInt("warming.provider_budget"),
+		Limit:          v.GetInt("warming.limit"),
+		QuietHours:     v.GetString("warming.quiet_hours"),
+		Regions:        regions,
+	}
+}
+
+func buildProviderCost(v *viper.Viper, name string) ProviderCostConfig {
+
+	prefix := "costs." + name + "."
+
+	prices := map[string]float64{}
+
+	for _, sku := range CostSKUs[name] {
+		prices[sku] = v.GetFloat64(prefix + "prices." + sku)
+	}
+
+	return ProviderCostConfig{
+		Prices:        prices,
+		DailyBudget:   v.GetFloat64(prefix + "daily_budget"),
+		MonthlyBudget: v.GetFloat64(prefix + "monthly_budget"),
+		DailyCalls:    v.GetInt64(prefix + "daily_calls"),
+		MonthlyCalls:  v.GetInt64(prefix + "monthly_calls"),
+	}
+}
+
+func buildHedge(v *viper.Viper, prefix string) HedgeConfig {
+
+	return HedgeConfig{
+		Enabled:           v.GetBool(prefix + "enabled"),
+		Percentile:        v.GetFloat64(prefix + "percentile"),
+		MinDelay:          v.GetDuration(prefix + "min_delay"),
+		Budget:            v.GetFloat64(prefix + "budget"),
+		AlternateEndpoint: v.GetString(prefix + "alternate_endpoint"),
+	}
+}
+
+// Validate reports the first problem that would make the config unusable.
+func (c *Config) Validate() error {
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		return fmt.Errorf("server.port out of range: %d", c.Server.Port)
+	}
+
+	if c.Server.RateLimit < 0 || c.Server.RateLimitBurst < 0 {
+		return errors.New("server.rate_limit and server.rate_limit_burst must not be negative")
+	}
+
+	if c.Batch.MaxQueries < 1 || c.Batch.Concurrency < 1 {
+		return errors.New("batch.max_queries and batch.concurrency must be at least 1")
+	}
+
+	if c.Batch.ProviderBudget < 0 {
+		return errors.New("batch.provider_budget must not be negative")
+	}
+
+	if c.Admin.Enabled && len(c.Admin.APIKeys) == 0 {
+		return errors.New("admin.api_keys is required when admin is enabled")
+	}
+
+	if c.Warming.Enabled {
+		if err := c.Warming.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Store.Enabled {
+
+		if c.Store.Path == "" {
+			return errors.New("store.path is required when the store is enabled")
+		}
+
+		if c.Store.Retention < 0 || c.Store.CompactionInterval <= 0 {
+			return errors.New("store.retention must not be negative and store.compaction_interval must be positive")
+		}
+	}
+
+	if c.Overrides.Enabled && c.Overrides.RefreshInterval <= 0 {
+		return errors.New("overrides.refresh_interval must be positive")
+	}
+
+	if c.Identity.Enabled && c.Identity.CacheSize < 0 {
+		return errors.New("identity.cache_size must not be negative")
+	}
+
+	if c.Capture.Enabled {
+
+		if c.Capture.Path == "" {
+			return errors.New("capture.path is required when capture is enabled")
+		}
+
+		if c.Capture.SampleRate <= 0 || c.Capture.SampleRate > 1 {
+			return errors.New("capture.sample_rate must be in (0, 1]")
+		}
+
+		if c.Capture.MaxBytes < 0 {
+			return errors.New("capture.max_bytes must not be negative")
+		}
+	}
+
+	switch c.OpenAPI.Validation {
+
+	case "off", "log", "strict":
+
+	default:
+		return fmt.Errorf("openapi.validation must be off, log or strict, got %q", c.OpenAPI.Validation)
+	}
+
+	if err := c.Log.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Health.validate(); err != nil {
+		return err
+	}
+
+	if c.Costs.Enabled {
+		if err := c.Costs.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Router.validate(); err != nil {
+		return err
+	}
+
+	if c.GRPC.Enabled {
+
+		if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+			return fmt.Errorf("grpc.port out of range: %d", c.GRPC.Port)
+		}
+
+		if c.GRPC.Port == c.Server.Port {
+			return errors.New("grpc.port must differ from server.port")
+		}
+	}
+
+	if err := c.Redis.validate(); err != nil {
+		return err
+	}
+
+	if c.Cache.TTL <= 0 {
+		return errors.New("cache.ttl must be positive")
+	}
+
+	if c.Cache.DegradedTTL <= 0 {
+		return errors.New("cache.degraded_ttl must be positive")
+	}
+
+	if c.Cache.EmptyTTL <= 0 {
+		return errors.New("cache.empty_ttl must be positive")
+	}
+
+	if c.Cache.NegativeTTL < 0 || c.Cache.NegativeMaxTTL < c.Cache.NegativeTTL {
+		return errors.New("cache.negative_ttl must not be negative or exceed cache.negative_max_ttl")
+	}
+
+	if c.Cache.Codec != "json" && c.Cache.Codec != "protobuf" {
+		return fmt.Errorf("cache.codec must be json or protobuf, got %q", c.Cache.Codec)
+	}
+
+	if c.Cache.Compression != "none" && c.Cache.Compression != "zstd" && c.Cache.Compression != "snappy" {
+		return fmt.Errorf("cache.compression must be none, zstd or snappy, got %q", c.Cache.Compression)
+	}
+
+	if c.Cache.CompressThreshold < 0 {
+		return errors.New("cache.compress_threshold must not be negative")
+	}
+
+	if c.GraphQL.Enabled && c.GraphQL.MaxComplexity <= 0 {
+		return errors.New("graphql.max_complexity must be positive")
+	}
+
+	p := c.Providers
+
+	if !p.OSM.Enabled && !p.Google.Enabled && !p.Foursquare.Enabled {
+		return errors.New("no provider enabled")
+	}
+
+	switch p.Fixtures.Mode {
+
+	case "off", "record", "replay":
+
+	default:
+		return fmt.Errorf("providers.fixtures.mode must be off, record or replay, got %q", p.Fixtures.Mode)
+	}
+
+	if p.Fixtures.Mode != "off" && p.Fixtures.Dir == "" {
+		return errors.New("providers.fixtures.dir is required when recording or replaying")
+	}
+
+	// replayed fixtures hold no credentials, so none are needed
+	replay := p.Fixtures.Mode == "replay"
+
+	if p.Google.Enabled && p.Google.ApiKey == "" && !replay {
+		return errors.New("providers.google.api_key is required when enabled")
+	}
+
+	if p.Foursquare.Enabled && p.Foursquare.ApiKey == "" && !replay {
+		return errors.New("providers.foursquare.api_key is required when enabled")
+	}
+
+	checks := []struct {
+		name      string
+		enabled   bool
+		tier      int
+		timeout   time.Duration
+		retries   int
+		rateLimit float64
+		cb        CircuitBreakerConfig
+		hedge     HedgeConfig
+	}{
+		{"osm", p.OSM.Enabled, p.OSM.Tier, p.OSM.Timeout, p.OSM.Retries, p.OSM.RateLimit, p.OSM.CircuitBreaker, p.OSM.Hedge},
+		{"google", p.Google.Enabled, p.Google.Tier, p.Google.Timeout, p.Google.Retries, p.Google.RateLimit, p.Google.CircuitBreaker, p.Google.Hedge},
+		{"foursquare", p.Foursquare.Enabled, p.Foursquare.Tier, p.Foursquare.Timeout, p.Foursquare.Retries, p.Foursquare.RateLimit, p.Foursquare.CircuitBreaker, p.Foursquare.Hedge},
+	}
+
+	for _, check := range checks {
+
+		if !check.enabled {
+			continue
+		}
+
+		if check.tier < 0 {
+			return fmt.Errorf("providers.%s.tier must not be negative", check.name)
+		}
+
+		if check.timeout <= 0 {
+			return fmt.Errorf("providers.%s.timeout must be positive", check.name)
+		}
+
+		if check.retries < 0 {
+			return fmt.Errorf("providers.%s.retries must not be negative", check.name)
+		}
+
+		if check.rateLimit < 0 {
+			return fmt.Errorf("providers.%s.rate_limit must not be negative", check.name)
+		}
+
+		if err := check.cb.validate("providers." + check.name + ".cb"); err != nil {
+			return err
+		}
+
+		if err := check.hedge.validate("providers." + check.name + ".hedge"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c RouterConfig) validate() error {
+
+	if c.Timeout <= 0 {
+		return errors.New("router.timeout must be positive")
+	}
+
+	switch c.Strategy {
+
+	case "parallel":
+		return nil
+
+	case "tiered":
+
+	default:
+		return fmt.Errorf("router.strategy must be parallel or tiered, got %q", c.Strategy)
+	}
+
+	t := c.Tiered
+
+	if t.MinResults < 0 {
+		return errors.New("router.tiered.min_results must not be negative")
+	}
+
+	if t.MinCompleteness < 0 || t.MinCompleteness > 1 {
+		return errors.New("router.tiered.min_completeness must be in [0, 1]")
+	}
+
+	for _, field := range t.CompletenessFields {
+		if !slices.Contains(TierFields, field) {
+			return fmt.Errorf("router.tiered.completeness_fields: unknown field %q", field)
+		}
+	}
+
+	if t.EscalationTime <= 0 || t.EscalationTime >= c.Timeout {
+		return errors.New("router.tiered.escalation_time must be positive and shorter than router.timeout")
+	}
+
+	return nil
+}
+
+func (c CostsConfig) validate() error {
+
+	if c.FlushInterval <= 0 || c.QuotaCooldown <= 0 {
+		return errors.New("costs.flush_interval and costs.quota_cooldown must be positive")
+	}
+
+	for name, p := range map[string]ProviderCostConfig{"google": c.Google, "foursquare": c.Foursquare} {
+
+		for sku, price := range p.Prices {
+			if price < 0 {
+				return fmt.Errorf("costs.%s.prices.%s must not be negative", name, sku)
+			}
+		}
+
+		if p.DailyBudget < 0 || p.MonthlyBudget < 0 || p.DailyCalls < 0 || p.MonthlyCalls < 0 {
+			return fmt.Errorf("costs.%s budgets and call limits must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+func (c HealthConfig) validate() error {
+
+	if c.Window < 1 {
+		return errors.New("health.window must be at least 1")
+	}
+
+	if c.MinSuccessRate < 0 || c.MinSuccessRate > 1 {
+		return errors.New("health.min_success_rate must be in [0, 1]")
+	}
+
+	switch c.Readiness {
+
+	case "none", "any", "all":
+
+	default:
+		return fmt.Errorf("health.readiness must be none, any or all, got %q", c.Readiness)
+	}
+
+	// an unready replica gets no searches, so only canaries can bring
+	// its providers back to health
+	if c.Readiness != "none" && !c.Canary.Enabled {
+		return fmt.Errorf("health.readiness %s requires health.canary.enabled", c.Readiness)
+	}
+
+	if !c.Canary.Enabled {
+		return nil
+	}
+
+	if c.Canary.Interval <= 0 || c.Canary.Radius <= 0 {
+		return errors.New("health.canary.interval and health.canary.radius must be positive")
+	}
+
+	if c.Canary.Latitude < -90 || c.Canary.Latitude > 90 || c.Canary.Longitude < -180 || c.Canary.Longitude > 180 {
+		return errors.New("health.canary.lat and health.canary.lng must be valid coordinates")
+	}
+
+	return nil
+}
+
+func (c WarmingConfig) validate() error {
+
+	if c.Interval <= 0 {
+		return errors.New("warming.interval must be positive")
+	}
+
+	if c.TopN < 0 || c.ProviderBudget < 1 || c.Limit < 1 {
+		return errors.New("warming.top_n must not be negative, warming.provider_budget and warming.limit must be at least 1")
+	}
+
+	if c.QuietHours != "" {
+		if _, _, err := ParseQuietHours(c.QuietHours); err != nil {
+			return err
+		}
+	}
+
+	for i, r := range c.Regions {
+
+		if len(r.BBox) != 0 && len(r.BBox) != 4 {
+			return fmt.Errorf("warming.regions[%d].bbox needs min_lat, min_lng, max_lat, max_lng", i)
+		}
+
+		if len(r.BBox) == 0 && r.Radius <= 0 {
+			return fmt.Errorf("warming.regions[%d] needs a bbox or a positive radius", i)
+		}
+	}
+
+	return nil
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM" into offsets from midnight. The
+// window may wrap past midnight.
+func ParseQuietHours(s string) (start time.Duration, end time.Duration, err error) {
+
+	from, to, ok := strings.Cut(s, "-")
+
+	if !ok {
+		return 0, 0, fmt.Errorf("warming.quiet_hours must look like 22:00-06:00, got %q", s)
+	}
+
+	bounds := make([]time.Duration, 2)
+
+	for i, part := range []string{from, to} {
+
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("warming.quiet_hours must look like 22:00-06:00, got %q", s)
+		}
+
+		bounds[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	return bounds[0], bounds[1], nil
+}
+
+func (c RedisConfig) validate() error {
+
+	switch c.Mode {
+
+	case "standalone":
+
+		if c.Addr == "" && len(c.Addrs) == 0 {
+			return errors.New("redis.addr is required")
+		}
+
+		if len(c.Addrs) > 1 {
+			return errors.New("redis.addrs takes a single address in standalone mode")
+		}
+
+	case "sentinel":
+
+		if c.MasterName == "" || len(c.Addrs) == 0 {
+			return errors.New("redis.master_name and redis.addrs are required in sentinel mode")
+		}
+
+	case "cluster":
+
+		if len(c.Addrs) == 0 {
+			return errors.New("redis.addrs is required in cluster mode")
+		}
+
+		if c.DB != 0 {
+			return errors.New("redis.db must be 0 in cluster mode")
+		}
+
+	default:
+		return fmt.Errorf("redis.mode must be standalone, sentinel or cluster, got %q", c.Mode)
+	}
+
+	if c.DialTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 {
+		return errors.New("redis timeouts must not be negative")
+	}
+
+	if c.OpTimeout <= 0 {
+		return errors.New("redis.op_timeout must be positive")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("redis.tls.cert_file and redis.tls.key_file must be set together")
+	}
+
+	return c.CircuitBreaker.validate("redis.cb")
+}
+
+func (c CircuitBreakerConfig) validate(prefix string) error {
+
+	if c.Window <= 0 {
+		return fmt.Errorf("%s.window must be positive", prefix)
+	}
+
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		return fmt.Errorf("%s.failure_rate must be in (0, 1]", prefix)
+	}
+
+	if c.SlowCallRate <= 0 || c.SlowCallRate > 1 {
+		return fmt.Errorf("%s.slow_call_rate must be in (0, 1]", prefix)
+	}
+
+	if c.ResetTimeout <= 0 {
+		return fmt.Errorf("%s.reset_timeout must be positive", prefix)
+	}
+
+	if c.MinRequests < 1 || c.HalfOpenProbes < 1 {
+		return fmt.Errorf("%s.min_requests and half_open_probes must be at least 1", prefix)
+	}
+
+	if c.SlowCallDuration < 0 {
+		return fmt.Errorf("%s.slow_call_duration must not be negative", prefix)
+	}
+
+	return nil
+}
+
+func (c HedgeConfig) validate(prefix string) error {
+
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Percentile <= 0 || c.Percentile >= 1 {
+		return fmt.Errorf("%s.percentile must be in (0, 1)", prefix)
+	}
+
+	if c.Budget <= 0 || c.Budget > 1 {
+		return fmt.Errorf("%s.budget must be in (0, 1]", prefix)
+	}
+
+	if c.MinDelay < 0 {
+		return fmt.Errorf("%s.min_delay must not be negative", prefix)
+	}
+
+	return nil
+}
+
+func (c LogConfig) validate() error {
+
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Level)
+	}
+
+	if c.Format != "json" && c.Format != "text" {
+		return fmt.Errorf("log.format must be json or text, got %q", c.Format)
+	}
+
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return errors.New("log.sample_rate must be in [0, 1]")
+	}
+
+	if c.SlowRequest < 0 {
+		return errors.New("log.slow_request must not be negative")
+	}
 
-	return cfg
+	return nil
 }
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func validConfig() *Config {
+	cb := CircuitBreakerConfig{
+		Window:         time.Minute,
+		FailureRate:    0.5,
+		MinRequests:    10,
+		ResetTimeout:   30 * time.Second,
+		HalfOpenProbes: 3,
+		SlowCallRate:   0.8,
+	}
+
+	return &Config{
+		Server: ServerConfig{Port: 8080},
+		Redis: RedisConfig{
+			Mode:           "standalone",
+			Addr:           "localhost:6379",
+			OpTimeout:      500 * time.Millisecond,
+			CircuitBreaker: cb,
+		},
+		Cache: CacheConfig{
+			TTL:               5 * time.Minute,
+			DegradedTTL:       30 * time.Second,
+			EmptyTTL:          30 * time.Minute,
+			NegativeTTL:       5 * time.Second,
+			NegativeMaxTTL:    time.Minute,
+			Codec:             "protobuf",
+			Compression:       "zstd",
+			CompressThreshold: 1024,
+		},
+		GRPC:    GRPCConfig{Enabled: true, Port: 9090},
+		Batch:   BatchConfig{MaxQueries: 100, Concurrency: 8, ProviderBudget: 200},
+		OpenAPI: OpenAPIConfig{Validation: "off"},
+		Log:     LogConfig{Level: "info", Format: "json", SampleRate: 1, SlowRequest: time.Second},
+		Health:  HealthConfig{Window: 20, MinSuccessRate: 0.5, Readiness: "none"},
+		Router:  RouterConfig{Strategy: "parallel", Timeout: 3 * time.Second},
+		Providers: ProvidersConfig{
+			OSM: ProviderConfig{
+				Enabled:  true,
+				Priority: 10,
+				Timeout:  2 * time.Second,
+				Retries:  1,
+
+				CircuitBreaker: cb,
+			},
+			Fixtures: FixturesConfig{Mode: "off", Dir: "testdata/fixtures"},
+		},
+	}
+}
+
+func TestValidate_Valid(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidate_ReplayNeedsNoAPIKeys(t *testing.T) {
+	cfg := validConfig()
+	cfg.Providers.Fixtures.Mode = "replay"
+	cfg.Providers.Google = GoogleProviderConfig{Enabled: true, Timeout: time.Second, CircuitBreaker: cfg.Providers.OSM.CircuitBreaker}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   string
+	}{
+		{"bad port", func(c *Config) { c.Server.Port = 0 }, "server.port"},
+		{"grpc port clash", func(c *Config) { c.GRPC.Port = 8080 }, "grpc.port"},
+		{"negative server rate limit", func(c *Config) { c.Server.RateLimit = -1 }, "server.rate_limit"},
+		{"zero batch concurrency", func(c *Config) { c.Batch.Concurrency = 0 }, "batch.concurrency"},
+		{"admin without keys", func(c *Config) { c.Admin.Enabled = true }, "admin.api_keys"},
+		{"unknown redis mode", func(c *Config) { c.Redis.Mode = "replica" }, "redis.mode"},
+		{"sentinel without master", func(c *Config) { c.Redis.Mode = "sentinel"; c.Redis.Addrs = []string{"s1:26379"} }, "redis.master_name"},
+		{"cluster without addrs", func(c *Config) { c.Redis.Mode = "cluster" }, "redis.addrs"},
+		{"cluster with db", func(c *Config) { c.Redis.Mode = "cluster"; c.Redis.Addrs = []string{"n1:6379"}; c.Redis.DB = 1 }, "redis.db"},
+		{"zero redis op timeout", func(c *Config) { c.Redis.OpTimeout = 0 }, "redis.op_timeout"},
+		{"redis cert without key", func(c *Config) { c.Redis.TLS.CertFile = "client.pem" }, "redis.tls"},
+		{"negative ttl above max", func(c *Config) { c.Cache.NegativeTTL = 2 * time.Minute }, "cache.negative_ttl"},
+		{"unknown codec", func(c *Config) { c.Cache.Codec = "msgpack" }, "cache.codec"},
+		{"unknown compression", func(c *Config) { c.Cache.Compression = "lz4" }, "cache.compression"},
+		{"warming without interval", func(c *Config) { c.Warming = WarmingConfig{Enabled: true, ProviderBudget: 1, Limit: 50} }, "warming.interval"},
+		{"bad quiet hours", func(c *Config) {
+			c.Warming = WarmingConfig{Enabled: true, Interval: time.Minute, ProviderBudget: 1, Limit: 50, QuietHours: "late"}
+		}, "warming.quiet_hours"},
+		{"warm region without area", func(c *Config) {
+			c.Warming = WarmingConfig{Enabled: true, Interval: time.Minute, ProviderBudget: 1, Limit: 50, Regions: []WarmRegion{{Latitude: 59.3}}}
+		}, "warming.regions[0]"},
+		{"store without path", func(c *Config) { c.Store = StoreConfig{Enabled: true, CompactionInterval: time.Hour} }, "store.path"},
+		{"store without compaction interval", func(c *Config) { c.Store = StoreConfig{Enabled: true, Path: "poi.db"} }, "store.compaction_interval"},
+		{"overrides without refresh interval", func(c *Config) { c.Overrides = OverridesConfig{Enabled: true} }, "overrides.refresh_interval"},
+		{"capture sample rate above one", func(c *Config) { c.Capture = CaptureConfig{Enabled: true, Path: "x.jsonl", SampleRate: 2} }, "capture.sample_rate"},
+		{"capture without path", func(c *Config) { c.Capture = CaptureConfig{Enabled: true, SampleRate: 0.1} }, "capture.path"},
+		{"unknown openapi validation", func(c *Config) { c.OpenAPI.Validation = "warn" }, "openapi.validation"},
+		{"unknown log level", func(c *Config) { c.Log.Level = "verbose" }, "log.level"},
+		{"unknown log format", func(c *Config) { c.Log.Format = "logfmt" }, "log.format"},
+		{"log sample rate above one", func(c *Config) { c.Log.SampleRate = 1.5 }, "log.sample_rate"},
+		{"empty health window", func(c *Config) { c.Health.Window = 0 }, "health.window"},
+		{"unknown readiness policy", func(c *Config) { c.Health.Readiness = "most" }, "health.readiness"},
+		{"readiness without canaries", func(c *Config) { c.Health.Readiness = "any" }, "health.canary.enabled"},
+		{"canary without interval", func(c *Config) { c.Health.Canary = CanaryConfig{Enabled: true, Radius: 500} }, "health.canary.interval"},
+		{"negative price", func(c *Config) {
+			c.Costs = CostsConfig{Enabled: true, FlushInterval: time.Second, QuotaCooldown: time.Minute, Google: ProviderCostConfig{Prices: map[string]float64{"nearby_search": -1}}}
+		}, "costs.google.prices.nearby_search"},
+		{"negative budget", func(c *Config) {
+			c.Costs = CostsConfig{Enabled: true, FlushInterval: time.Second, QuotaCooldown: time.Minute, Foursquare: ProviderCostConfig{MonthlyBudget: -5}}
+		}, "costs.foursquare"},
+		{"costs without flush interval", func(c *Config) { c.Costs = CostsConfig{Enabled: true, QuotaCooldown: time.Minute} }, "costs.flush_interval"},
+		{"unknown router strategy", func(c *Config) { c.Router.Strategy = "weighted" }, "router.strategy"},
+		{"zero router timeout", func(c *Config) { c.Router.Timeout = 0 }, "router.timeout"},
+		{"unknown completeness field", func(c *Config) {
+			c.Router.Strategy = "tiered"
+			c.Router.Tiered = TieredConfig{CompletenessFields: []string{"stars"}, EscalationTime: time.Second}
+		}, "router.tiered.completeness_fields"},
+		{"escalation time beyond timeout", func(c *Config) {
+			c.Router.Strategy = "tiered"
+			c.Router.Tiered = TieredConfig{EscalationTime: 3 * time.Second}
+		}, "router.tiered.escalation_time"},
+		{"negative identity cache", func(c *Config) { c.Identity = IdentityConfig{Enabled: true, CacheSize: -1} }, "identity.cache_size"},
+		{"zero ttl", func(c *Config) { c.Cache.TTL = 0 }, "cache.ttl"},
+		{"no providers", func(c *Config) { c.Providers.OSM.Enabled = false }, "no provider"},
+		{"google without key", func(c *Config) { c.Providers.Google.Enabled = true; c.Providers.Google.Timeout = time.Second }, "google.api_key"},
+		{"zero timeout", func(c *Config) { c.Providers.OSM.Timeout = 0 }, "osm.timeout"},
+		{"negative tier", func(c *Config) { c.Providers.OSM.Tier = -1 }, "osm.tier"},
+		{"unknown fixtures mode", func(c *Config) { c.Providers.Fixtures.Mode = "rewind" }, "fixtures.mode"},
+		{"fixtures without dir", func(c *Config) { c.Providers.Fixtures.Mode = "record"; c.Providers.Fixtures.Dir = "" }, "fixtures.dir"},
+		{"negative rate limit", func(c *Config) { c.Providers.OSM.RateLimit = -1 }, "osm.rate_limit"},
+		{"failure rate above one", func(c *Config) { c.Providers.OSM.CircuitBreaker.FailureRate = 1.5 }, "osm.cb.failure_rate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	start, end, err := ParseQuietHours("22:30-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if start != 22*time.Hour+30*time.Minute || end != 6*time.Hour {
+		t.Errorf("Expected 22h30m and 6h, got %s and %s", start, end)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList([]string{"a, b", "", "c"})
+
+	if strings.Join(got, "|") != "a|b|c" {
+		t.Errorf("Expected [a b c], got %v", got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	os.WriteFile(path, []byte("cache:\n  tll: 10m\n  ttl: 2m\nwarming:\n  regions:\n    - latitude: 1\n"), 0o644)
+
+	cfg, unknown, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Cache.TTL != 2*time.Minute {
+		t.Errorf("Expected cache.ttl 2m from the file, got %s", cfg.Cache.TTL)
+	}
+
+	if strings.Join(unknown, ",") != "cache.tll" {
+		t.Errorf("Expected cache.tll to be unknown, got %v", unknown)
+	}
+
+	if _, _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file, got nil")
+	}
+}
+
+func TestReload_LeavesGlobalViperAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	os.WriteFile(path, []byte("cache:\n  ttl: 2m\n"), 0o644)
+
+	if _, _, err := LoadFile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	os.WriteFile(path, []byte("cache:\n  ttl: 3m\n"), 0o644)
+
+	cfg, err := Reload()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Cache.TTL != 3*time.Minute {
+		t.Errorf("Expected cache.ttl 3m from the changed file, got %s", cfg.Cache.TTL)
+	}
+
+	// the file watcher owns the global instance
+	if got := viper.GetDuration("cache.ttl"); got != 2*time.Minute {
+		t.Errorf("Expected the global viper untouched at 2m, got %s", got)
+	}
+}
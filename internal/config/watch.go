@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Watch invokes reload whenever the config file changes on disk or the
+// process receives SIGHUP. Invocations never overlap.
+func Watch(reload func()) {
+
+	var mu sync.Mutex
+
+	trigger := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reload()
+	}
+
+	if viper.ConfigFileUsed() != "" {
+
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			trigger()
+		})
+
+		viper.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		for range hup {
+			trigger()
+		}
+	}()
+}
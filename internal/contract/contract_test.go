@@ -0,0 +1,273 @@
+package contract
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/api/openapi"
+)
+
+func loadDocument(t *testing.T) *Spec {
+
+	spec, err := Load(openapi.Document)
+
+	if err != nil {
+		t.Fatalf("Expected the OpenAPI document to load, got %v", err)
+	}
+
+	return spec
+}
+
+func TestLoad_RejectsUnresolvedRef(t *testing.T) {
+
+	doc := `{"openapi": "3.0.3", "paths": {"/x": {"get": {"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}}`
+
+	if _, err := Load([]byte(doc)); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Expected an unresolved $ref error, got %v", err)
+	}
+}
+
+func TestLoad_RejectsVersion2(t *testing.T) {
+
+	if _, err := Load([]byte(`{"swagger": "2.0", "paths": {}}`)); err == nil {
+		t.Error("Expected Swagger 2 to be rejected, got nil")
+	}
+}
+
+func searchHeaders() http.Header {
+
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Hynek-Result", "complete")
+	h.Set("X-Hynek-Cache", "miss")
+
+	return h
+}
+
+const validPage = `{"data":[{"id":"node/1","name":"Café","latitude":50.08,"longitude":14.42,"category":"cafe","source":"osm","open_now":true}],"total":1,"page":1,"page_size":20,"total_pages":1,"complete":true,"cached":false,"providers":[{"provider":"osm","status":"ok","latency_ms":12,"result_count":1}]}`
+
+func TestValidateResponse(t *testing.T) {
+
+	spec := loadDocument(t)
+
+	tests := []struct {
+		name    string
+		status  int
+		header  func(http.Header)
+		body    string
+		strict  bool
+		wantErr string
+	}{
+		{"valid page", 200, nil, validPage, true, ""},
+		{"missing field", 200, nil, strings.Replace(validPage, `"total":1,`, "", 1), false, "body.total: required"},
+		{"wrong type", 200, nil, strings.Replace(validPage, `"page":1`, `"page":"1"`, 1), false, "body.page: must be an integer"},
+		{"fractional integer", 200, nil, strings.Replace(validPage, `"latency_ms":12`, `"latency_ms":1.5`, 1), false, "body.providers[0].latency_ms: must be an integer"},
+		{"out of range", 200, nil, strings.Replace(validPage, `"latitude":50.08`, `"latitude":91`, 1), false, "body.data[0].latitude: must be at most 90"},
+		{"unknown enum value", 200, nil, strings.Replace(validPage, `"status":"ok"`, `"status":"slow"`, 1), false, "body.providers[0].status: must be one of"},
+		{"undocumented field allowed", 200, nil, strings.Replace(validPage, `"cached":false`, `"cached":false,"debug":1`, 1), false, ""},
+		{"undocumented field strict", 200, nil, strings.Replace(validPage, `"cached":false`, `"cached":false,"debug":1`, 1), true, "body.debug: not documented"},
+		{"missing header", 200, func(h http.Header) { h.Del("X-Hynek-Cache") }, validPage, false, "header X-Hynek-Cache: required"},
+		{"header enum", 200, func(h http.Header) { h.Set("X-Hynek-Result", "partial") }, validPage, false, "header X-Hynek-Result: must be one of"},
+		{"undocumented status", 404, func(h http.Header) { h.Set("Content-Type", "text/plain; charset=utf-8") }, "not found", false, "status 404 not documented"},
+		{"error as text", 503, func(h http.Header) { h.Set("Content-Type", "text/plain; charset=utf-8") }, "all providers failed", true, ""},
+		{"undocumented content type", 503, nil, `{"error":"down"}`, false, "content type application/json not documented"},
+		{"invalid JSON", 200, nil, `{"data":`, false, "body: invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			h := searchHeaders()
+
+			if tt.header != nil {
+				tt.header(h)
+			}
+
+			err := spec.ValidateResponse("GET", "/v1/search", tt.status, h, []byte(tt.body), tt.strict)
+
+			if tt.wantErr == "" {
+
+				if err != nil {
+					t.Errorf("Expected no violation, got %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected violation containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateResponse_OneOf(t *testing.T) {
+
+	spec := loadDocument(t)
+
+	h := http.Header{"Content-Type": {"application/json"}}
+
+	body := `{"results":[` + validPage + `,{"error":"all providers failed"}],"provider_calls":3}`
+
+	if err := spec.ValidateResponse("POST", "/v1/search/batch", 200, h, []byte(body), true); err != nil {
+		t.Errorf("Expected no violation, got %v", err)
+	}
+
+	body = `{"results":[{"page":1}],"provider_calls":0}`
+
+	if err := spec.ValidateResponse("POST", "/v1/search/batch", 200, h, []byte(body), true); err == nil || !strings.Contains(err.Error(), "oneOf") {
+		t.Errorf("Expected a oneOf violation, got %v", err)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+
+	spec := loadDocument(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		strict  bool
+		wantErr string
+	}{
+		{"valid search", "GET", "/v1/search?lat=50.08&lng=14.42&categories=cafe&page=2", "", true, ""},
+		{"not a number", "GET", "/v1/search?lat=north", "", false, "query lat: must be a number"},
+		{"page size above maximum", "GET", "/v1/search?lat=1&lng=1&page_size=500", "", false, "query page_size: must be at most 100"},
+		{"bad bbox", "GET", "/v1/search?bbox=1,2,3", "", false, "query bbox: must match"},
+		{"undocumented parameter", "GET", "/v1/search?lat=1&lng=1&radius=50", "", true, "query radius: not documented"},
+		{"undocumented parameter allowed", "GET", "/v1/search?lat=1&lng=1&radius=50", "", false, ""},
+		{"missing required", "GET", "/v1/ids?source=osm", "", false, "query id: required"},
+		{"valid batch", "POST", "/v1/search/batch", `{"queries":[{"lat":50.08,"lng":14.42,"categories":["cafe"]}]}`, true, ""},
+		{"empty batch", "POST", "/v1/search/batch", `{"queries":[]}`, false, "body.queries: must have at least 1 items"},
+		{"missing body", "POST", "/v1/search/batch", "", false, "body: required"},
+		{"undocumented method", "DELETE", "/v1/search", "", false, "method DELETE not documented"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+			err := spec.ValidateRequest(r, tt.strict)
+
+			if tt.wantErr == "" {
+
+				if err != nil {
+					t.Errorf("Expected no violation, got %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected violation containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_RestoresBody(t *testing.T) {
+
+	spec := loadDocument(t)
+
+	body := `{"queries":[{"lat":1,"lng":2}]}`
+
+	r := httptest.NewRequest("POST", "/v1/search/batch", strings.NewReader(body))
+
+	if err := spec.ValidateRequest(r, true); err != nil {
+		t.Fatalf("Unexpected violation: %v", err)
+	}
+
+	got, _ := io.ReadAll(r.Body)
+
+	if string(got) != body {
+		t.Errorf("Expected the handler to read %s, got %s", body, got)
+	}
+}
+
+func serve(mode Mode, handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+
+	spec, _ := Load(openapi.Document)
+
+	rec := httptest.NewRecorder()
+
+	spec.Middleware(mode, handler).ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+
+	called := false
+
+	wrongShape := func(w http.ResponseWriter, r *http.Request) {
+
+		called = true
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Hynek-Result", "complete")
+		w.Header().Set("X-Hynek-Cache", "miss")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}
+
+	t.Run("strict rejects invalid requests", func(t *testing.T) {
+
+		called = false
+
+		rec := serve(ModeStrict, wrongShape, "/v1/search?lat=100")
+
+		if rec.Code != http.StatusBadRequest || called {
+			t.Errorf("Expected 400 without calling the handler, got %d, called %v", rec.Code, called)
+		}
+	})
+
+	t.Run("strict replaces invalid responses", func(t *testing.T) {
+
+		rec := serve(ModeStrict, wrongShape, "/v1/search?lat=1&lng=1")
+
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "body.total: required") {
+			t.Errorf("Expected 500 naming the violation, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("log serves traffic unchanged", func(t *testing.T) {
+
+		rec := serve(ModeLog, wrongShape, "/v1/search?lat=100")
+
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"data":[]}` || rec.Header().Get("X-Hynek-Cache") != "miss" {
+			t.Errorf("Expected the handler's response, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("undocumented paths pass through", func(t *testing.T) {
+
+		rec := serve(ModeStrict, wrongShape, "/admin/overrides")
+
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"data":[]}` {
+			t.Errorf("Expected the handler's response, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("streams are not buffered", func(t *testing.T) {
+
+		rec := serve(ModeStrict, func(w http.ResponseWriter, r *http.Request) {
+
+			if _, ok := w.(http.Flusher); !ok {
+				http.Error(w, "streaming unsupported", 500)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("event: result\ndata: {}\n\n"))
+		}, "/v1/search/stream?lat=1&lng=1")
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected the stream to reach the client, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,443 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// Mode says what the middleware does with traffic that does not match
+// the document.
+type Mode string
+
+const (
+	// ModeOff passes traffic through unchecked.
+	ModeOff Mode = "off"
+
+	// ModeLog checks requests and responses, logging and counting
+	// violations but serving them unchanged.
+	ModeLog Mode = "log"
+
+	// ModeStrict rejects invalid requests with 400 and replaces invalid
+	// responses with 500. Undocumented query parameters and object
+	// properties are violations too.
+	ModeStrict Mode = "strict"
+)
+
+// maxBody bounds the request body read for validation; larger bodies
+// are left to the handler to reject.
+const maxBody = 1 << 20
+
+// Middleware validates traffic to the paths the document describes
+// against it. Other paths, such as admin endpoints, pass through.
+// Responses documented as text/event-stream are not buffered, so only
+// their requests are checked.
+func (s *Spec) Middleware(mode Mode, next http.Handler) http.Handler {
+
+	if mode == ModeOff {
+		return next
+	}
+
+	strict := mode == ModeStrict
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		item, ok := s.doc.Paths[r.URL.Path]
+
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		op := item.operation(r.Method)
+
+		if op == nil {
+
+			report(r, "request", fmt.Errorf("method %s not documented", r.Method))
+
+			if strict {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := s.ValidateRequest(r, strict); err != nil {
+
+			report(r, "request", err)
+
+			if strict {
+				http.Error(w, "request violates API contract: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if s.streams(op) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{header: http.Header{}}
+
+		next.ServeHTTP(rec, r)
+
+		if err := s.ValidateResponse(r.Method, r.URL.Path, rec.status, rec.header, rec.body.Bytes(), strict); err != nil {
+
+			report(r, "response", err)
+
+			if strict {
+				http.Error(w, "response violates API contract: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		rec.copyTo(w)
+	})
+}
+
+func report(r *http.Request, kind string, err error) {
+
+	metrics.ContractViolations.WithLabelValues(kind).Inc()
+
+	slog.WarnContext(r.Context(), "contract violation", "method", r.Method, "path", r.URL.Path, "kind", kind, "error", err)
+}
+
+func (s *Spec) streams(op *operation) bool {
+
+	for _, r := range op.Responses {
+		if _, ok := s.mustResponse(r).Content["text/event-stream"]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Spec) mustResponse(r *response) *response {
+
+	resolved, err := s.response(r)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return resolved
+}
+
+// ValidateRequest checks the query parameters and JSON body of r against
+// its operation. The body is read and put back for the handler.
+func (s *Spec) ValidateRequest(r *http.Request, strict bool) error {
+
+	op, err := s.operation(r.Method, r.URL.Path)
+
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	query := r.URL.Query()
+
+	declared := map[string]bool{}
+
+	for _, p := range op.Parameters {
+
+		param, _ := s.parameter(p)
+
+		if param.In != "query" {
+			continue
+		}
+
+		declared[param.Name] = true
+
+		value, present := query[param.Name]
+
+		if !present {
+
+			if param.Required {
+				errs = append(errs, fmt.Errorf("query %s: required", param.Name))
+			}
+
+			continue
+		}
+
+		if param.Schema == nil {
+			continue
+		}
+
+		sc := s.mustSchema(param.Schema)
+
+		v, ok := parseParam(value[0], sc)
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("query %s: must be %s", param.Name, withArticle(sc.Type)))
+			continue
+		}
+
+		errs = append(errs, s.validate(v, sc, "query "+param.Name, strict)...)
+	}
+
+	if strict {
+		for name := range query {
+			if !declared[name] {
+				errs = append(errs, fmt.Errorf("query %s: not documented", name))
+			}
+		}
+	}
+
+	if op.RequestBody != nil {
+		errs = append(errs, s.validateRequestBody(op.RequestBody, r, strict)...)
+	}
+
+	return violation(errs)
+}
+
+func (s *Spec) operation(method, path string) (*operation, error) {
+
+	item, ok := s.doc.Paths[path]
+
+	if !ok {
+		return nil, fmt.Errorf("path %s not documented", path)
+	}
+
+	op := item.operation(method)
+
+	if op == nil {
+		return nil, fmt.Errorf("method %s not documented", method)
+	}
+
+	return op, nil
+}
+
+func (s *Spec) validateRequestBody(body *requestBody, r *http.Request, strict bool) []error {
+
+	var data []byte
+
+	if r.Body != nil {
+
+		var err error
+
+		data, err = io.ReadAll(io.LimitReader(r.Body, maxBody))
+
+		if err != nil {
+			return []error{fmt.Errorf("body: %w", err)}
+		}
+
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	}
+
+	if len(data) == 0 {
+
+		if body.Required {
+			return []error{errors.New("body: required")}
+		}
+
+		return nil
+	}
+
+	if len(data) == maxBody {
+		return nil
+	}
+
+	// clients commonly leave out the content type of JSON bodies
+	ct := mediaTypeOf(r.Header.Get("Content-Type"))
+
+	if ct == "" {
+		ct = "application/json"
+	}
+
+	media, ok := body.Content[ct]
+
+	if !ok {
+		return []error{fmt.Errorf("body: content type %s not documented", ct)}
+	}
+
+	return s.validateContent(media, ct, data, strict)
+}
+
+// ValidateResponse checks a response to method and path: its status must
+// be documented, with its required headers and content type, and JSON
+// bodies must match their schema.
+func (s *Spec) ValidateResponse(method, path string, status int, h http.Header, body []byte, strict bool) error {
+
+	op, err := s.operation(method, path)
+
+	if err != nil {
+		return err
+	}
+
+	r, ok := op.Responses[strconv.Itoa(status)]
+
+	if !ok {
+		r, ok = op.Responses[strconv.Itoa(status/100)+"XX"]
+	}
+
+	if !ok {
+		r, ok = op.Responses["default"]
+	}
+
+	if !ok {
+		return fmt.Errorf("status %d not documented", status)
+	}
+
+	resp := s.mustResponse(r)
+
+	var errs []error
+
+	for name, hd := range resp.Headers {
+
+		hdr, _ := s.header(hd)
+
+		value := h.Get(name)
+
+		if value == "" {
+
+			if hdr.Required {
+				errs = append(errs, fmt.Errorf("header %s: required", name))
+			}
+
+			continue
+		}
+
+		if hdr.Schema == nil {
+			continue
+		}
+
+		sc := s.mustSchema(hdr.Schema)
+
+		v, ok := parseParam(value, sc)
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("header %s: must be %s", name, withArticle(sc.Type)))
+			continue
+		}
+
+		errs = append(errs, s.validate(v, sc, "header "+name, strict)...)
+	}
+
+	if len(body) > 0 || h.Get("Content-Type") != "" {
+
+		ct := mediaTypeOf(h.Get("Content-Type"))
+
+		if ct == "" {
+			ct = mediaTypeOf(http.DetectContentType(body))
+		}
+
+		media, ok := resp.Content[ct]
+
+		if !ok {
+			errs = append(errs, fmt.Errorf("content type %s not documented for status %d", ct, status))
+		} else {
+			errs = append(errs, s.validateContent(media, ct, body, strict)...)
+		}
+	}
+
+	return violation(errs)
+}
+
+func (s *Spec) validateContent(media *mediaType, ct string, data []byte, strict bool) []error {
+
+	if media.Schema == nil || ct != "application/json" {
+		return nil
+	}
+
+	v, err := decodeJSON(data)
+
+	if err != nil {
+		return []error{fmt.Errorf("body: invalid JSON: %w", err)}
+	}
+
+	return s.validate(v, media.Schema, "body", strict)
+}
+
+func mediaTypeOf(contentType string) string {
+
+	if contentType == "" {
+		return ""
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return strings.TrimSpace(contentType)
+	}
+
+	return mt
+}
+
+// violation joins validation errors into one line, sorted so the same
+// problems always read the same.
+func violation(errs []error) error {
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(errs))
+
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	sort.Strings(msgs)
+
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// recorder buffers a response so it can be checked before it is sent.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.body.Write(p)
+}
+
+func (r *recorder) copyTo(w http.ResponseWriter) {
+
+	for name, values := range r.header {
+		w.Header()[name] = values
+	}
+
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	w.WriteHeader(r.status)
+
+	_, _ = w.Write(r.body.Bytes())
+}
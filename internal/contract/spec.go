@@ -0,0 +1,569 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Spec is an OpenAPI 3 document, reduced to the parts validation reads:
+// paths without templates, query parameters, JSON bodies, response
+// headers and content types. Schemas may use type, properties,
+// required, additionalProperties (true or false), items, oneOf, enum,
+// minimum, maximum, minItems, maxItems, pattern, nullable and $ref to
+// components.
+type Spec struct {
+	doc document
+}
+
+type document struct {
+	OpenAPI    string               `json:"openapi"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components components           `json:"components"`
+}
+
+type components struct {
+	Schemas    map[string]*schema    `json:"schemas"`
+	Parameters map[string]*parameter `json:"parameters"`
+	Headers    map[string]*header    `json:"headers"`
+	Responses  map[string]*response  `json:"responses"`
+}
+
+type pathItem struct {
+	Get    *operation `json:"get"`
+	Post   *operation `json:"post"`
+	Put    *operation `json:"put"`
+	Delete *operation `json:"delete"`
+}
+
+type operation struct {
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Ref     string                `json:"$ref"`
+	Headers map[string]*header    `json:"headers"`
+	Content map[string]*mediaType `json:"content"`
+}
+
+type header struct {
+	Ref      string  `json:"$ref"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	OneOf                []*schema          `json:"oneOf"`
+	Enum                 []any              `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Pattern              string             `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// Load parses an OpenAPI 3 document. Every $ref must resolve and every
+// pattern compile.
+func Load(data []byte) (*Spec, error) {
+
+	var doc document
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("version %q is not 3.x", doc.OpenAPI)
+	}
+
+	s := &Spec{doc: doc}
+
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Operations lists the documented operations as "METHOD /path", sorted.
+func (s *Spec) Operations() []string {
+
+	var ops []string
+
+	for path, item := range s.doc.Paths {
+		for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+			if item.operation(method) != nil {
+				ops = append(ops, method+" "+path)
+			}
+		}
+	}
+
+	sort.Strings(ops)
+
+	return ops
+}
+
+func (p *pathItem) operation(method string) *operation {
+
+	switch method {
+
+	case "GET":
+		return p.Get
+
+	case "POST":
+		return p.Post
+
+	case "PUT":
+		return p.Put
+
+	case "DELETE":
+		return p.Delete
+	}
+
+	return nil
+}
+
+func (p *pathItem) operations() []*operation {
+
+	var ops []*operation
+
+	for _, op := range []*operation{p.Get, p.Post, p.Put, p.Delete} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
+// check resolves every reference once and compiles patterns, so
+// validation can assume a well formed document.
+func (s *Spec) check() error {
+
+	for name, sc := range s.doc.Components.Schemas {
+		if err := s.checkSchema(sc, "#/components/schemas/"+name); err != nil {
+			return err
+		}
+	}
+
+	for path, item := range s.doc.Paths {
+
+		for _, op := range item.operations() {
+
+			for _, p := range op.Parameters {
+
+				param, err := s.parameter(p)
+
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+
+				if err := s.checkSchema(param.Schema, path+" "+param.Name); err != nil {
+					return err
+				}
+			}
+
+			if op.RequestBody != nil {
+				for ct, media := range op.RequestBody.Content {
+					if err := s.checkSchema(media.Schema, path+" request "+ct); err != nil {
+						return err
+					}
+				}
+			}
+
+			for status, r := range op.Responses {
+
+				resp, err := s.response(r)
+
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", path, status, err)
+				}
+
+				for name, h := range resp.Headers {
+
+					hdr, err := s.header(h)
+
+					if err != nil {
+						return fmt.Errorf("%s %s: %w", path, status, err)
+					}
+
+					if err := s.checkSchema(hdr.Schema, path+" "+status+" "+name); err != nil {
+						return err
+					}
+				}
+
+				for ct, media := range resp.Content {
+					if err := s.checkSchema(media.Schema, path+" "+status+" "+ct); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) checkSchema(sc *schema, at string) error {
+
+	if sc == nil {
+		return nil
+	}
+
+	if sc.Ref != "" {
+
+		if _, err := s.schema(sc); err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+
+		// the target is checked as a component
+		return nil
+	}
+
+	if sc.Pattern != "" {
+
+		re, err := regexp.Compile(sc.Pattern)
+
+		if err != nil {
+			return fmt.Errorf("%s: pattern: %w", at, err)
+		}
+
+		sc.pattern = re
+	}
+
+	for name, prop := range sc.Properties {
+		if err := s.checkSchema(prop, at+"."+name); err != nil {
+			return err
+		}
+	}
+
+	for i, alt := range sc.OneOf {
+		if err := s.checkSchema(alt, fmt.Sprintf("%s.oneOf[%d]", at, i)); err != nil {
+			return err
+		}
+	}
+
+	return s.checkSchema(sc.Items, at+"[]")
+}
+
+func lookup[T any](ref string, prefix string, in map[string]*T) (*T, error) {
+
+	name, ok := strings.CutPrefix(ref, prefix)
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+
+	v, ok := in[name]
+
+	if !ok {
+		return nil, fmt.Errorf("unresolved $ref %q", ref)
+	}
+
+	return v, nil
+}
+
+func (s *Spec) schema(sc *schema) (*schema, error) {
+
+	// components may alias each other, but not in a loop
+	for range 8 {
+
+		if sc.Ref == "" {
+			return sc, nil
+		}
+
+		var err error
+
+		if sc, err = lookup(sc.Ref, "#/components/schemas/", s.doc.Components.Schemas); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("$ref chain too long at %q", sc.Ref)
+}
+
+func (s *Spec) parameter(p *parameter) (*parameter, error) {
+
+	if p.Ref == "" {
+		return p, nil
+	}
+
+	return lookup(p.Ref, "#/components/parameters/", s.doc.Components.Parameters)
+}
+
+func (s *Spec) response(r *response) (*response, error) {
+
+	if r.Ref == "" {
+		return r, nil
+	}
+
+	return lookup(r.Ref, "#/components/responses/", s.doc.Components.Responses)
+}
+
+func (s *Spec) header(h *header) (*header, error) {
+
+	if h.Ref == "" {
+		return h, nil
+	}
+
+	return lookup(h.Ref, "#/components/headers/", s.doc.Components.Headers)
+}
+
+// mustSchema resolves a reference check has already seen resolve.
+func (s *Spec) mustSchema(sc *schema) *schema {
+
+	resolved, err := s.schema(sc)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return resolved
+}
+
+// validate checks v, decoded with json.Number for numbers, against sc.
+// In strict mode objects take only the properties they declare unless
+// additionalProperties is true; otherwise only an explicit false closes
+// them.
+func (s *Spec) validate(v any, sc *schema, at string, strict bool) []error {
+
+	if sc == nil {
+		return nil
+	}
+
+	sc = s.mustSchema(sc)
+
+	if v == nil {
+
+		if sc.Nullable || (sc.Type == "" && len(sc.OneOf) == 0) {
+			return nil
+		}
+
+		return []error{fmt.Errorf("%s: must not be null", at)}
+	}
+
+	if len(sc.OneOf) > 0 {
+
+		matched := 0
+
+		for _, alt := range sc.OneOf {
+			if len(s.validate(v, alt, at, strict)) == 0 {
+				matched++
+			}
+		}
+
+		if matched != 1 {
+			return []error{fmt.Errorf("%s: must match exactly one schema of oneOf, matched %d", at, matched)}
+		}
+
+		return nil
+	}
+
+	var errs []error
+
+	switch sc.Type {
+
+	case "object":
+
+		obj, ok := v.(map[string]any)
+
+		if !ok {
+			return []error{fmt.Errorf("%s: must be an object", at)}
+		}
+
+		for _, name := range sc.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s.%s: required", at, name))
+			}
+		}
+
+		closed := sc.AdditionalProperties != nil && !*sc.AdditionalProperties
+
+		if strict && sc.AdditionalProperties == nil {
+			closed = true
+		}
+
+		for name, value := range obj {
+
+			prop, declared := sc.Properties[name]
+
+			if !declared {
+
+				if closed {
+					errs = append(errs, fmt.Errorf("%s.%s: not documented", at, name))
+				}
+
+				continue
+			}
+
+			errs = append(errs, s.validate(value, prop, at+"."+name, strict)...)
+		}
+
+	case "array":
+
+		items, ok := v.([]any)
+
+		if !ok {
+			return []error{fmt.Errorf("%s: must be an array", at)}
+		}
+
+		if sc.MinItems != nil && len(items) < *sc.MinItems {
+			errs = append(errs, fmt.Errorf("%s: must have at least %d items", at, *sc.MinItems))
+		}
+
+		if sc.MaxItems != nil && len(items) > *sc.MaxItems {
+			errs = append(errs, fmt.Errorf("%s: must have at most %d items", at, *sc.MaxItems))
+		}
+
+		for i, item := range items {
+			errs = append(errs, s.validate(item, sc.Items, fmt.Sprintf("%s[%d]", at, i), strict)...)
+		}
+
+	case "string":
+
+		str, ok := v.(string)
+
+		if !ok {
+			return []error{fmt.Errorf("%s: must be a string", at)}
+		}
+
+		if sc.pattern != nil && !sc.pattern.MatchString(str) {
+			errs = append(errs, fmt.Errorf("%s: must match %s", at, sc.Pattern))
+		}
+
+	case "integer", "number":
+
+		n, ok := v.(json.Number)
+
+		if !ok {
+			return []error{fmt.Errorf("%s: must be %s", at, withArticle(sc.Type))}
+		}
+
+		f, err := n.Float64()
+
+		if err != nil {
+			return []error{fmt.Errorf("%s: must be %s", at, withArticle(sc.Type))}
+		}
+
+		if sc.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				return []error{fmt.Errorf("%s: must be an integer", at)}
+			}
+		}
+
+		if sc.Minimum != nil && f < *sc.Minimum {
+			errs = append(errs, fmt.Errorf("%s: must be at least %v", at, *sc.Minimum))
+		}
+
+		if sc.Maximum != nil && f > *sc.Maximum {
+			errs = append(errs, fmt.Errorf("%s: must be at most %v", at, *sc.Maximum))
+		}
+
+	case "boolean":
+
+		if _, ok := v.(bool); !ok {
+			return []error{fmt.Errorf("%s: must be a boolean", at)}
+		}
+	}
+
+	if len(sc.Enum) > 0 && !inEnum(v, sc.Enum) {
+		errs = append(errs, fmt.Errorf("%s: must be one of %v", at, sc.Enum))
+	}
+
+	return errs
+}
+
+func withArticle(typ string) string {
+
+	if strings.IndexByte("aeiou", typ[0]) >= 0 {
+		return "an " + typ
+	}
+
+	return "a " + typ
+}
+
+func inEnum(v any, enum []any) bool {
+
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeJSON decodes a body keeping numbers as json.Number.
+func decodeJSON(data []byte) (any, error) {
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+
+	return v, nil
+}
+
+// parseParam converts a query or header value to what the JSON decoder
+// would produce for the schema's type.
+func parseParam(value string, sc *schema) (any, bool) {
+
+	switch sc.Type {
+
+	case "integer", "number":
+
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, false
+		}
+
+		return json.Number(value), true
+
+	case "boolean":
+
+		b, err := strconv.ParseBool(value)
+
+		return b, err == nil
+	}
+
+	return value, true
+}
@@ -0,0 +1,374 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// Reasons a provider is skipped, reported as its error class.
+const (
+	ReasonSpend = "spend_budget_exhausted"
+	ReasonQuota = "quota_exhausted"
+)
+
+// ProviderCost is a provider's entry on /admin/costs.
+type ProviderCost struct {
+	Provider string `json:"provider"`
+	Day      Period `json:"day"`
+	Month    Period `json:"month"`
+
+	// Exhausted is why the provider is being skipped, if it is.
+	Exhausted  string     `json:"exhausted,omitempty"`
+	QuotaUntil *time.Time `json:"quota_until,omitempty"`
+}
+
+// Period is a provider's usage in one UTC day or month, with its caps.
+type Period struct {
+	Period    string  `json:"period"`
+	Calls     int64   `json:"calls"`
+	Spend     float64 `json:"spend"`
+	Budget    float64 `json:"budget,omitempty"`
+	CallLimit int64   `json:"call_limit,omitempty"`
+}
+
+// account is what a Ledger knows of one provider.
+type account struct {
+	day   string
+	month string
+
+	// dayUsage and monthUsage are the fleet totals as of the last flush
+	// plus this replica's calls since.
+	dayUsage   Usage
+	monthUsage Usage
+
+	pending Usage
+
+	quotaUntil  time.Time
+	quotaMarked bool
+}
+
+// Ledger accounts the calls of paid providers and enforces their
+// budgets. Calls are priced and counted in memory right away, so caps
+// take effect on this replica at once, and added to the Store on Flush,
+// which also picks up the other replicas' calls. Between flushes the
+// fleet can overshoot a cap by what the other replicas spend in one
+// interval.
+type Ledger struct {
+	mu sync.Mutex
+
+	store    Store
+	cfg      config.CostsConfig
+	limits   map[string]config.ProviderCostConfig
+	accounts map[string]*account
+
+	now func() time.Time
+}
+
+func NewLedger(store Store, cfg config.CostsConfig) *Ledger {
+
+	l := &Ledger{
+		store: store,
+		cfg:   cfg,
+		limits: map[string]config.ProviderCostConfig{
+			"google":     cfg.Google,
+			"foursquare": cfg.Foursquare,
+		},
+		accounts: map[string]*account{},
+		now:      time.Now,
+	}
+
+	for name := range l.limits {
+		l.accounts[name] = &account{}
+	}
+
+	return l
+}
+
+// account returns provider's account moved to the current periods, or
+// nil for free providers. The caller holds mu.
+func (l *Ledger) account(provider string) *account {
+
+	a, ok := l.accounts[provider]
+
+	if !ok {
+		return nil
+	}
+
+	now := l.now().UTC()
+
+	if day := now.Format("2006-01-02"); a.day != day {
+		a.day = day
+		a.dayUsage = Usage{}
+	}
+
+	if month := now.Format("2006-01"); a.month != month {
+		a.month = month
+		a.monthUsage = Usage{}
+	}
+
+	return a
+}
+
+// Record implements provider.Meter: it bills one call of provider as
+// skus.
+func (l *Ledger) Record(provider string, skus []string) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a := l.account(provider)
+
+	if a == nil {
+		return
+	}
+
+	spend := 0.0
+
+	for _, sku := range skus {
+
+		spend += l.limits[provider].Prices[sku]
+
+		metrics.ProviderBilledCalls.WithLabelValues(provider, sku).Inc()
+	}
+
+	metrics.ProviderSpend.WithLabelValues(provider).Add(spend)
+
+	for _, u := range []*Usage{&a.pending, &a.dayUsage, &a.monthUsage} {
+		u.Calls++
+		u.Spend += spend
+	}
+}
+
+// QuotaExceeded implements provider.Meter: provider is skipped for the
+// quota cooldown, on every replica once flushed.
+func (l *Ledger) QuotaExceeded(provider string) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a := l.account(provider)
+
+	if a == nil {
+		return
+	}
+
+	a.quotaUntil = l.now().Add(l.cfg.QuotaCooldown)
+	a.quotaMarked = true
+}
+
+// Exhausted implements orchestrator.CostGuard.
+func (l *Ledger) Exhausted(provider string) string {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a := l.account(provider)
+
+	if a == nil {
+		return ""
+	}
+
+	reason := l.exhausted(provider, a)
+
+	if reason != "" {
+		metrics.ProviderBudgetSkips.WithLabelValues(provider, reason).Inc()
+	}
+
+	return reason
+}
+
+func (l *Ledger) exhausted(provider string, a *account) string {
+
+	limits := l.limits[provider]
+
+	switch {
+
+	case l.now().Before(a.quotaUntil):
+		return ReasonQuota
+
+	case limits.DailyCalls > 0 && a.dayUsage.Calls >= limits.DailyCalls,
+		limits.MonthlyCalls > 0 && a.monthUsage.Calls >= limits.MonthlyCalls:
+		return ReasonQuota
+
+	case limits.DailyBudget > 0 && a.dayUsage.Spend >= limits.DailyBudget,
+		limits.MonthlyBudget > 0 && a.monthUsage.Spend >= limits.MonthlyBudget:
+		return ReasonSpend
+	}
+
+	return ""
+}
+
+// Flush adds this replica's pending usage to the store and reads back
+// the fleet totals and quota marks. Usage that could not be stored is
+// kept for the next flush, so no call goes unbilled.
+func (l *Ledger) Flush(ctx context.Context) error {
+
+	var errs []error
+
+	for _, provider := range l.providers() {
+		if err := l.flush(ctx, provider); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *Ledger) flush(ctx context.Context, provider string) error {
+
+	l.mu.Lock()
+
+	a := l.account(provider)
+
+	day, month := a.day, a.month
+	pending := a.pending
+	a.pending = Usage{}
+
+	marked, until := a.quotaMarked, a.quotaUntil
+	a.quotaMarked = false
+
+	l.mu.Unlock()
+
+	dayTotal, monthTotal, err := l.store.Add(ctx, provider, day, month, pending)
+
+	if err != nil {
+
+		l.mu.Lock()
+		a.pending.Calls += pending.Calls
+		a.pending.Spend += pending.Spend
+		a.quotaMarked = a.quotaMarked || marked
+		l.mu.Unlock()
+
+		return err
+	}
+
+	if marked {
+		err = l.store.MarkQuota(ctx, provider, until)
+	} else {
+		until, err = l.store.Quota(ctx, provider)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// calls recorded while the store was busy are not in its totals yet
+	if a.day == day {
+		a.dayUsage = Usage{Calls: dayTotal.Calls + a.pending.Calls, Spend: dayTotal.Spend + a.pending.Spend}
+	}
+
+	if a.month == month {
+		a.monthUsage = Usage{Calls: monthTotal.Calls + a.pending.Calls, Spend: monthTotal.Spend + a.pending.Spend}
+	}
+
+	if until.After(a.quotaUntil) {
+		a.quotaUntil = until
+	}
+
+	if marked && err != nil {
+		a.quotaMarked = true
+	}
+
+	metrics.ProviderPeriodSpend.WithLabelValues(provider, "day").Set(a.dayUsage.Spend)
+	metrics.ProviderPeriodSpend.WithLabelValues(provider, "month").Set(a.monthUsage.Spend)
+
+	return err
+}
+
+// Run flushes immediately, loading the fleet totals, then every flush
+// interval until ctx is done.
+func (l *Ledger) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+
+		if err := l.Flush(ctx); err != nil {
+			slog.Warn("costs: flush, retrying", "error", err)
+		}
+
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns every paid provider's usage and caps, as of the last
+// flush plus this replica's calls since.
+func (l *Ledger) Report() []ProviderCost {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var report []ProviderCost
+
+	for _, provider := range l.providers() {
+
+		a := l.account(provider)
+		limits := l.limits[provider]
+
+		entry := ProviderCost{
+			Provider: provider,
+			Day: Period{
+				Period:    a.day,
+				Calls:     a.dayUsage.Calls,
+				Spend:     a.dayUsage.Spend,
+				Budget:    limits.DailyBudget,
+				CallLimit: limits.DailyCalls,
+			},
+			Month: Period{
+				Period:    a.month,
+				Calls:     a.monthUsage.Calls,
+				Spend:     a.monthUsage.Spend,
+				Budget:    limits.MonthlyBudget,
+				CallLimit: limits.MonthlyCalls,
+			},
+			Exhausted: l.exhausted(provider, a),
+		}
+
+		if l.now().Before(a.quotaUntil) {
+			until := a.quotaUntil
+			entry.QuotaUntil = &until
+		}
+
+		report = append(report, entry)
+	}
+
+	return report
+}
+
+// Currency is the unit prices and spend are in.
+func (l *Ledger) Currency() string {
+	return l.cfg.Currency
+}
+
+func (l *Ledger) providers() []string {
+
+	names := make([]string, 0, len(l.limits))
+
+	for name := range l.limits {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+var (
+	_ provider.Meter         = (*Ledger)(nil)
+	_ orchestrator.CostGuard = (*Ledger)(nil)
+)
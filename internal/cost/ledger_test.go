@@ -0,0 +1,260 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	usage map[string]Usage
+	quota map[string]time.Time
+	err   error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{usage: map[string]Usage{}, quota: map[string]time.Time{}}
+}
+
+func (s *memoryStore) Add(ctx context.Context, provider string, day string, month string, usage Usage) (Usage, Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return Usage{}, Usage{}, s.err
+	}
+
+	for _, key := range []string{provider + ":" + day, provider + ":" + month} {
+		u := s.usage[key]
+		u.Calls += usage.Calls
+		u.Spend += usage.Spend
+		s.usage[key] = u
+	}
+
+	return s.usage[provider+":"+day], s.usage[provider+":"+month], nil
+}
+
+func (s *memoryStore) MarkQuota(ctx context.Context, provider string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quota[provider] = until
+
+	return nil
+}
+
+func (s *memoryStore) Quota(ctx context.Context, provider string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.quota[provider], nil
+}
+
+var day1 = time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+func newLedger(store Store, google config.ProviderCostConfig) (*Ledger, *time.Time) {
+
+	google.Prices = map[string]float64{"nearby_search": 0.032, "contact_data": 0.003}
+
+	l := NewLedger(store, config.CostsConfig{
+		Enabled:       true,
+		Currency:      "USD",
+		FlushInterval: 10 * time.Second,
+		QuotaCooldown: 15 * time.Minute,
+		Google:        google,
+	})
+
+	now := day1
+	l.now = func() time.Time { return now }
+
+	return l, &now
+}
+
+func report(l *Ledger, provider string) ProviderCost {
+
+	for _, entry := range l.Report() {
+		if entry.Provider == provider {
+			return entry
+		}
+	}
+
+	return ProviderCost{}
+}
+
+func TestLedger_PricesCallsBySKU(t *testing.T) {
+
+	l, _ := newLedger(newMemoryStore(), config.ProviderCostConfig{})
+
+	l.Record("google", []string{"nearby_search", "contact_data"})
+	l.Record("google", []string{"nearby_search", "unpriced"})
+	l.Record("osm", []string{"search"})
+
+	got := report(l, "google")
+
+	if got.Day.Calls != 2 || math.Abs(got.Day.Spend-0.067) > 1e-9 {
+		t.Errorf("Expected 2 calls for 0.067, got %d for %v", got.Day.Calls, got.Day.Spend)
+	}
+
+	if got.Day.Period != "2026-03-31" || got.Month.Period != "2026-03" || got.Month.Calls != 2 {
+		t.Errorf("Expected the calls in 2026-03-31 and 2026-03, got %+v", got)
+	}
+
+	if len(l.Report()) != 2 {
+		t.Errorf("Expected only google and foursquare reported, got %+v", l.Report())
+	}
+}
+
+func TestLedger_SpendBudget(t *testing.T) {
+
+	l, _ := newLedger(newMemoryStore(), config.ProviderCostConfig{DailyBudget: 0.07})
+
+	l.Record("google", []string{"nearby_search", "contact_data"})
+
+	if reason := l.Exhausted("google"); reason != "" {
+		t.Fatalf("Expected google within budget, got %s", reason)
+	}
+
+	l.Record("google", []string{"nearby_search", "contact_data"})
+
+	if reason := l.Exhausted("google"); reason != ReasonSpend {
+		t.Errorf("Expected %s, got '%s'", ReasonSpend, reason)
+	}
+
+	if reason := l.Exhausted("osm"); reason != "" {
+		t.Errorf("Expected a free provider never exhausted, got %s", reason)
+	}
+}
+
+func TestLedger_CallLimit(t *testing.T) {
+
+	l, _ := newLedger(newMemoryStore(), config.ProviderCostConfig{MonthlyCalls: 2})
+
+	l.Record("google", []string{"nearby_search"})
+	l.Record("google", []string{"nearby_search"})
+
+	if reason := l.Exhausted("google"); reason != ReasonQuota {
+		t.Errorf("Expected %s, got '%s'", ReasonQuota, reason)
+	}
+}
+
+func TestLedger_NewDayResetsDailyBudget(t *testing.T) {
+
+	l, now := newLedger(newMemoryStore(), config.ProviderCostConfig{DailyBudget: 0.03, MonthlyBudget: 0.05})
+
+	l.Record("google", []string{"nearby_search"})
+
+	if reason := l.Exhausted("google"); reason != ReasonSpend {
+		t.Fatalf("Expected %s, got '%s'", ReasonSpend, reason)
+	}
+
+	*now = day1.Add(24 * time.Hour)
+
+	got := report(l, "google")
+
+	if got.Day.Calls != 0 || got.Day.Period != "2026-04-01" || got.Month.Period != "2026-04" {
+		t.Errorf("Expected a fresh day and month, got %+v", got)
+	}
+
+	if got.Exhausted != "" {
+		t.Errorf("Expected google available again, got %s", got.Exhausted)
+	}
+}
+
+func TestLedger_QuotaCooldown(t *testing.T) {
+
+	store := newMemoryStore()
+
+	l, now := newLedger(store, config.ProviderCostConfig{})
+
+	l.QuotaExceeded("google")
+
+	if reason := l.Exhausted("google"); reason != ReasonQuota {
+		t.Fatalf("Expected %s, got '%s'", ReasonQuota, reason)
+	}
+
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// another replica picks the quota up on its next flush
+	other, otherNow := newLedger(store, config.ProviderCostConfig{})
+
+	if err := other.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if reason := other.Exhausted("google"); reason != ReasonQuota {
+		t.Errorf("Expected the quota shared, got '%s'", reason)
+	}
+
+	*now = day1.Add(15 * time.Minute)
+	*otherNow = *now
+
+	if l.Exhausted("google") != "" || other.Exhausted("google") != "" {
+		t.Error("Expected google available after the cooldown")
+	}
+}
+
+func TestLedger_FlushSharesTotals(t *testing.T) {
+
+	store := newMemoryStore()
+
+	a, _ := newLedger(store, config.ProviderCostConfig{DailyCalls: 3})
+	b, _ := newLedger(store, config.ProviderCostConfig{DailyCalls: 3})
+
+	a.Record("google", []string{"nearby_search"})
+	a.Record("google", []string{"nearby_search"})
+	b.Record("google", []string{"nearby_search"})
+
+	if b.Exhausted("google") != "" {
+		t.Fatal("Expected b unaware of a's calls before flushing")
+	}
+
+	for _, l := range []*Ledger{a, b} {
+		if err := l.Flush(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if got := report(b, "google").Day.Calls; got != 3 {
+		t.Errorf("Expected 3 fleet calls, got %d", got)
+	}
+
+	if reason := b.Exhausted("google"); reason != ReasonQuota {
+		t.Errorf("Expected the fleet call limit reached, got '%s'", reason)
+	}
+}
+
+func TestLedger_FlushKeepsUsageOnError(t *testing.T) {
+
+	store := newMemoryStore()
+	store.err = errors.New("redis down")
+
+	l, _ := newLedger(store, config.ProviderCostConfig{})
+
+	l.Record("google", []string{"nearby_search"})
+
+	if err := l.Flush(context.Background()); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	store.err = nil
+
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := store.usage["google:2026-03-31"]; got.Calls != 1 {
+		t.Errorf("Expected the call stored on the next flush, got %+v", got)
+	}
+
+	if got := report(l, "google").Day.Calls; got != 1 {
+		t.Errorf("Expected the call counted once, got %d", got)
+	}
+}
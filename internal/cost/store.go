@@ -0,0 +1,104 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Usage is what a provider was billed for in some period.
+type Usage struct {
+	Calls int64   `json:"calls"`
+	Spend float64 `json:"spend"`
+}
+
+// Store keeps provider usage per period, shared by all replicas.
+type Store interface {
+	// Add adds usage to provider's counters for day and month and
+	// returns the resulting totals. Adding zero usage reads them.
+	Add(ctx context.Context, provider string, day string, month string, usage Usage) (Usage, Usage, error)
+
+	// MarkQuota records that provider's upstream quota is exhausted
+	// until until.
+	MarkQuota(ctx context.Context, provider string, until time.Time) error
+
+	// Quota returns until when provider's quota is exhausted, or the
+	// zero time.
+	Quota(ctx context.Context, provider string) (time.Time, error)
+}
+
+const keyPrefix = "hynek-poi:costs:"
+
+// Periods are kept a while past their end, for the admin API and
+// reconciliation with invoices.
+const (
+	dayRetention   = 35 * 24 * time.Hour
+	monthRetention = 400 * 24 * time.Hour
+)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Add(ctx context.Context, provider string, day string, month string, usage Usage) (Usage, Usage, error) {
+
+	pipe := s.client.TxPipeline()
+
+	add := func(period string, retention time.Duration) (*redis.IntCmd, *redis.FloatCmd) {
+
+		key := keyPrefix + provider + ":" + period
+
+		calls := pipe.HIncrBy(ctx, key, "calls", usage.Calls)
+		spend := pipe.HIncrByFloat(ctx, key, "spend", usage.Spend)
+
+		pipe.Expire(ctx, key, retention)
+
+		return calls, spend
+	}
+
+	dayCalls, daySpend := add(day, dayRetention)
+	monthCalls, monthSpend := add(month, monthRetention)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Usage{}, Usage{}, err
+	}
+
+	return Usage{Calls: dayCalls.Val(), Spend: daySpend.Val()},
+		Usage{Calls: monthCalls.Val(), Spend: monthSpend.Val()},
+		nil
+}
+
+func (s *RedisStore) MarkQuota(ctx context.Context, provider string, until time.Time) error {
+
+	return s.client.Set(ctx, keyPrefix+provider+":quota", until.Unix(), time.Until(until)).Err()
+}
+
+func (s *RedisStore) Quota(ctx context.Context, provider string) (time.Time, error) {
+
+	v, err := s.client.Get(ctx, keyPrefix+provider+":quota").Result()
+
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	unix, err := strconv.ParseInt(v, 10, 64)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(unix, 0), nil
+}
+
+var _ Store = (*RedisStore)(nil)
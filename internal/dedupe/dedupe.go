@@ -13,28 +13,47 @@ func Deduplicate(pois []domain.POI) []domain.POI {
 
 	var result []domain.POI
 
+	for _, group := range Group(pois) {
+		result = append(result, group[0])
+	}
+
+	return result
+}
+
+// Group clusters the POIs Deduplicate treats as one place. The first POI
+// of each group is the one Deduplicate keeps; the others are its matches
+// from other providers.
+func Group(pois []domain.POI) [][]domain.POI {
+
+	var groups [][]domain.POI
+
 	for _, poi := range pois {
 
-		if !exists(result, poi) {
-			result = append(result, poi)
+		if i := find(groups, poi); i >= 0 {
+			groups[i] = append(groups[i], poi)
+			continue
 		}
+
+		groups = append(groups, []domain.POI{poi})
 	}
 
-	return result
+	return groups
 }
 
-func exists(pois []domain.POI, candidate domain.POI) bool {
+func find(groups [][]domain.POI, candidate domain.POI) int {
+
+	for i, group := range groups {
 
-	for _, existing := range pois {
+		existing := group[0]
 
 		if sameName(existing.Name, candidate.Name) &&
 			sameLocation(existing, candidate) {
 
-			return true
+			return i
 		}
 	}
 
-	return false
+	return -1
 }
 
 func sameName(a, b string) bool {
@@ -157,3 +157,21 @@ func TestDistanceMeters(t *testing.T) {
 		t.Errorf("Expected ~1111 meters, got %f", dist)
 	}
 }
+
+func TestGroup_KeepsMatches(t *testing.T) {
+	pois := []domain.POI{
+		{ID: "1", Name: "Restaurant A", Latitude: 59.3293, Longitude: 18.0686, Source: "google"},
+		{ID: "2", Name: "Cafe C", Latitude: 59.3500, Longitude: 18.0800, Source: "osm"},
+		{ID: "3", Name: "restaurant a", Latitude: 59.32935, Longitude: 18.06865, Source: "osm"},
+	}
+
+	groups := Group(pois)
+
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+
+	if len(groups[0]) != 2 || groups[0][0].ID != "1" || groups[0][1].ID != "3" {
+		t.Errorf("Expected restaurant group [1 3], got %+v", groups[0])
+	}
+}
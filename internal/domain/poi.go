@@ -27,4 +27,12 @@ type POI struct {
 	Delivery             *bool    `json:"delivery,omitempty"`
 	Verified             *bool    `json:"verified,omitempty"`
 	Popularity           float64  `json:"popularity,omitempty"`
+
+	// CanonicalID is Hynek's stable ID for the place, the same whichever
+	// provider's record won deduplication.
+	CanonicalID string `json:"canonical_id,omitempty"`
+
+	// Pinned is set by curated overrides; pinned POIs rank first, in
+	// ascending order. Zero means not pinned.
+	Pinned int `json:"-"`
 }
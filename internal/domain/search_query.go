@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
 type SearchQuery struct {
 	Latitude  float64
 	Longitude float64
@@ -20,10 +25,57 @@ type BBox struct {
 	MaxLng float64
 }
 
+// Bounds of the searches the APIs accept.
+const (
+	// MaxRadius is the largest search radius in meters.
+	MaxRadius = 50000
+
+	// MaxLimit is the most POIs one search may ask for.
+	MaxLimit = 200
+)
+
+// Validate checks q's radius, limit and bbox against what the APIs
+// accept. A zero radius or limit is valid; callers apply their default.
+func (q SearchQuery) Validate() error {
+
+	if q.Radius < 0 || q.Radius > MaxRadius {
+		return fmt.Errorf("radius must be between 0 and %d", MaxRadius)
+	}
+
+	if q.Limit < 0 || q.Limit > MaxLimit {
+		return fmt.Errorf("limit must be between 0 and %d", MaxLimit)
+	}
+
+	if q.BBox != nil {
+		return q.BBox.Validate()
+	}
+
+	return nil
+}
+
+// Validate checks that b lies within valid coordinates and its minimums
+// do not exceed its maximums.
+func (b BBox) Validate() error {
+
+	if !(b.MinLat >= -90 && b.MaxLat <= 90 && b.MinLng >= -180 && b.MaxLng <= 180) {
+		return errors.New("bbox must lie within latitude -90..90 and longitude -180..180")
+	}
+
+	if !(b.MinLat <= b.MaxLat && b.MinLng <= b.MaxLng) {
+		return errors.New("bbox min must not exceed max")
+	}
+
+	return nil
+}
+
 type PaginatedResponse struct {
 	Data       []POI `json:"data"`
 	Total      int   `json:"total"`
 	Page       int   `json:"page"`
 	PageSize   int   `json:"page_size"`
 	TotalPages int   `json:"total_pages"`
+
+	Complete  bool             `json:"complete"`
+	Cached    bool             `json:"cached"`
+	Providers []ProviderStatus `json:"providers,omitempty"`
 }
@@ -0,0 +1,46 @@
+package domain
+
+// Provider outcome of a single search.
+const (
+	ProviderStatusOK          = "ok"
+	ProviderStatusError       = "error"
+	ProviderStatusTimeout     = "timeout"
+	ProviderStatusCircuitOpen = "circuit_open"
+	ProviderStatusSkipped     = "skipped"
+)
+
+type ProviderStatus struct {
+	Provider    string `json:"provider"`
+	Status      string `json:"status"`
+	ErrorClass  string `json:"error_class,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	ResultCount int    `json:"result_count"`
+
+	// Truncated is set when the provider may have left out places: it
+	// returned as many as one call of it was allowed, or searched a
+	// smaller radius than asked.
+	Truncated bool `json:"-"`
+}
+
+// SearchResult is the merged answer together with how it was produced.
+// Complete is false when at least one provider did not answer normally.
+type SearchResult struct {
+	POIs      []POI
+	Complete  bool
+	Cached    bool
+	Providers []ProviderStatus
+}
+
+// DegradedProviders lists providers whose status is not ok.
+func (r SearchResult) DegradedProviders() []string {
+
+	var names []string
+
+	for _, p := range r.Providers {
+		if p.Status != ProviderStatusOK {
+			names = append(names, p.Provider)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,268 @@
+// Package fixture records provider HTTP traffic to files and replays it,
+// so pipelines can run against realistic provider data without a network.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secrets in recorded requests.
+const Redacted = "REDACTED"
+
+// ErrNoFixture means a replayed request was never recorded.
+var ErrNoFixture = errors.New("no fixture recorded for request")
+
+// secretParams are query and form parameters carrying credentials.
+var secretParams = []string{"key", "api_key", "apikey", "access_token", "token", "client_secret", "client_id"}
+
+// secretHeaders are headers carrying credentials.
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "X-Goog-Api-Key", "Cookie"}
+
+// responseHeaders are the response headers worth keeping; the rest are
+// transport noise that would make fixtures differ on every recording.
+var responseHeaders = []string{"Content-Type", "Retry-After"}
+
+// Interaction is one recorded request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// Response holds the body as JSON when it is JSON, so fixtures stay
+// readable and editable, and as text otherwise.
+type Response struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	JSON    json.RawMessage     `json:"json,omitempty"`
+	Text    string              `json:"text,omitempty"`
+}
+
+// body returns the response body as the provider received it.
+func (r Response) body() []byte {
+
+	if len(r.JSON) > 0 {
+		return r.JSON
+	}
+
+	return []byte(r.Text)
+}
+
+func newResponse(status int, header http.Header, body []byte) Response {
+
+	resp := Response{
+		Status:  status,
+		Headers: map[string][]string{},
+	}
+
+	for _, name := range responseHeaders {
+		if v := header.Values(name); len(v) > 0 {
+			resp.Headers[name] = v
+		}
+	}
+
+	if len(resp.Headers) == 0 {
+		resp.Headers = nil
+	}
+
+	var compact bytes.Buffer
+
+	if json.Valid(body) && json.Compact(&compact, body) == nil {
+		resp.JSON = compact.Bytes()
+	} else {
+		resp.Text = string(body)
+	}
+
+	return resp
+}
+
+// Scrub copies req, with its body, into a Request with every credential
+// redacted. Scrubbed requests are what fixtures are keyed by, so a replay
+// matches whichever key the recording was made with.
+func Scrub(req *http.Request, body []byte) Request {
+
+	u := *req.URL
+	u.RawQuery = scrubValues(u.Query()).Encode()
+	u.User = nil
+
+	out := Request{
+		Method:  req.Method,
+		URL:     u.String(),
+		Headers: map[string][]string{},
+		Body:    string(body),
+	}
+
+	for name, values := range req.Header {
+		out.Headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+
+	for _, name := range secretHeaders {
+		if _, ok := out.Headers[name]; ok {
+			out.Headers[name] = []string{Redacted}
+		}
+	}
+
+	if len(out.Headers) == 0 {
+		out.Headers = nil
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(out.Body); err == nil {
+			out.Body = scrubValues(form).Encode()
+		}
+	}
+
+	return out
+}
+
+func scrubValues(values url.Values) url.Values {
+
+	for name := range values {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				values[name] = []string{Redacted}
+			}
+		}
+	}
+
+	return values
+}
+
+// Key identifies a scrubbed request: its method, URL with sorted query
+// and body. Headers are left out, since clients add their own.
+func (r Request) Key() string {
+
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%s\n%s\n%s", r.Method, r.URL, r.Body)
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Dir stores the fixtures of one provider, one file per request, named
+// by the request's Key.
+type Dir string
+
+func (d Dir) path(key string) string {
+	return filepath.Join(string(d), key+".json")
+}
+
+// Load reads the interaction recorded for req, or ErrNoFixture.
+func (d Dir) Load(req Request) (Interaction, error) {
+
+	data, err := os.ReadFile(d.path(req.Key()))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return Interaction{}, fmt.Errorf("%w: %s %s", ErrNoFixture, req.Method, req.URL)
+	}
+
+	if err != nil {
+		return Interaction{}, err
+	}
+
+	var in Interaction
+
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Interaction{}, fmt.Errorf("fixture %s: %w", d.path(req.Key()), err)
+	}
+
+	return in, nil
+}
+
+// Save writes in, replacing any earlier recording of the same request.
+// The file is written under a temporary name and renamed, so concurrent
+// replays never read half a fixture.
+func (d Dir) Save(in Interaction) error {
+
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(in); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(string(d), ".fixture-*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), d.path(in.Request.Key()))
+}
+
+// List returns the keys of every recorded request, sorted.
+func (d Dir) List() ([]string, error) {
+
+	entries, err := os.ReadDir(string(d))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			keys = append(keys, name)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// readBody drains body and returns its bytes along with a reader that
+// yields them again.
+func readBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+
+	if body == nil || body == http.NoBody {
+		return nil, http.NoBody, nil
+	}
+
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, io.NopCloser(bytes.NewReader(data)), nil
+}
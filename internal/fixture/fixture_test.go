@@ -0,0 +1,135 @@
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Trace", "abc")
+		w.WriteHeader(http.StatusOK)
+
+		io.WriteString(w, `{"results": [{"name": "Café Slavia"}]}`)
+	}))
+	defer server.Close()
+
+	dir := Dir(t.TempDir())
+
+	client := &http.Client{Transport: NewRecorder(dir, nil)}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/search?ll=50.08,14.41&key=secret-key", nil)
+	req.Header.Set("Authorization", "secret-token")
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	live, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(live) != `{"results": [{"name": "Café Slavia"}]}` {
+		t.Errorf("Expected the live body to reach the caller, got %s", live)
+	}
+
+	keys, err := dir.List()
+
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("Expected 1 fixture, got %v (%v)", keys, err)
+	}
+
+	saved, _ := os.ReadFile(filepath.Join(string(dir), keys[0]+".json"))
+
+	for _, secret := range []string{"secret-key", "secret-token"} {
+		if strings.Contains(string(saved), secret) {
+			t.Errorf("Expected %s to be scrubbed, got %s", secret, saved)
+		}
+	}
+
+	if strings.Contains(string(saved), "X-Request-Trace") {
+		t.Errorf("Expected transport headers to be dropped, got %s", saved)
+	}
+
+	server.Close()
+
+	// a different key replays the same recording
+	replay := &http.Client{Transport: NewReplayer(dir)}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/search?key=other-key&ll=50.08,14.41", nil)
+
+	resp, err = replay.Do(req)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected content type application/json, got %s", resp.Header.Get("Content-Type"))
+	}
+
+	var body struct {
+		Results []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+
+	if err := json.Unmarshal(replayed, &body); err != nil || len(body.Results) != 1 || body.Results[0].Name != "Café Slavia" {
+		t.Errorf("Expected the recorded body, got %s", replayed)
+	}
+}
+
+func TestReplay_UnknownRequest(t *testing.T) {
+
+	client := &http.Client{Transport: NewReplayer(Dir(t.TempDir()))}
+
+	_, err := client.Get("https://example.com/search?ll=1,2")
+
+	if !errors.Is(err, ErrNoFixture) {
+		t.Errorf("Expected ErrNoFixture, got %v", err)
+	}
+}
+
+func TestScrub_FormBody(t *testing.T) {
+
+	form := url.Values{"data": {"[out:json];node;out;"}, "api_key": {"secret"}}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/api", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	scrubbed := Scrub(req, []byte(form.Encode()))
+
+	if strings.Contains(scrubbed.Body, "secret") {
+		t.Errorf("Expected api_key to be scrubbed, got %s", scrubbed.Body)
+	}
+
+	if !strings.Contains(scrubbed.Body, "data=") {
+		t.Errorf("Expected other fields to be kept, got %s", scrubbed.Body)
+	}
+}
+
+func TestTransport_Off(t *testing.T) {
+
+	if rt := Transport(ModeOff, t.TempDir(), "osm", nil); rt != nil {
+		t.Errorf("Expected no transport when off, got %T", rt)
+	}
+}
@@ -0,0 +1,154 @@
+package fixture
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// Mode says what providers do with their HTTP traffic.
+type Mode string
+
+const (
+	// ModeOff sends requests to providers as usual.
+	ModeOff Mode = "off"
+
+	// ModeRecord sends requests to providers and saves every exchange.
+	ModeRecord Mode = "record"
+
+	// ModeReplay answers requests from saved exchanges only.
+	ModeReplay Mode = "replay"
+)
+
+// Transport returns the RoundTripper for provider's client in mode, with
+// fixtures kept under dir/provider. next is used for live requests; nil
+// means http.DefaultTransport. It returns nil for ModeOff, leaving the
+// client's transport alone.
+func Transport(mode Mode, dir string, provider string, next http.RoundTripper) http.RoundTripper {
+
+	fixtures := Dir(filepath.Join(dir, provider))
+
+	switch mode {
+
+	case ModeRecord:
+		return NewRecorder(fixtures, next)
+
+	case ModeReplay:
+		return NewReplayer(fixtures)
+	}
+
+	return nil
+}
+
+// Recorder is a RoundTripper that passes requests on and saves each
+// exchange, credentials scrubbed, to its Dir. Failing to save is logged,
+// never returned, so recording cannot break live traffic.
+type Recorder struct {
+	dir  Dir
+	next http.RoundTripper
+}
+
+func NewRecorder(dir Dir, next http.RoundTripper) *Recorder {
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:  dir,
+		next: next,
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	body, replay, err := readBody(req.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the caller's request
+	sent := req.Clone(req.Context())
+	sent.Body = replay
+
+	resp, err := r.next.RoundTrip(sent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, again, err := readBody(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = again
+
+	in := Interaction{
+		Request:  Scrub(req, body),
+		Response: newResponse(resp.StatusCode, resp.Header, respBody),
+	}
+
+	if err := r.dir.Save(in); err != nil {
+		log.Printf("fixture: recording %s %s: %v", in.Request.Method, in.Request.URL, err)
+	}
+
+	return resp, nil
+}
+
+// Replayer is a RoundTripper that answers from the exchanges saved in its
+// Dir and never touches the network. Unrecorded requests fail with
+// ErrNoFixture.
+type Replayer struct {
+	dir Dir
+}
+
+func NewReplayer(dir Dir) *Replayer {
+	return &Replayer{dir: dir}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	body, _, err := readBody(req.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := r.dir.Load(Scrub(req, body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	respBody := in.Response.body()
+
+	header := http.Header{}
+
+	for name, values := range in.Response.Headers {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	if header.Get("Content-Type") == "" && len(in.Response.JSON) > 0 {
+		header.Set("Content-Type", "application/json")
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(respBody)))
+
+	return &http.Response{
+		Status:        strconv.Itoa(in.Response.Status) + " " + http.StatusText(in.Response.Status),
+		StatusCode:    in.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+const (
+	// searchCost reflects that every search may fan out to all providers.
+	searchCost = 10
+
+	// A search costs one more per limitCost POIs and per radiusCost
+	// meters it asks for, since both widen the upstream fetch.
+	limitCost  = 10
+	radiusCost = 1000
+
+	maxDepth = 15
+)
+
+var errTooDeep = errors.New("query exceeds maximum depth")
+
+// complexity estimates the cost of running the selected operation. Every
+// field costs one, search adds searchCost plus a share of its limit and
+// radius, and fields under edges are multiplied by the page size
+// requested through first. Arguments must be within their bounds so no
+// alias can lower the total.
+func complexity(request string, operationName string, variables map[string]interface{}) (int, error) {
+
+	doc, err := parser.Parse(parser.ParseParams{Source: request})
+
+	if err != nil {
+		return 0, err
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+
+	var operation *ast.OperationDefinition
+
+	for _, def := range doc.Definitions {
+
+		switch d := def.(type) {
+
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || (d.Name != nil && d.Name.Value == operationName)) {
+				operation = d
+			}
+		}
+	}
+
+	if operation == nil {
+		return 0, errors.New("operation not found")
+	}
+
+	w := &complexityWalker{
+		fragments: fragments,
+		variables: variables,
+	}
+
+	return w.walk(operation.SelectionSet, 0, defaultPageSize)
+}
+
+type complexityWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (w *complexityWalker) walk(set *ast.SelectionSet, depth int, first int) (int, error) {
+
+	if set == nil {
+		return 0, nil
+	}
+
+	if depth > maxDepth {
+		return 0, errTooDeep
+	}
+
+	total := 0
+
+	for _, selection := range set.Selections {
+
+		var cost int
+		var err error
+
+		switch s := selection.(type) {
+
+		case *ast.Field:
+			cost, err = w.field(s, depth, first)
+
+		case *ast.InlineFragment:
+			cost, err = w.walk(s.SelectionSet, depth+1, first)
+
+		case *ast.FragmentSpread:
+			if fragment, ok := w.fragments[s.Name.Value]; ok {
+				cost, err = w.walk(fragment.SelectionSet, depth+1, first)
+			}
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		total += cost
+	}
+
+	return total, nil
+}
+
+func (w *complexityWalker) field(field *ast.Field, depth int, first int) (int, error) {
+
+	cost := 1
+
+	switch field.Name.Value {
+
+	case "search":
+		cost += searchCost
+		first = w.intArgument(field, "first", defaultPageSize)
+
+		if first < 1 || first > maxPageSize {
+			return 0, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+		}
+
+		limit := w.intArgument(field, "limit", defaultLimit)
+		radius := w.intArgument(field, "radius", defaultRadius)
+
+		if err := (domain.SearchQuery{Limit: limit, Radius: radius}).Validate(); err != nil {
+			return 0, err
+		}
+
+		cost += limit/limitCost + radius/radiusCost
+	}
+
+	children, err := w.walk(field.SelectionSet, depth+1, first)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if field.Name.Value == "edges" {
+		children *= first
+	}
+
+	return cost + children, nil
+}
+
+func (w *complexityWalker) intArgument(field *ast.Field, name string, defaultVal int) int {
+
+	for _, arg := range field.Arguments {
+
+		if arg.Name.Value != name {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return n
+			}
+
+		case *ast.Variable:
+			switch n := w.variables[v.Name.Value].(type) {
+			case float64:
+				return int(n)
+			case int:
+				return n
+			}
+		}
+	}
+
+	return defaultVal
+}
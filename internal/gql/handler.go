@@ -0,0 +1,112 @@
+// Package gql serves the GraphQL API on top of the search orchestrator.
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+)
+
+type Handler struct {
+	schema        graphql.Schema
+	maxComplexity int
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler builds the GraphQL handler. orch is called per search so
+// config reloads are picked up; index backs the poi lookup.
+func NewHandler(
+	orch func() orchestrator.StreamingOrchestrator,
+	index *cache.POIIndex,
+	maxComplexity int,
+) (*Handler, error) {
+
+	schema, err := newSchema(orch, index)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		schema:        schema,
+		maxComplexity: maxComplexity,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	var req request
+
+	switch r.Method {
+
+	case http.MethodGet:
+
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+
+	case http.MethodPost:
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.Query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	cost, err := complexity(req.Query, req.OperationName, req.Variables)
+
+	if err == nil && cost > h.maxComplexity {
+		err = fmt.Errorf("query complexity %d exceeds limit %d", cost, h.maxComplexity)
+	}
+
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, &graphql.Result{
+			Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())},
+		})
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+
+	writeResult(w, http.StatusOK, result)
+}
+
+func writeResult(w http.ResponseWriter, status int, result *graphql.Result) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(result)
+}
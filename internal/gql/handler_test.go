@@ -0,0 +1,286 @@
+package gql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+)
+
+type fakeOrchestrator struct {
+	pois  []domain.POI
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (f *fakeOrchestrator) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	result, err := f.SearchWithStatus(query)
+	return result.POIs, err
+}
+
+func (f *fakeOrchestrator) SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error) {
+	return f.SearchStream(context.Background(), query, nil)
+}
+
+func (f *fakeOrchestrator) SearchStream(ctx context.Context, query domain.SearchQuery, observe func(orchestrator.ProviderUpdate)) (domain.SearchResult, error) {
+	f.calls.Add(1)
+	time.Sleep(f.delay)
+	return domain.SearchResult{
+		POIs:     f.pois,
+		Complete: true,
+		Providers: []domain.ProviderStatus{
+			{Provider: "osm", Status: domain.ProviderStatusOK, ResultCount: len(f.pois)},
+		},
+	}, nil
+}
+
+func newTestHandler(t *testing.T, orch *fakeOrchestrator, index *cache.POIIndex) *Handler {
+	t.Helper()
+
+	h, err := NewHandler(func() orchestrator.StreamingOrchestrator { return orch }, index, 2000)
+	if err != nil {
+		t.Fatalf("Failed to build handler: %v", err)
+	}
+
+	return h
+}
+
+func do(t *testing.T, h http.Handler, query string, variables map[string]interface{}) (int, map[string]interface{}) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/graphql", bytes.NewReader(body)))
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON response: %v: %s", err, rec.Body.String())
+	}
+
+	return rec.Code, resp
+}
+
+func testPOIs(n int) []domain.POI {
+	pois := make([]domain.POI, n)
+	for i := range pois {
+		pois[i] = domain.POI{
+			ID:        string(rune('a' + i)),
+			Name:      "POI",
+			Latitude:  59.33,
+			Longitude: 18.07,
+			Source:    "osm",
+		}
+	}
+	return pois
+}
+
+func TestHandler_SearchPagination(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(5)}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	query := `query($after: String) {
+		search(lat: 59.33, lng: 18.07, first: 2, after: $after) {
+			totalCount
+			complete
+			edges { cursor node { id name } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	status, resp := do(t, h, query, nil)
+	if status != http.StatusOK || resp["errors"] != nil {
+		t.Fatalf("Unexpected failure: %d %v", status, resp["errors"])
+	}
+
+	search := resp["data"].(map[string]interface{})["search"].(map[string]interface{})
+
+	if search["totalCount"].(float64) != 5 {
+		t.Errorf("Expected totalCount 5, got %v", search["totalCount"])
+	}
+
+	edges := search["edges"].([]interface{})
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(edges))
+	}
+
+	pageInfo := search["pageInfo"].(map[string]interface{})
+	if pageInfo["hasNextPage"] != true {
+		t.Error("Expected another page")
+	}
+
+	_, resp = do(t, h, query, map[string]interface{}{"after": pageInfo["endCursor"]})
+
+	edges = resp["data"].(map[string]interface{})["search"].(map[string]interface{})["edges"].([]interface{})
+	first := edges[0].(map[string]interface{})["node"].(map[string]interface{})
+
+	if first["id"] != "c" {
+		t.Errorf("Expected second page to start at c, got %v", first["id"])
+	}
+}
+
+func TestHandler_BatchedSearchesRunConcurrently(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(1), delay: 100 * time.Millisecond}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	query := `{
+		a: search(lat: 59.33, lng: 18.07) { totalCount }
+		b: search(lat: 59.34, lng: 18.08) { totalCount }
+		c: search(lat: 59.35, lng: 18.09) { totalCount }
+	}`
+
+	start := time.Now()
+
+	status, resp := do(t, h, query, nil)
+	if status != http.StatusOK || resp["errors"] != nil {
+		t.Fatalf("Unexpected failure: %d %v", status, resp["errors"])
+	}
+
+	if orch.calls.Load() != 3 {
+		t.Errorf("Expected 3 searches, got %d", orch.calls.Load())
+	}
+
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Expected searches to run concurrently, took %v", elapsed)
+	}
+}
+
+func TestHandler_POILookup(t *testing.T) {
+	index := cache.NewPOIIndex(time.Minute, 100)
+	index.Add([]domain.POI{{ID: "42", Source: "google", Name: "Indexed"}})
+
+	h := newTestHandler(t, &fakeOrchestrator{}, index)
+
+	_, resp := do(t, h, `{ poi(source: "google", id: "42") { name } missing: poi(source: "osm", id: "1") { name } }`, nil)
+
+	data := resp["data"].(map[string]interface{})
+
+	if data["poi"].(map[string]interface{})["name"] != "Indexed" {
+		t.Errorf("Expected indexed POI, got %v", data["poi"])
+	}
+
+	if data["missing"] != nil {
+		t.Errorf("Expected null for unknown POI, got %v", data["missing"])
+	}
+}
+
+func TestHandler_Categories(t *testing.T) {
+	h := newTestHandler(t, &fakeOrchestrator{}, cache.NewPOIIndex(time.Minute, 100))
+
+	_, resp := do(t, h, `{ categories }`, nil)
+
+	categories := resp["data"].(map[string]interface{})["categories"].([]interface{})
+	if len(categories) == 0 {
+		t.Error("Expected categories")
+	}
+}
+
+func TestHandler_ComplexityLimit(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(1)}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < 10; i++ {
+		b.WriteString("s")
+		b.WriteString(string(rune('a' + i)))
+		b.WriteString(`: search(first: 100) { edges { node { id name latitude longitude } } } `)
+	}
+	b.WriteString("}")
+
+	status, resp := do(t, h, b.String(), nil)
+
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for expensive query, got %d", status)
+	}
+
+	if resp["errors"] == nil {
+		t.Error("Expected complexity error")
+	}
+
+	if orch.calls.Load() != 0 {
+		t.Errorf("Expected no searches for rejected query, got %d", orch.calls.Load())
+	}
+}
+
+func TestHandler_NegativeFirstCannotOffsetComplexity(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(1)}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	status, resp := do(t, h, `{
+		cheap: search(first: -1000) { edges { node { id name latitude longitude } } }
+		a: search(first: 100) { edges { node { id name latitude longitude } } }
+		b: search(first: 100) { edges { node { id name latitude longitude } } }
+		c: search(first: 100) { edges { node { id name latitude longitude } } }
+		d: search(first: 100) { edges { node { id name latitude longitude } } }
+		e: search(first: 100) { edges { node { id name latitude longitude } } }
+	}`, nil)
+
+	if status != http.StatusBadRequest || resp["errors"] == nil {
+		t.Errorf("Expected 400 for an invalid page size, got %d", status)
+	}
+
+	if orch.calls.Load() != 0 {
+		t.Errorf("Expected no searches for rejected query, got %d", orch.calls.Load())
+	}
+}
+
+func TestHandler_LimitAndRadiusAddToComplexity(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(1)}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	searches := func(args string) string {
+
+		var b strings.Builder
+		b.WriteString("{")
+		for i := 0; i < 7; i++ {
+			fmt.Fprintf(&b, "s%d: search(first: 50%s) { edges { node { id name latitude longitude } } } ", i, args)
+		}
+		b.WriteString("}")
+
+		return b.String()
+	}
+
+	if status, _ := do(t, h, searches(""), nil); status != http.StatusOK {
+		t.Fatalf("Expected default searches within the limit, got %d", status)
+	}
+
+	status, resp := do(t, h, searches(", radius: 50000, limit: 200"), nil)
+
+	if status != http.StatusBadRequest || resp["errors"] == nil {
+		t.Errorf("Expected 400 for wide searches, got %d", status)
+	}
+}
+
+func TestHandler_RejectsOutOfBoundsSearch(t *testing.T) {
+	orch := &fakeOrchestrator{pois: testPOIs(1)}
+	h := newTestHandler(t, orch, cache.NewPOIIndex(time.Minute, 100))
+
+	for _, q := range []string{
+		`{ search(lat: 59.3, lng: 18.0, limit: 100000) { totalCount } }`,
+		`{ search(lat: 59.3, lng: 18.0, radius: 10000000) { totalCount } }`,
+		`{ search(bbox: { minLat: 59.34, minLng: 18.05, maxLat: 59.32, maxLng: 18.08 }) { totalCount } }`,
+		`{ search(bbox: { minLat: -80, minLng: -170, maxLat: 80, maxLng: 170 }) { totalCount } }`,
+	} {
+
+		_, resp := do(t, h, q, nil)
+
+		if resp["errors"] == nil {
+			t.Errorf("Expected an error for %s", q)
+		}
+	}
+
+	if orch.calls.Load() != 0 {
+		t.Errorf("Expected no searches for rejected queries, got %d", orch.calls.Load())
+	}
+}
@@ -0,0 +1,453 @@
+package gql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/geo"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+const (
+	defaultRadius   = 1000
+	defaultLimit    = 50
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// searchConnection is the resolved value of the search field. Child
+// fields read from it, so the orchestrator is called once per search.
+type searchConnection struct {
+	query  domain.SearchQuery
+	result domain.SearchResult
+	offset int
+	first  int
+}
+
+type poiEdge struct {
+	cursor string
+	node   poiNode
+}
+
+// poiNode pairs a POI with the query it answered, for derived fields.
+type poiNode struct {
+	poi   domain.POI
+	query domain.SearchQuery
+}
+
+func newSchema(
+	orch func() orchestrator.StreamingOrchestrator,
+	index *cache.POIIndex,
+) (graphql.Schema, error) {
+
+	providerStatusType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProviderStatus",
+		Fields: graphql.Fields{
+			"provider":    statusField(graphql.NewNonNull(graphql.String), func(s domain.ProviderStatus) interface{} { return s.Provider }),
+			"status":      statusField(graphql.NewNonNull(graphql.String), func(s domain.ProviderStatus) interface{} { return s.Status }),
+			"errorClass":  statusField(graphql.String, func(s domain.ProviderStatus) interface{} { return nullString(s.ErrorClass) }),
+			"latencyMs":   statusField(graphql.NewNonNull(graphql.Int), func(s domain.ProviderStatus) interface{} { return int(s.LatencyMs) }),
+			"resultCount": statusField(graphql.NewNonNull(graphql.Int), func(s domain.ProviderStatus) interface{} { return s.ResultCount }),
+		},
+	})
+
+	poiType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "POI",
+		Fields: poiFields(),
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "POIEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(poiEdge).cursor, nil
+				},
+			},
+			"node": &graphql.Field{
+				Type: graphql.NewNonNull(poiType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(poiEdge).node, nil
+				},
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*searchConnection)
+					return c.offset+c.first < len(c.result.POIs), nil
+				},
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*searchConnection)
+					end := c.end()
+					if end == c.offset {
+						return nil, nil
+					}
+					return encodeCursor(end - 1), nil
+				},
+			},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*searchConnection)
+					var edges []poiEdge
+					for i := c.offset; i < c.end(); i++ {
+						edges = append(edges, poiEdge{
+							cursor: encodeCursor(i),
+							node:   poiNode{poi: c.result.POIs[i], query: c.query},
+						})
+					}
+					return edges, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfoType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+			"totalCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(p.Source.(*searchConnection).result.POIs), nil
+				},
+			},
+			"complete": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*searchConnection).result.Complete, nil
+				},
+			},
+			"cached": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*searchConnection).result.Cached, nil
+				},
+			},
+			"providers": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(providerStatusType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*searchConnection).result.Providers, nil
+				},
+			},
+		},
+	})
+
+	bboxInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "BBoxInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"minLat": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"minLng": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"maxLat": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"maxLng": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"search": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"lat":        &graphql.ArgumentConfig{Type: graphql.Float},
+					"lng":        &graphql.ArgumentConfig{Type: graphql.Float},
+					"radius":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultRadius},
+					"limit":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+					"bbox":       &graphql.ArgumentConfig{Type: bboxInput},
+					"categories": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"first":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":      &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveSearch(p.Context, orch(), p.Args)
+				},
+			},
+			"poi": &graphql.Field{
+				Type: poiType,
+				Args: graphql.FieldConfigArgument{
+					"source": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"id":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					poi, found := index.Get(p.Args["source"].(string), p.Args["id"].(string))
+					if !found {
+						return nil, nil
+					}
+					return poiNode{poi: poi}, nil
+				},
+			},
+			"categories": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return provider.Categories(), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+}
+
+// resolveSearch starts the search right away and returns a thunk, so
+// several aliased searches in one document run concurrently. They are
+// cancelled with the request.
+func resolveSearch(ctx context.Context, orch orchestrator.StreamingOrchestrator, args map[string]interface{}) (interface{}, error) {
+
+	query, first, offset, err := searchArgs(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type outcome struct {
+		result domain.SearchResult
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+
+	go func() {
+		result, err := orch.SearchStream(ctx, query, nil)
+		done <- outcome{result: result, err: err}
+	}()
+
+	return func() (interface{}, error) {
+
+		o := <-done
+
+		if o.err != nil {
+			return nil, o.err
+		}
+
+		return &searchConnection{
+			query:  query,
+			result: o.result,
+			offset: offset,
+			first:  first,
+		}, nil
+	}, nil
+}
+
+func searchArgs(args map[string]interface{}) (domain.SearchQuery, int, int, error) {
+
+	query := domain.SearchQuery{
+		Radius: intArg(args, "radius", defaultRadius),
+		Limit:  intArg(args, "limit", defaultLimit),
+	}
+
+	if v, ok := args["lat"].(float64); ok {
+		query.Latitude = v
+	}
+
+	if v, ok := args["lng"].(float64); ok {
+		query.Longitude = v
+	}
+
+	if bbox, ok := args["bbox"].(map[string]interface{}); ok {
+		query.BBox = &domain.BBox{
+			MinLat: bbox["minLat"].(float64),
+			MinLng: bbox["minLng"].(float64),
+			MaxLat: bbox["maxLat"].(float64),
+			MaxLng: bbox["maxLng"].(float64),
+		}
+	}
+
+	if categories, ok := args["categories"].([]interface{}); ok {
+		for _, c := range categories {
+			query.Categories = append(query.Categories, c.(string))
+		}
+	}
+
+	if err := query.Validate(); err != nil {
+		return query, 0, 0, err
+	}
+
+	if err := cache.CheckArea(query); err != nil {
+		return query, 0, 0, err
+	}
+
+	first := intArg(args, "first", defaultPageSize)
+
+	if first < 1 || first > maxPageSize {
+		return query, 0, 0, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+
+	offset := 0
+
+	if after, ok := args["after"].(string); ok && after != "" {
+
+		index, err := decodeCursor(after)
+
+		if err != nil {
+			return query, 0, 0, err
+		}
+
+		offset = index + 1
+	}
+
+	return query, first, offset, nil
+}
+
+func (c *searchConnection) end() int {
+
+	if c.offset >= len(c.result.POIs) {
+		return c.offset
+	}
+
+	end := c.offset + c.first
+
+	if end > len(c.result.POIs) {
+		end = len(c.result.POIs)
+	}
+
+	return end
+}
+
+const cursorPrefix = "offset:"
+
+func encodeCursor(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(index)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errors.New("invalid cursor")
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+
+	if err != nil || index < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+
+	return index, nil
+}
+
+func intArg(args map[string]interface{}, name string, defaultVal int) int {
+
+	if v, ok := args[name].(int); ok {
+		return v
+	}
+
+	return defaultVal
+}
+
+func statusField(t graphql.Output, get func(domain.ProviderStatus) interface{}) *graphql.Field {
+
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(domain.ProviderStatus)), nil
+		},
+	}
+}
+
+func poiField(t graphql.Output, get func(domain.POI) interface{}) *graphql.Field {
+
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(poiNode).poi), nil
+		},
+	}
+}
+
+func poiFields() graphql.Fields {
+
+	return graphql.Fields{
+		"id":        poiField(graphql.NewNonNull(graphql.String), func(p domain.POI) interface{} { return p.ID }),
+		"name":      poiField(graphql.NewNonNull(graphql.String), func(p domain.POI) interface{} { return p.Name }),
+		"latitude":  poiField(graphql.NewNonNull(graphql.Float), func(p domain.POI) interface{} { return p.Latitude }),
+		"longitude": poiField(graphql.NewNonNull(graphql.Float), func(p domain.POI) interface{} { return p.Longitude }),
+		"category":  poiField(graphql.NewNonNull(graphql.String), func(p domain.POI) interface{} { return p.Category }),
+		"source":    poiField(graphql.NewNonNull(graphql.String), func(p domain.POI) interface{} { return p.Source }),
+
+		"rating":               poiField(graphql.Float, func(p domain.POI) interface{} { return nullFloat(p.Rating) }),
+		"ratingCount":          poiField(graphql.Int, func(p domain.POI) interface{} { return nullInt(p.RatingCount) }),
+		"website":              poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Website) }),
+		"phone":                poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Phone) }),
+		"openingHours":         poiField(graphql.NewList(graphql.NewNonNull(graphql.String)), func(p domain.POI) interface{} { return p.OpeningHours }),
+		"cuisine":              poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Cuisine) }),
+		"priceLevel":           poiField(graphql.Int, func(p domain.POI) interface{} { return nullInt(p.PriceLevel) }),
+		"menuUrl":              poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.MenuURL) }),
+		"address":              poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Address) }),
+		"description":          poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Description) }),
+		"email":                poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.Email) }),
+		"openNow":              poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.OpenNow }),
+		"wheelchairAccessible": poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.WheelchairAccessible }),
+		"outdoorSeating":       poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.OutdoorSeating }),
+		"takeaway":             poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.Takeaway }),
+		"delivery":             poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.Delivery }),
+		"verified":             poiField(graphql.Boolean, func(p domain.POI) interface{} { return p.Verified }),
+		"popularity":           poiField(graphql.Float, func(p domain.POI) interface{} { return nullFloat(p.Popularity) }),
+		"canonicalId":          poiField(graphql.String, func(p domain.POI) interface{} { return nullString(p.CanonicalID) }),
+
+		// distanceMeters is computed only when requested.
+		"distanceMeters": &graphql.Field{
+			Type: graphql.Float,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				node := p.Source.(poiNode)
+				if node.query.BBox != nil || (node.query.Latitude == 0 && node.query.Longitude == 0) {
+					return nil, nil
+				}
+				return geo.DistanceMeters(node.query.Latitude, node.query.Longitude, node.poi.Latitude, node.poi.Longitude), nil
+			},
+		},
+	}
+}
+
+func nullString(s string) interface{} {
+
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func nullInt(v int) interface{} {
+
+	if v == 0 {
+		return nil
+	}
+
+	return v
+}
+
+func nullFloat(v float64) interface{} {
+
+	if v == 0 {
+		return nil
+	}
+
+	return v
+}
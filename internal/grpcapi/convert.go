@@ -0,0 +1,77 @@
+package grpcapi
+
+import (
+	poiv1 "github.com/hynek-systems/hynek-poi/api/poi/v1"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func toProtoResult(result domain.SearchResult) *poiv1.SearchResult {
+
+	out := &poiv1.SearchResult{
+		Pois:     toProtoPOIs(result.POIs),
+		Complete: result.Complete,
+		Cached:   result.Cached,
+	}
+
+	for _, s := range result.Providers {
+		out.Providers = append(out.Providers, toProtoStatus(s))
+	}
+
+	return out
+}
+
+func toProtoStatus(s domain.ProviderStatus) *poiv1.ProviderStatus {
+
+	return &poiv1.ProviderStatus{
+		Provider:    s.Provider,
+		Status:      s.Status,
+		ErrorClass:  s.ErrorClass,
+		LatencyMs:   s.LatencyMs,
+		ResultCount: int32(s.ResultCount),
+	}
+}
+
+func toProtoPOIs(pois []domain.POI) []*poiv1.POI {
+
+	out := make([]*poiv1.POI, 0, len(pois))
+
+	for _, p := range pois {
+		out = append(out, toProtoPOI(p))
+	}
+
+	return out
+}
+
+func toProtoPOI(p domain.POI) *poiv1.POI {
+
+	return &poiv1.POI{
+		Id:        p.ID,
+		Name:      p.Name,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Category:  p.Category,
+		Source:    p.Source,
+
+		Rating:       p.Rating,
+		RatingCount:  int32(p.RatingCount),
+		Website:      p.Website,
+		Phone:        p.Phone,
+		OpeningHours: p.OpeningHours,
+		Cuisine:      p.Cuisine,
+		PriceLevel:   int32(p.PriceLevel),
+		MenuUrl:      p.MenuURL,
+
+		Address:              p.Address,
+		Description:          p.Description,
+		Email:                p.Email,
+		OpenNow:              p.OpenNow,
+		WheelchairAccessible: p.WheelchairAccessible,
+		OutdoorSeating:       p.OutdoorSeating,
+		Takeaway:             p.Takeaway,
+		Delivery:             p.Delivery,
+		Verified:             p.Verified,
+		Popularity:           p.Popularity,
+
+		CanonicalId: p.CanonicalID,
+	}
+}
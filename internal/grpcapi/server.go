@@ -0,0 +1,143 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	poiv1 "github.com/hynek-systems/hynek-poi/api/poi/v1"
+	"github.com/hynek-systems/hynek-poi/internal/access"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// NewServer returns a gRPC server exposing service behind the same
+// metrics, API key and client rate limit policy as the HTTP API.
+func NewServer(service *Service, guard *access.Guard) *grpc.Server {
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			unaryMetrics,
+			unaryGuard(guard),
+		),
+		grpc.ChainStreamInterceptor(
+			streamMetrics,
+			streamGuard(guard),
+		),
+	)
+
+	poiv1.RegisterPOIServiceServer(server, service)
+
+	return server
+}
+
+func unaryMetrics(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	defer observe(info.FullMethod, time.Now())
+
+	return handler(ctx, req)
+}
+
+func streamMetrics(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	defer observe(info.FullMethod, time.Now())
+
+	return handler(srv, ss)
+}
+
+func observe(method string, start time.Time) {
+
+	metrics.RequestsTotal.WithLabelValues(method).Inc()
+
+	metrics.RequestDuration.
+		WithLabelValues(method).
+		Observe(time.Since(start).Seconds())
+}
+
+func unaryGuard(guard *access.Guard) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+
+		if err := check(ctx, guard); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func streamGuard(guard *access.Guard) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+
+		if err := check(ss.Context(), guard); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// check applies guard using the x-api-key or authorization metadata and
+// the peer address.
+func check(ctx context.Context, guard *access.Guard) error {
+
+	var apiKey string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+
+		if v := md.Get("x-api-key"); len(v) > 0 {
+			apiKey = v[0]
+		} else if v := md.Get("authorization"); len(v) > 0 {
+			apiKey = access.BearerToken(v[0])
+		}
+	}
+
+	var client string
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client = p.Addr.String()
+
+		if host, _, err := net.SplitHostPort(client); err == nil {
+			client = host
+		}
+	}
+
+	err := guard.Check("grpc", strings.TrimSpace(apiKey), client)
+
+	switch {
+
+	case errors.Is(err, access.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+
+	case errors.Is(err, access.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return nil
+}
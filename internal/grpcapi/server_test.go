@@ -0,0 +1,350 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	poiv1 "github.com/hynek-systems/hynek-poi/api/poi/v1"
+	"github.com/hynek-systems/hynek-poi/internal/access"
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+type fakeProvider struct {
+	name   string
+	pois   []domain.POI
+	delay  time.Duration
+	called chan error
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *fakeProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	select {
+
+	case <-time.After(p.delay):
+		return p.pois, nil
+
+	case <-ctx.Done():
+
+		if p.called != nil {
+			p.called <- ctx.Err()
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+func newTestClient(t *testing.T, guard *access.Guard, providers ...provider.Provider) (poiv1.POIServiceClient, *cache.POIIndex) {
+
+	index := cache.NewPOIIndex(time.Minute, 100)
+
+	cached := orchestrator.NewCached(
+		orchestrator.NewParallel(providers, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	)
+
+	cached.SetIndex(index)
+
+	service := NewService(func() orchestrator.StreamingOrchestrator { return cached }, index)
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := NewServer(service, guard)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return poiv1.NewPOIServiceClient(conn), index
+}
+
+func collect(t *testing.T, stream poiv1.POIService_SearchClient) ([]*poiv1.ProviderResult, *poiv1.SearchResult, error) {
+
+	var updates []*poiv1.ProviderResult
+
+	var final *poiv1.SearchResult
+
+	for {
+
+		resp, err := stream.Recv()
+
+		if err == io.EOF {
+			return updates, final, nil
+		}
+
+		if err != nil {
+			return updates, final, err
+		}
+
+		if u := resp.GetProviderResult(); u != nil {
+			updates = append(updates, u)
+		}
+
+		if r := resp.GetResult(); r != nil {
+			final = r
+		}
+	}
+}
+
+func TestSearch_StreamsProvidersThenResult(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0),
+		&fakeProvider{name: "fast", pois: []domain.POI{{ID: "1", Name: "Cafe", Source: "fast", Latitude: 1, Longitude: 1}}},
+		&fakeProvider{name: "slow", pois: []domain.POI{{ID: "2", Name: "Bar", Source: "slow", Latitude: 1.001, Longitude: 1.001}}, delay: 20 * time.Millisecond},
+	)
+
+	stream, err := client.Search(context.Background(), &poiv1.SearchRequest{Latitude: 1, Longitude: 1})
+
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	updates, final, err := collect(t, stream)
+
+	if err != nil {
+		t.Fatalf("Expected clean stream, got %v", err)
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 provider results, got %d", len(updates))
+	}
+
+	if updates[0].GetStatus().GetProvider() != "fast" {
+		t.Errorf("Expected fast provider first, got %s", updates[0].GetStatus().GetProvider())
+	}
+
+	if final == nil || len(final.GetPois()) != 2 || !final.GetComplete() {
+		t.Fatalf("Expected complete final result with 2 POIs, got %v", final)
+	}
+}
+
+func TestSearch_CacheHitSendsOnlyResult(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0),
+		&fakeProvider{name: "osm", pois: []domain.POI{{ID: "1", Source: "osm"}}},
+	)
+
+	req := &poiv1.SearchRequest{Latitude: 1, Longitude: 1}
+
+	for i := 0; i < 2; i++ {
+
+		stream, err := client.Search(context.Background(), req)
+
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+
+		updates, final, err := collect(t, stream)
+
+		if err != nil {
+			t.Fatalf("Expected clean stream, got %v", err)
+		}
+
+		if i == 1 && (len(updates) != 0 || !final.GetCached()) {
+			t.Errorf("Expected cached result without provider events, got %d events, cached=%v", len(updates), final.GetCached())
+		}
+	}
+}
+
+func TestSearch_DeadlinePropagatesToProvider(t *testing.T) {
+
+	called := make(chan error, 1)
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0),
+		&fakeProvider{name: "slow", delay: 5 * time.Second, called: called},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	stream, err := client.Search(ctx, &poiv1.SearchRequest{})
+
+	if err == nil {
+		_, _, err = collect(t, stream)
+	}
+
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	select {
+
+	case <-called:
+
+	case <-time.After(time.Second):
+		t.Fatal("Expected provider context to be cancelled")
+	}
+}
+
+func TestSearch_InvalidBBox(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0), &fakeProvider{name: "osm"})
+
+	stream, err := client.Search(context.Background(), &poiv1.SearchRequest{
+		Bbox: &poiv1.BBox{MinLat: 2, MaxLat: 1},
+	})
+
+	if err == nil {
+		_, _, err = collect(t, stream)
+	}
+
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestSearch_OutOfBounds(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0), &fakeProvider{name: "osm"})
+
+	for _, req := range []*poiv1.SearchRequest{
+		{Latitude: 59.3, Longitude: 18.0, Radius: domain.MaxRadius + 1},
+		{Latitude: 59.3, Longitude: 18.0, Limit: domain.MaxLimit + 1},
+		{Bbox: &poiv1.BBox{MinLat: -80, MinLng: -170, MaxLat: 80, MaxLng: 170}},
+	} {
+
+		stream, err := client.Search(context.Background(), req)
+
+		if err == nil {
+			_, _, err = collect(t, stream)
+		}
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+func TestGetPOI(t *testing.T) {
+
+	client, index := newTestClient(t, access.NewGuard(nil, 0, 0), &fakeProvider{name: "osm"})
+
+	_, err := client.GetPOI(context.Background(), &poiv1.GetPOIRequest{Source: "osm", Id: "1"})
+
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+
+	index.Add([]domain.POI{{ID: "1", Source: "osm", Name: "Cafe"}})
+
+	poi, err := client.GetPOI(context.Background(), &poiv1.GetPOIRequest{Source: "osm", Id: "1"})
+
+	if err != nil || poi.GetName() != "Cafe" {
+		t.Errorf("Expected Cafe, got %v, %v", poi, err)
+	}
+}
+
+func TestListCategories(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard(nil, 0, 0), &fakeProvider{name: "osm"})
+
+	resp, err := client.ListCategories(context.Background(), &poiv1.ListCategoriesRequest{})
+
+	if err != nil {
+		t.Fatalf("ListCategories: %v", err)
+	}
+
+	if len(resp.GetCategories()) != len(provider.Categories()) {
+		t.Errorf("Expected %d categories, got %d", len(provider.Categories()), len(resp.GetCategories()))
+	}
+}
+
+func TestGuard_Interceptors(t *testing.T) {
+
+	client, _ := newTestClient(t, access.NewGuard([]string{"secret"}, 0, 0), &fakeProvider{name: "osm"})
+
+	_, err := client.ListCategories(context.Background(), &poiv1.ListCategoriesRequest{})
+
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected Unauthenticated, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+
+	if _, err := client.ListCategories(ctx, &poiv1.ListCategoriesRequest{}); err != nil {
+		t.Errorf("Expected authorized call, got %v", err)
+	}
+
+	stream, err := client.Search(context.Background(), &poiv1.SearchRequest{})
+
+	if err == nil {
+		_, _, err = collect(t, stream)
+	}
+
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated on stream, got %v", err)
+	}
+}
+
+// curated hides "b", renames "a" and injects a custom POI.
+type curated struct{}
+
+func (curated) ApplyOverrides(query domain.SearchQuery, pois []domain.POI) []domain.POI {
+
+	var out []domain.POI
+
+	for _, poi := range pois {
+
+		switch poi.ID {
+
+		case "a":
+			poi.Name = "Renamed"
+
+		case "b":
+			continue
+		}
+
+		out = append(out, poi)
+	}
+
+	return append(out, domain.POI{ID: "injected", Source: "custom"})
+}
+
+func TestOverridden(t *testing.T) {
+
+	pois := overridden(curated{}, domain.SearchQuery{}, []domain.POI{
+		{ID: "a", Source: "osm", Name: "Original"},
+		{ID: "b", Source: "osm"},
+	})
+
+	if len(pois) != 1 || pois[0].ID != "a" || pois[0].Name != "Renamed" {
+		t.Errorf("Expected only the patched POI, got %+v", pois)
+	}
+}
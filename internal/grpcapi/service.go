@@ -0,0 +1,194 @@
+// Package grpcapi serves the gRPC API defined in api/poi/v1.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	poiv1 "github.com/hynek-systems/hynek-poi/api/poi/v1"
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+const (
+	defaultRadius = 1000
+	defaultLimit  = 50
+)
+
+// overrider is implemented by orchestrators with curated overrides, such
+// as orchestrator.CachedOrchestrator.
+type overrider interface {
+	ApplyOverrides(query domain.SearchQuery, pois []domain.POI) []domain.POI
+}
+
+type Service struct {
+	poiv1.UnimplementedPOIServiceServer
+
+	orch  func() orchestrator.StreamingOrchestrator
+	index *cache.POIIndex
+}
+
+// NewService builds the POIService implementation. orch is called per
+// search so config reloads are picked up; index backs GetPOI.
+func NewService(orch func() orchestrator.StreamingOrchestrator, index *cache.POIIndex) *Service {
+
+	return &Service{
+		orch:  orch,
+		index: index,
+	}
+}
+
+func (s *Service) Search(req *poiv1.SearchRequest, stream poiv1.POIService_SearchServer) error {
+
+	query, err := searchQuery(req)
+
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+
+	pipeline := s.orch()
+
+	// a failed send means the client is gone, which also cancels ctx and
+	// with it the search, so the first error is all that matters
+	var sendErr error
+
+	observe := func(update orchestrator.ProviderUpdate) {
+
+		if sendErr != nil {
+			return
+		}
+
+		pois := update.POIs
+
+		if o, ok := pipeline.(overrider); ok {
+			pois = overridden(o, query, pois)
+		}
+
+		sendErr = stream.Send(&poiv1.SearchResponse{
+			Event: &poiv1.SearchResponse_ProviderResult{
+				ProviderResult: &poiv1.ProviderResult{
+					Status: toProtoStatus(update.Status),
+					Pois:   toProtoPOIs(pois),
+				},
+			},
+		})
+	}
+
+	result, err := pipeline.SearchStream(ctx, query, observe)
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+
+	if err != nil {
+		return searchError(err)
+	}
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	return stream.Send(&poiv1.SearchResponse{
+		Event: &poiv1.SearchResponse_Result{
+			Result: toProtoResult(result),
+		},
+	})
+}
+
+// overridden applies o's overrides to one provider's POIs, as stream
+// snapshots do. POIs the overrides inject are left to the final result
+// rather than repeated in every provider event.
+func overridden(o overrider, query domain.SearchQuery, pois []domain.POI) []domain.POI {
+
+	returned := make(map[string]bool, len(pois))
+
+	for _, poi := range pois {
+		returned[poi.Source+"/"+poi.ID] = true
+	}
+
+	var out []domain.POI
+
+	for _, poi := range o.ApplyOverrides(query, pois) {
+		if returned[poi.Source+"/"+poi.ID] {
+			out = append(out, poi)
+		}
+	}
+
+	return out
+}
+
+func (s *Service) GetPOI(ctx context.Context, req *poiv1.GetPOIRequest) (*poiv1.POI, error) {
+
+	if req.GetSource() == "" || req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "source and id are required")
+	}
+
+	poi, found := s.index.Get(req.GetSource(), req.GetId())
+
+	if !found {
+		return nil, status.Error(codes.NotFound, "poi not found")
+	}
+
+	return toProtoPOI(poi), nil
+}
+
+func (s *Service) ListCategories(ctx context.Context, req *poiv1.ListCategoriesRequest) (*poiv1.ListCategoriesResponse, error) {
+
+	return &poiv1.ListCategoriesResponse{Categories: provider.Categories()}, nil
+}
+
+// searchQuery reads req into a query within the bounds HTTP and GraphQL
+// apply too.
+func searchQuery(req *poiv1.SearchRequest) (domain.SearchQuery, error) {
+
+	query := domain.SearchQuery{
+		Latitude:   req.GetLatitude(),
+		Longitude:  req.GetLongitude(),
+		Radius:     int(req.GetRadius()),
+		Limit:      int(req.GetLimit()),
+		Categories: req.GetCategories(),
+	}
+
+	if b := req.GetBbox(); b != nil {
+		query.BBox = &domain.BBox{
+			MinLat: b.GetMinLat(),
+			MinLng: b.GetMinLng(),
+			MaxLat: b.GetMaxLat(),
+			MaxLng: b.GetMaxLng(),
+		}
+	}
+
+	if err := query.Validate(); err != nil {
+		return domain.SearchQuery{}, err
+	}
+
+	if query.Radius == 0 {
+		query.Radius = defaultRadius
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultLimit
+	}
+
+	if err := cache.CheckArea(query); err != nil {
+		return domain.SearchQuery{}, err
+	}
+
+	return query, nil
+}
+
+func searchError(err error) error {
+
+	if errors.Is(err, orchestrator.ErrAllProvidersFailed) || errors.Is(err, orchestrator.ErrCallBudgetExhausted) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
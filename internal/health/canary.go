@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// RunCanaries searches the configured spot with every monitored provider
+// each interval until ctx is done. Canaries go through the providers'
+// full stack, rate limits and breakers included, and count towards
+// their success rate. Paid providers out of budget are not searched.
+func (m *Monitor) RunCanaries(ctx context.Context, cfg config.CanaryConfig) {
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+
+		m.Canary(ctx, cfg)
+
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// Canary runs one round of synthetic searches, one per provider, and
+// waits for all of them.
+func (m *Monitor) Canary(ctx context.Context, cfg config.CanaryConfig) {
+
+	query := domain.SearchQuery{
+		Latitude:  cfg.Latitude,
+		Longitude: cfg.Longitude,
+		Radius:    cfg.Radius,
+		Limit:     10,
+	}
+
+	// a canary slower than the interval is as good as failed
+	ctx, cancel := context.WithTimeout(ctx, cfg.Interval)
+	defer cancel()
+
+	m.mu.Lock()
+	providers := m.providers
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	for _, rp := range providers {
+
+		wg.Add(1)
+
+		go func(p provider.Provider) {
+
+			defer wg.Done()
+
+			start := time.Now()
+
+			if m.costs != nil {
+
+				if reason := m.costs.Exhausted(p.Name()); reason != "" {
+
+					metrics.CanaryChecks.WithLabelValues(p.Name(), "skipped").Inc()
+					m.setCanary(p.Name(), CanaryResult{Status: "skipped", At: start})
+
+					return
+				}
+			}
+
+			pois, err := provider.SearchWithContext(ctx, p, query)
+
+			elapsed := time.Since(start)
+
+			status := domain.ProviderStatus{
+				Provider:    p.Name(),
+				Status:      domain.ProviderStatusOK,
+				LatencyMs:   elapsed.Milliseconds(),
+				ResultCount: len(pois),
+			}
+
+			result := "ok"
+
+			switch {
+
+			case errors.Is(err, circuitbreaker.ErrCircuitOpen), errors.Is(err, provider.ErrRateLimited):
+				// not sent; the provider was not asked
+				result = "skipped"
+				status.Status = domain.ProviderStatusSkipped
+
+			case errors.Is(err, context.Canceled):
+				return
+
+			case errors.Is(err, provider.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+				result = "failed"
+				status.Status = domain.ProviderStatusTimeout
+
+			case err != nil:
+				result = "failed"
+				status.Status = domain.ProviderStatusError
+				status.ErrorClass = "upstream"
+
+			case len(pois) == 0:
+				result = "empty"
+			}
+
+			// the raw error may carry upstream URLs and API keys
+			if result == "failed" {
+				slog.Warn("canary failed", "provider", p.Name(), "status", status.Status, "error_class", status.ErrorClass)
+			}
+
+			metrics.CanaryChecks.WithLabelValues(p.Name(), result).Inc()
+
+			m.Observe(status)
+			m.setCanary(p.Name(), CanaryResult{Status: result, Results: len(pois), LatencyMs: status.LatencyMs, At: start})
+
+		}(rp.Provider)
+	}
+
+	wg.Wait()
+}
+
+func (m *Monitor) setCanary(name string, result CanaryResult) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.history[name]; ok {
+		h.canary = &result
+	}
+}
@@ -2,19 +2,33 @@ package health
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Checker struct {
-	Redis *redis.Client
+	Redis redis.UniversalClient
+
+	// RedisRequired fails readiness while Redis is unreachable. Without
+	// it the instance stays ready and reports itself degraded, serving
+	// from the in-memory cache and the providers.
+	RedisRequired bool
+
+	// Monitor, when set, reports provider health on /v1/status, and
+	// Readiness decides whether it affects /ready: none, any (at least
+	// one provider healthy) or all.
+	Monitor   *Monitor
+	Readiness string
 }
 
-func New(redis *redis.Client) *Checker {
+func New(redis redis.UniversalClient, redisRequired bool) *Checker {
 	return &Checker{
-		Redis: redis,
+		Redis:         redis,
+		RedisRequired: redisRequired,
 	}
 }
 
@@ -25,19 +39,91 @@ func (c *Checker) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("OK"))
 }
 
+// Status is the body of /v1/status.
+type Status struct {
+	// Status is ready, degraded (ready, but Redis is unavailable or a
+	// provider unhealthy) or unready, with the reason in Reason.
+	Status    string           `json:"status"`
+	Reason    string           `json:"reason,omitempty"`
+	Redis     string           `json:"redis"`
+	Providers []ProviderHealth `json:"providers"`
+}
+
 // Readiness probe
 func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	status := c.check(r.Context())
+
+	switch status.Status {
+
+	case "unready":
+		http.Error(w, status.Reason, http.StatusServiceUnavailable)
+
+	case "degraded":
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("DEGRADED: " + status.Reason))
+
+	default:
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("READY"))
+	}
+}
+
+// StatusHandler serves /v1/status: readiness with its reason, Redis and
+// the health of every provider. It answers 200 whatever the status; load
+// balancers should probe /ready.
+func (c *Checker) StatusHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.check(r.Context()))
+}
+
+func (c *Checker) check(ctx context.Context) Status {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	// Check Redis
-	if err := c.Redis.Ping(ctx).Err(); err != nil {
+	status := Status{
+		Status:    "ready",
+		Redis:     "ok",
+		Providers: []ProviderHealth{},
+	}
 
-		http.Error(w, "Redis not ready", http.StatusServiceUnavailable)
-		return
+	if c.Monitor != nil {
+		status.Providers = c.Monitor.Providers()
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("READY"))
+	var unhealthy []string
+
+	for _, p := range status.Providers {
+		if !p.Healthy {
+			unhealthy = append(unhealthy, p.Provider)
+		}
+	}
+
+	redisDown := c.Redis.Ping(ctx).Err() != nil
+
+	if redisDown {
+		status.Redis = "unavailable"
+	}
+
+	switch {
+
+	case redisDown && c.RedisRequired:
+		status.Status, status.Reason = "unready", "Redis not ready"
+
+	case c.Readiness == "any" && len(unhealthy) == len(status.Providers) && len(unhealthy) > 0:
+		status.Status, status.Reason = "unready", "no provider healthy"
+
+	case c.Readiness == "all" && len(unhealthy) > 0:
+		status.Status, status.Reason = "unready", "providers unhealthy: "+strings.Join(unhealthy, ",")
+
+	case redisDown:
+		status.Status, status.Reason = "degraded", "redis unavailable"
+
+	case len(unhealthy) > 0:
+		status.Status, status.Reason = "degraded", "providers unhealthy: "+strings.Join(unhealthy, ",")
+	}
+
+	return status
 }
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+	"github.com/redis/go-redis/v9"
+)
+
+func downRedis(t *testing.T) redis.UniversalClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestReadyHandler_RedisRequired(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	New(downRedis(t), true).ReadyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", rec.Code)
+	}
+}
+
+func TestReadyHandler_RedisOptionalDegrades(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	New(downRedis(t), false).ReadyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rec.Code)
+	}
+
+	if rec.Body.String() != "DEGRADED: redis unavailable" {
+		t.Errorf("Expected degraded body, got %q", rec.Body.String())
+	}
+}
+
+func TestReadyHandler_ProviderPolicy(t *testing.T) {
+
+	healthy := provider.RegisteredProvider{Provider: stubProvider{name: "osm"}}
+	failing := provider.RegisteredProvider{Provider: stubProvider{name: "google"}}
+
+	tests := []struct {
+		name      string
+		readiness string
+		providers []provider.RegisteredProvider
+		want      int
+	}{
+		{"none ignores providers", "none", []provider.RegisteredProvider{failing}, http.StatusOK},
+		{"any with one healthy", "any", []provider.RegisteredProvider{healthy, failing}, http.StatusOK},
+		{"any with none healthy", "any", []provider.RegisteredProvider{failing}, http.StatusServiceUnavailable},
+		{"all with one unhealthy", "all", []provider.RegisteredProvider{healthy, failing}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			checker := New(downRedis(t), false)
+			checker.Monitor = newMonitor(tt.providers...)
+			checker.Readiness = tt.readiness
+
+			observe(checker.Monitor, "google", 0, minJudgedCalls)
+
+			rec := httptest.NewRecorder()
+
+			checker.ReadyHandler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestStatusHandler_ReportsProviders(t *testing.T) {
+
+	checker := New(downRedis(t), false)
+	checker.Monitor = newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}})
+
+	observe(checker.Monitor, "osm", 1, 0)
+
+	rec := httptest.NewRecorder()
+
+	checker.StatusHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/status", nil))
+
+	var status Status
+
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Status != "degraded" || status.Redis != "unavailable" {
+		t.Errorf("Expected degraded without Redis, got %s, redis %s", status.Status, status.Redis)
+	}
+
+	if len(status.Providers) != 1 || status.Providers[0].Calls != 1 || !status.Providers[0].Healthy {
+		t.Errorf("Expected one healthy provider, got %+v", status.Providers)
+	}
+}
@@ -0,0 +1,261 @@
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// minJudgedCalls is the number of calls needed before a provider's
+// success rate counts against it, so one early failure does not mark it
+// unhealthy.
+const minJudgedCalls = 5
+
+// ProviderHealth is a provider's entry on /v1/status.
+type ProviderHealth struct {
+	Provider string `json:"provider"`
+	Healthy  bool   `json:"healthy"`
+
+	// Breaker is closed, open or half_open.
+	Breaker string `json:"breaker"`
+
+	// Calls is the number of recent calls SuccessRate and LatencyMs are
+	// computed over; SuccessRate is omitted while Calls is zero.
+	Calls       int      `json:"calls"`
+	SuccessRate *float64 `json:"success_rate,omitempty"`
+	LatencyMs   int64    `json:"latency_ms"`
+
+	LastSuccess *time.Time     `json:"last_success,omitempty"`
+	LastError   *ProviderError `json:"last_error,omitempty"`
+	Canary      *CanaryResult  `json:"canary,omitempty"`
+}
+
+// ProviderError is the latest failed call of a provider. Like search
+// results, it carries the status and error class but not the raw
+// message, which may contain upstream URLs and API keys.
+type ProviderError struct {
+	Status     string    `json:"status"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// CanaryResult is the latest synthetic search of a provider.
+type CanaryResult struct {
+	Status    string    `json:"status"`
+	Results   int       `json:"results"`
+	LatencyMs int64     `json:"latency_ms"`
+	At        time.Time `json:"at"`
+}
+
+type call struct {
+	ok      bool
+	latency time.Duration
+}
+
+// history is what a Monitor remembers of one provider.
+type history struct {
+	calls []call
+	next  int
+
+	lastSuccess time.Time
+	lastError   *ProviderError
+	canary      *CanaryResult
+}
+
+func (h *history) record(c call, window int) {
+
+	if len(h.calls) < window {
+		h.calls = append(h.calls, c)
+		return
+	}
+
+	h.calls[h.next] = c
+	h.next = (h.next + 1) % window
+}
+
+func (h *history) successRate() float64 {
+
+	if len(h.calls) == 0 {
+		return 0
+	}
+
+	ok := 0
+
+	for _, c := range h.calls {
+		if c.ok {
+			ok++
+		}
+	}
+
+	return float64(ok) / float64(len(h.calls))
+}
+
+// latency is the mean latency of the recent successful calls.
+func (h *history) latency() time.Duration {
+
+	var total time.Duration
+
+	ok := 0
+
+	for _, c := range h.calls {
+		if c.ok {
+			total += c.latency
+			ok++
+		}
+	}
+
+	if ok == 0 {
+		return 0
+	}
+
+	return total / time.Duration(ok)
+}
+
+// Monitor tracks the health of the active providers from the outcomes of
+// their calls, searches and canaries alike.
+type Monitor struct {
+	mu sync.Mutex
+
+	window  int
+	minRate float64
+
+	providers []provider.RegisteredProvider
+	history   map[string]*history
+
+	costs orchestrator.CostGuard
+}
+
+func NewMonitor(cfg config.HealthConfig) *Monitor {
+
+	return &Monitor{
+		window:  cfg.Window,
+		minRate: cfg.MinSuccessRate,
+		history: map[string]*history{},
+	}
+}
+
+// SetCosts skips the canaries of providers costs reports exhausted, so
+// synthetic searches do not spend past a budget or an upstream quota.
+// It must be called before canaries run.
+func (m *Monitor) SetCosts(costs orchestrator.CostGuard) {
+	m.costs = costs
+}
+
+// SetProviders replaces the monitored providers, e.g. after a config
+// reload. History is kept for providers that remain.
+func (m *Monitor) SetProviders(providers []provider.RegisteredProvider) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := map[string]*history{}
+
+	for _, rp := range providers {
+
+		name := rp.Provider.Name()
+
+		if h, ok := m.history[name]; ok {
+			kept[name] = h
+		} else {
+			kept[name] = &history{}
+		}
+	}
+
+	m.providers = providers
+	m.history = kept
+}
+
+// Observe records the outcome of one provider call. Calls the breaker or
+// the rate limit rejected, the call budget skipped or the caller
+// cancelled say nothing about the provider and are not counted.
+// Providers not monitored, such as those removed by a reload, are
+// ignored.
+func (m *Monitor) Observe(status domain.ProviderStatus) {
+
+	switch {
+
+	case status.Status == domain.ProviderStatusSkipped,
+		status.Status == domain.ProviderStatusCircuitOpen,
+		status.ErrorClass == "rate_limited",
+		status.ErrorClass == "canceled":
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.history[status.Provider]
+
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+
+	success := status.Status == domain.ProviderStatusOK
+
+	h.record(call{ok: success, latency: time.Duration(status.LatencyMs) * time.Millisecond}, m.window)
+
+	if success {
+		h.lastSuccess = now
+	} else {
+		h.lastError = &ProviderError{Status: status.Status, ErrorClass: status.ErrorClass, At: now}
+	}
+
+	metrics.ProviderSuccessRate.WithLabelValues(status.Provider).Set(h.successRate())
+}
+
+// Providers reports the health of every monitored provider, in the
+// order they were set.
+func (m *Monitor) Providers() []ProviderHealth {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := make([]ProviderHealth, 0, len(m.providers))
+
+	for _, rp := range m.providers {
+
+		name := rp.Provider.Name()
+		h := m.history[name]
+
+		state := circuitbreaker.StateClosed
+
+		if rp.Breaker != nil {
+			state = rp.Breaker.State()
+		}
+
+		entry := ProviderHealth{
+			Provider:  name,
+			Breaker:   state.String(),
+			Calls:     len(h.calls),
+			LatencyMs: h.latency().Milliseconds(),
+			LastError: h.lastError,
+			Canary:    h.canary,
+		}
+
+		rate := h.successRate()
+
+		if entry.Calls > 0 {
+			entry.SuccessRate = &rate
+		}
+
+		if !h.lastSuccess.IsZero() {
+			at := h.lastSuccess
+			entry.LastSuccess = &at
+		}
+
+		entry.Healthy = state == circuitbreaker.StateClosed &&
+			(entry.Calls < min(minJudgedCalls, m.window) || rate >= m.minRate)
+
+		report = append(report, entry)
+	}
+
+	return report
+}
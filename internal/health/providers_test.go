@@ -0,0 +1,214 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+type stubProvider struct {
+	name string
+	pois []domain.POI
+	err  error
+}
+
+func (p stubProvider) Name() string {
+	return p.name
+}
+
+func (p stubProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	return p.pois, p.err
+}
+
+func newMonitor(providers ...provider.RegisteredProvider) *Monitor {
+
+	m := NewMonitor(config.HealthConfig{Window: 10, MinSuccessRate: 0.5})
+	m.SetProviders(providers)
+
+	return m
+}
+
+func observe(m *Monitor, name string, ok int, failed int) {
+
+	for range ok {
+		m.Observe(domain.ProviderStatus{Provider: name, Status: domain.ProviderStatusOK, LatencyMs: 100})
+	}
+
+	for range failed {
+		m.Observe(domain.ProviderStatus{Provider: name, Status: domain.ProviderStatusError, ErrorClass: "upstream"})
+	}
+}
+
+func TestMonitor_SuccessRate(t *testing.T) {
+
+	m := newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}})
+
+	observe(m, "osm", 3, 1)
+
+	got := m.Providers()[0]
+
+	if got.Calls != 4 || got.SuccessRate == nil || *got.SuccessRate != 0.75 {
+		t.Fatalf("Expected 3 of 4 calls to succeed, got %d calls at %v", got.Calls, got.SuccessRate)
+	}
+
+	if got.LatencyMs != 100 {
+		t.Errorf("Expected the mean latency of successful calls, got %d", got.LatencyMs)
+	}
+
+	if got.LastError == nil || got.LastError.ErrorClass != "upstream" || got.LastSuccess == nil {
+		t.Errorf("Expected the last error and success, got %+v %v", got.LastError, got.LastSuccess)
+	}
+
+	if !got.Healthy {
+		t.Error("Expected a provider above the minimum success rate to be healthy")
+	}
+}
+
+func TestMonitor_WindowForgetsOldCalls(t *testing.T) {
+
+	m := newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}})
+
+	observe(m, "osm", 0, 10)
+	observe(m, "osm", 10, 0)
+
+	got := m.Providers()[0]
+
+	if got.Calls != 10 || *got.SuccessRate != 1 {
+		t.Errorf("Expected only the last 10 calls, got %d calls at %v", got.Calls, *got.SuccessRate)
+	}
+}
+
+func TestMonitor_UnhealthyBelowMinimumRate(t *testing.T) {
+
+	m := newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}})
+
+	observe(m, "osm", 0, minJudgedCalls-1)
+
+	if !m.Providers()[0].Healthy {
+		t.Error("Expected too few calls not to be judged")
+	}
+
+	observe(m, "osm", 0, 1)
+
+	if m.Providers()[0].Healthy {
+		t.Error("Expected a failing provider to be unhealthy")
+	}
+}
+
+func TestMonitor_UnhealthyWhileBreakerOpen(t *testing.T) {
+
+	cb := circuitbreaker.New("osm", circuitbreaker.Config{Window: time.Minute, FailureRate: 0.5, OpenTimeout: time.Minute})
+
+	done, _ := cb.Allow()
+	done(circuitbreaker.Failure, time.Millisecond)
+
+	m := newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}, Breaker: cb})
+
+	got := m.Providers()[0]
+
+	if got.Breaker != "open" || got.Healthy {
+		t.Errorf("Expected an open breaker to be unhealthy, got %s healthy=%v", got.Breaker, got.Healthy)
+	}
+}
+
+func TestMonitor_IgnoresCallsNotMade(t *testing.T) {
+
+	m := newMonitor(provider.RegisteredProvider{Provider: stubProvider{name: "osm"}})
+
+	m.Observe(domain.ProviderStatus{Provider: "osm", Status: domain.ProviderStatusCircuitOpen})
+	m.Observe(domain.ProviderStatus{Provider: "osm", Status: domain.ProviderStatusSkipped})
+	m.Observe(domain.ProviderStatus{Provider: "osm", Status: domain.ProviderStatusError, ErrorClass: "canceled"})
+	m.Observe(domain.ProviderStatus{Provider: "osm", Status: domain.ProviderStatusError, ErrorClass: "rate_limited"})
+	m.Observe(domain.ProviderStatus{Provider: "removed", Status: domain.ProviderStatusOK})
+
+	if got := m.Providers(); len(got) != 1 || got[0].Calls != 0 || got[0].SuccessRate != nil {
+		t.Errorf("Expected no calls counted, got %+v", got)
+	}
+}
+
+func TestMonitor_SetProvidersKeepsHistory(t *testing.T) {
+
+	m := newMonitor(
+		provider.RegisteredProvider{Provider: stubProvider{name: "osm"}},
+		provider.RegisteredProvider{Provider: stubProvider{name: "google"}},
+	)
+
+	observe(m, "osm", 2, 0)
+	observe(m, "google", 2, 0)
+
+	m.SetProviders([]provider.RegisteredProvider{{Provider: stubProvider{name: "osm"}}})
+
+	got := m.Providers()
+
+	if len(got) != 1 || got[0].Provider != "osm" || got[0].Calls != 2 {
+		t.Errorf("Expected osm with its history only, got %+v", got)
+	}
+}
+
+func TestMonitor_Canary(t *testing.T) {
+
+	m := newMonitor(
+		provider.RegisteredProvider{Provider: stubProvider{name: "osm", pois: []domain.POI{{ID: "1"}}}},
+		provider.RegisteredProvider{Provider: stubProvider{name: "google", err: errors.New("boom")}},
+		provider.RegisteredProvider{Provider: stubProvider{name: "foursquare", err: circuitbreaker.ErrCircuitOpen}},
+	)
+
+	m.Canary(context.Background(), config.CanaryConfig{Interval: time.Second, Latitude: 50.0875, Longitude: 14.4213, Radius: 500})
+
+	want := map[string]struct {
+		canary string
+		calls  int
+	}{
+		"osm":        {"ok", 1},
+		"google":     {"failed", 1},
+		"foursquare": {"skipped", 0},
+	}
+
+	for _, got := range m.Providers() {
+
+		w := want[got.Provider]
+
+		if got.Canary == nil || got.Canary.Status != w.canary {
+			t.Errorf("Expected %s canary %s, got %+v", got.Provider, w.canary, got.Canary)
+		}
+
+		if got.Calls != w.calls {
+			t.Errorf("Expected %s canary to count %d calls, got %d", got.Provider, w.calls, got.Calls)
+		}
+	}
+}
+
+type exhaustedCosts map[string]string
+
+func (c exhaustedCosts) Exhausted(provider string) string {
+	return c[provider]
+}
+
+func TestMonitor_CanarySkipsExhaustedProviders(t *testing.T) {
+
+	m := newMonitor(
+		provider.RegisteredProvider{Provider: stubProvider{name: "osm", pois: []domain.POI{{ID: "1"}}}},
+		provider.RegisteredProvider{Provider: stubProvider{name: "google", err: errors.New("boom")}},
+	)
+
+	m.SetCosts(exhaustedCosts{"google": "daily_budget"})
+
+	m.Canary(context.Background(), config.CanaryConfig{Interval: time.Second, Latitude: 50.0875, Longitude: 14.4213, Radius: 500})
+
+	for _, got := range m.Providers() {
+
+		if got.Provider != "google" {
+			continue
+		}
+
+		if got.Canary == nil || got.Canary.Status != "skipped" || got.Calls != 0 {
+			t.Errorf("Expected google's canary skipped without a call, got %+v", got)
+		}
+	}
+}
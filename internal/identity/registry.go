@@ -0,0 +1,251 @@
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// Prefix starts every canonical ID, so they cannot be mistaken for
+// provider IDs.
+const Prefix = "hp_"
+
+// Registry mints canonical IDs for places and links every provider ID
+// deduplication matched to them. Links are never moved once made, so a
+// canonical ID stays valid for as long as the store keeps it. Known links
+// are cached locally up to maxCached refs.
+type Registry struct {
+	store Store
+
+	mu        sync.RWMutex
+	cached    map[Ref]string
+	maxCached int
+}
+
+func NewRegistry(store Store, maxCached int) *Registry {
+
+	return &Registry{
+		store:     store,
+		cached:    make(map[Ref]string),
+		maxCached: maxCached,
+	}
+}
+
+// Assign returns the first POI of each group, as dedupe.Group orders
+// them, with CanonicalID set, linking the group's other provider IDs to
+// it. A group takes the canonical ID of its kept POI if it has one, else
+// of the first match that does, else a new one. If the store fails the
+// POIs are returned without canonical IDs.
+func (r *Registry) Assign(ctx context.Context, groups [][]domain.POI) ([]domain.POI, error) {
+
+	kept := make([]domain.POI, len(groups))
+
+	for i, group := range groups {
+		kept[i] = group[0]
+	}
+
+	var refs []Ref
+
+	for _, group := range groups {
+		for _, poi := range group {
+			if linkable(poi) {
+				refs = append(refs, refOf(poi))
+			}
+		}
+	}
+
+	known, err := r.lookup(ctx, refs)
+
+	if err != nil {
+		metrics.IdentityAssignments.WithLabelValues("failed").Add(float64(len(groups)))
+		return kept, err
+	}
+
+	proposed := map[Ref]string{}
+
+	canonicals := make([]string, len(groups))
+
+	for i, group := range groups {
+
+		canonical := ""
+
+		for _, poi := range group {
+			if c, ok := known[refOf(poi)]; ok {
+				canonical = c
+				break
+			}
+		}
+
+		if canonical == "" {
+
+			for _, poi := range group {
+				if linkable(poi) {
+					canonical = Mint(refOf(poi))
+					break
+				}
+			}
+
+			// nothing to derive an ID from
+			if canonical == "" {
+				continue
+			}
+
+			metrics.IdentityAssignments.WithLabelValues("minted").Inc()
+
+		} else {
+			metrics.IdentityAssignments.WithLabelValues("known").Inc()
+		}
+
+		canonicals[i] = canonical
+
+		for _, poi := range group {
+			if _, ok := known[refOf(poi)]; !ok && linkable(poi) {
+				proposed[refOf(poi)] = canonical
+			}
+		}
+	}
+
+	if len(proposed) > 0 {
+
+		linked, err := r.store.Link(ctx, proposed)
+
+		if err != nil {
+			metrics.IdentityAssignments.WithLabelValues("failed").Add(float64(len(groups)))
+			return kept, err
+		}
+
+		r.remember(linked)
+
+		// a kept POI linked concurrently by another replica takes that
+		// replica's canonical ID
+		for i, group := range groups {
+			if c, ok := linked[refOf(group[0])]; ok {
+				canonicals[i] = c
+			}
+		}
+	}
+
+	for i := range kept {
+		kept[i].CanonicalID = canonicals[i]
+	}
+
+	return kept, nil
+}
+
+// Resolve returns the canonical ID of a provider ID, or of a canonical ID
+// itself, and every provider ID linked to it. found is false for IDs
+// never linked.
+func (r *Registry) Resolve(ctx context.Context, ref Ref) (canonical string, links []Ref, found bool, err error) {
+
+	canonical = ref.ID
+
+	if ref.Source != "" {
+
+		known, err := r.lookup(ctx, []Ref{ref})
+
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		if canonical, found = known[ref]; !found {
+			return "", nil, false, nil
+		}
+	}
+
+	links, err = r.store.Links(ctx, canonical)
+
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].String() < links[j].String() })
+
+	return canonical, links, len(links) > 0, nil
+}
+
+// lookup answers from the local cache and asks the store for the rest.
+func (r *Registry) lookup(ctx context.Context, refs []Ref) (map[Ref]string, error) {
+
+	known := make(map[Ref]string, len(refs))
+
+	var missing []Ref
+
+	r.mu.RLock()
+
+	for _, ref := range refs {
+		if c, ok := r.cached[ref]; ok {
+			known[ref] = c
+		} else {
+			missing = append(missing, ref)
+		}
+	}
+
+	r.mu.RUnlock()
+
+	if len(missing) == 0 {
+		return known, nil
+	}
+
+	stored, err := r.store.Lookup(ctx, missing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.remember(stored)
+
+	for ref, c := range stored {
+		known[ref] = c
+	}
+
+	return known, nil
+}
+
+func (r *Registry) remember(links map[Ref]string) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ref, c := range links {
+		r.cached[ref] = c
+	}
+
+	// links never change, so any entry is as good to drop as another
+	for ref := range r.cached {
+
+		if len(r.cached) <= r.maxCached {
+			break
+		}
+
+		delete(r.cached, ref)
+	}
+}
+
+// Mint derives a canonical ID from the provider ID that first named a
+// place. It is deterministic, so replicas minting for the same place at
+// once agree.
+func Mint(ref Ref) string {
+
+	sum := sha256.Sum256([]byte(ref.String()))
+
+	return Prefix + hex.EncodeToString(sum[:10])
+}
+
+// IsCanonical reports whether id looks like a canonical ID.
+func IsCanonical(id string) bool {
+	return strings.HasPrefix(id, Prefix)
+}
+
+func linkable(poi domain.POI) bool {
+	return poi.Source != "" && poi.ID != ""
+}
+
+func refOf(poi domain.POI) Ref {
+	return Ref{Source: poi.Source, ID: poi.ID}
+}
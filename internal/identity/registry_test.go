@@ -0,0 +1,185 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+type memoryStore struct {
+	xref  map[Ref]string
+	links map[string]map[Ref]bool
+	err   error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{xref: map[Ref]string{}, links: map[string]map[Ref]bool{}}
+}
+
+func (s *memoryStore) Lookup(ctx context.Context, refs []Ref) (map[Ref]string, error) {
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	found := map[Ref]string{}
+
+	for _, ref := range refs {
+		if c, ok := s.xref[ref]; ok {
+			found[ref] = c
+		}
+	}
+
+	return found, nil
+}
+
+func (s *memoryStore) Link(ctx context.Context, proposed map[Ref]string) (map[Ref]string, error) {
+
+	linked := map[Ref]string{}
+
+	for ref, c := range proposed {
+
+		if _, ok := s.xref[ref]; !ok {
+			s.xref[ref] = c
+		}
+
+		c = s.xref[ref]
+
+		if s.links[c] == nil {
+			s.links[c] = map[Ref]bool{}
+		}
+
+		s.links[c][ref] = true
+		linked[ref] = c
+	}
+
+	return linked, nil
+}
+
+func (s *memoryStore) Links(ctx context.Context, canonical string) ([]Ref, error) {
+
+	var refs []Ref
+
+	for ref := range s.links[canonical] {
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+var (
+	googleCafe = domain.POI{ID: "ChIJcafe", Name: "Cafe", Source: "google"}
+	osmCafe    = domain.POI{ID: "node/42", Name: "Cafe", Source: "osm"}
+)
+
+func TestRegistry_StableAcrossDedupeWinners(t *testing.T) {
+
+	ctx := context.Background()
+
+	registry := NewRegistry(newMemoryStore(), 100)
+
+	first, err := registry.Assign(ctx, [][]domain.POI{{googleCafe, osmCafe}})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	canonical := first[0].CanonicalID
+
+	if canonical != Mint(Ref{Source: "google", ID: "ChIJcafe"}) {
+		t.Errorf("Expected ID minted from the kept POI, got %q", canonical)
+	}
+
+	// provider ordering changed, so osm now wins dedupe
+	second, _ := registry.Assign(ctx, [][]domain.POI{{osmCafe, googleCafe}})
+
+	if second[0].ID != "node/42" || second[0].CanonicalID != canonical {
+		t.Errorf("Expected osm record with canonical %q, got %+v", canonical, second[0])
+	}
+}
+
+func TestRegistry_ResolvesLinkedIDs(t *testing.T) {
+
+	ctx := context.Background()
+
+	store := newMemoryStore()
+
+	NewRegistry(store, 100).Assign(ctx, [][]domain.POI{{googleCafe, osmCafe}})
+
+	// a fresh registry has nothing cached and reads the store
+	registry := NewRegistry(store, 100)
+
+	canonical, links, found, err := registry.Resolve(ctx, Ref{Source: "osm", ID: "node/42"})
+
+	if err != nil || !found {
+		t.Fatalf("Expected osm id to resolve, got found=%v err=%v", found, err)
+	}
+
+	if len(links) != 2 || links[0].Source != "google" || links[1].Source != "osm" {
+		t.Errorf("Expected google and osm links, got %+v", links)
+	}
+
+	if _, byCanonical, found, _ := registry.Resolve(ctx, Ref{ID: canonical}); !found || len(byCanonical) != 2 {
+		t.Errorf("Expected canonical id to list both links, got %+v", byCanonical)
+	}
+
+	if _, _, found, _ := registry.Resolve(ctx, Ref{Source: "foursquare", ID: "nope"}); found {
+		t.Error("Expected unknown id not to resolve")
+	}
+}
+
+func TestRegistry_KeepsExistingLinks(t *testing.T) {
+
+	ctx := context.Background()
+
+	registry := NewRegistry(newMemoryStore(), 100)
+
+	other := domain.POI{ID: "ChIJother", Name: "Cafe", Source: "google"}
+
+	registry.Assign(ctx, [][]domain.POI{{osmCafe}})
+	registry.Assign(ctx, [][]domain.POI{{other}})
+
+	// a later match between two places already known keeps both IDs
+	merged, _ := registry.Assign(ctx, [][]domain.POI{{other, osmCafe}})
+
+	if merged[0].CanonicalID != Mint(Ref{Source: "google", ID: "ChIJother"}) {
+		t.Errorf("Expected the kept POI's own canonical id, got %q", merged[0].CanonicalID)
+	}
+
+	alone, _ := registry.Assign(ctx, [][]domain.POI{{osmCafe}})
+
+	if alone[0].CanonicalID != Mint(Ref{Source: "osm", ID: "node/42"}) {
+		t.Errorf("Expected osm to keep its canonical id, got %q", alone[0].CanonicalID)
+	}
+}
+
+func TestRegistry_StoreFailureLeavesIDsEmpty(t *testing.T) {
+
+	store := newMemoryStore()
+	store.err = errors.New("redis down")
+
+	pois, err := NewRegistry(store, 100).Assign(context.Background(), [][]domain.POI{{googleCafe, osmCafe}})
+
+	if err == nil {
+		t.Error("Expected the store error")
+	}
+
+	if len(pois) != 1 || pois[0].ID != "ChIJcafe" || pois[0].CanonicalID != "" {
+		t.Errorf("Expected the kept POI without canonical id, got %+v", pois)
+	}
+}
+
+func TestRegistry_LocalCacheIsBounded(t *testing.T) {
+
+	registry := NewRegistry(newMemoryStore(), 2)
+
+	registry.Assign(context.Background(), [][]domain.POI{
+		{{ID: "1", Source: "osm"}}, {{ID: "2", Source: "osm"}}, {{ID: "3", Source: "osm"}},
+	})
+
+	if n := len(registry.cached); n > 2 {
+		t.Errorf("Expected at most 2 cached links, got %d", n)
+	}
+}
@@ -0,0 +1,134 @@
+package identity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Ref is one provider's ID for a place.
+type Ref struct {
+	Source string `json:"source"`
+	ID     string `json:"id"`
+}
+
+func (r Ref) String() string {
+	return r.Source + "/" + r.ID
+}
+
+func parseRef(s string) (Ref, bool) {
+
+	source, id, ok := strings.Cut(s, "/")
+
+	return Ref{Source: source, ID: id}, ok
+}
+
+// Store persists the cross-reference of provider IDs to canonical IDs,
+// shared by all replicas.
+type Store interface {
+	// Lookup returns the canonical IDs of the refs that have one.
+	Lookup(ctx context.Context, refs []Ref) (map[Ref]string, error)
+
+	// Link links each ref without a canonical ID to its proposed one and
+	// returns the canonical ID every ref ends up with. Refs linked
+	// concurrently keep the first link.
+	Link(ctx context.Context, proposed map[Ref]string) (map[Ref]string, error)
+
+	// Links lists the refs linked to canonical.
+	Links(ctx context.Context, canonical string) ([]Ref, error)
+}
+
+const (
+	xrefKey        = "hynek-poi:identity:xref"
+	linksKeyPrefix = "hynek-poi:identity:links:"
+)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Lookup(ctx context.Context, refs []Ref) (map[Ref]string, error) {
+
+	found := make(map[Ref]string, len(refs))
+
+	if len(refs) == 0 {
+		return found, nil
+	}
+
+	fields := make([]string, len(refs))
+
+	for i, ref := range refs {
+		fields[i] = ref.String()
+	}
+
+	values, err := s.client.HMGet(ctx, xrefKey, fields...).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range values {
+		if canonical, ok := v.(string); ok {
+			found[refs[i]] = canonical
+		}
+	}
+
+	return found, nil
+}
+
+func (s *RedisStore) Link(ctx context.Context, proposed map[Ref]string) (map[Ref]string, error) {
+
+	refs := make([]Ref, 0, len(proposed))
+
+	pipe := s.client.Pipeline()
+
+	for ref, canonical := range proposed {
+		refs = append(refs, ref)
+		pipe.HSetNX(ctx, xrefKey, ref.String(), canonical)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	// read back, since another replica may have linked a ref first
+	linked, err := s.Lookup(ctx, refs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pipe = s.client.Pipeline()
+
+	for ref, canonical := range linked {
+		pipe.SAdd(ctx, linksKeyPrefix+canonical, ref.String())
+	}
+
+	_, err = pipe.Exec(ctx)
+
+	return linked, err
+}
+
+func (s *RedisStore) Links(ctx context.Context, canonical string) ([]Ref, error) {
+
+	members, err := s.client.SMembers(ctx, linksKeyPrefix+canonical).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]Ref, 0, len(members))
+
+	for _, m := range members {
+		if ref, ok := parseRef(m); ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs, nil
+}
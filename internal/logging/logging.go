@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+var (
+	// level is shared by every handler Setup builds, so reloads can
+	// change it in place.
+	level slog.LevelVar
+
+	// sampling holds the request line settings, swapped on reload.
+	sampling atomic.Pointer[config.LogConfig]
+)
+
+// Setup installs a JSON or text logger writing to out as the slog and
+// log default, so remaining log.Printf calls come out structured too.
+// Records logged with a context carrying a request ID get a request_id
+// attribute.
+func Setup(cfg config.LogConfig, out io.Writer) error {
+
+	opts := &slog.HandlerOptions{Level: &level}
+
+	var handler slog.Handler
+
+	switch cfg.Format {
+
+	case "json":
+		handler = slog.NewJSONHandler(out, opts)
+
+	case "text":
+		handler = slog.NewTextHandler(out, opts)
+
+	default:
+		return fmt.Errorf("log format must be json or text, got %q", cfg.Format)
+	}
+
+	if err := Apply(cfg); err != nil {
+		return err
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+
+	return nil
+}
+
+// Apply changes the level and request line sampling of the installed
+// logger. The format is fixed by Setup.
+func Apply(cfg config.LogConfig) error {
+
+	var l slog.Level
+
+	if err := l.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("log level: %w", err)
+	}
+
+	level.Set(l)
+	sampling.Store(&cfg)
+
+	return nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying id, which every record logged
+// with it is tagged with.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or "".
+func RequestID(ctx context.Context) string {
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// contextHandler adds the request ID of the logging context to records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Milliseconds is d in milliseconds with microsecond precision, the unit
+// latencies are logged in.
+func Milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
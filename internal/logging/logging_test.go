@@ -0,0 +1,224 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+// capture installs a JSON logger writing to the returned buffer for the
+// duration of the test.
+func capture(t *testing.T, cfg config.LogConfig) *bytes.Buffer {
+
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	var out bytes.Buffer
+
+	if err := Setup(cfg, &out); err != nil {
+		t.Fatalf("Expected setup to succeed, got %v", err)
+	}
+
+	return &out
+}
+
+func lines(t *testing.T, out *bytes.Buffer) []map[string]any {
+
+	var records []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+
+		if line == "" {
+			continue
+		}
+
+		record := map[string]any{}
+
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON log line, got %q", line)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func serve(handler http.Handler, path, id string) *httptest.ResponseRecorder {
+
+	req := httptest.NewRequest("GET", path, nil)
+
+	if id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestMiddleware_CarriesRequestID(t *testing.T) {
+
+	out := capture(t, config.LogConfig{Level: "info", Format: "json", SampleRate: 1})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slog.InfoContext(r.Context(), "provider failed", "provider", "osm")
+	}))
+
+	rec := serve(handler, "/v1/search", "abc-123")
+
+	if got := rec.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("Expected the client request ID echoed, got %q", got)
+	}
+
+	records := lines(t, out)
+
+	if len(records) != 2 {
+		t.Fatalf("Expected a handler line and a request line, got %d", len(records))
+	}
+
+	for _, record := range records {
+		if record["request_id"] != "abc-123" {
+			t.Errorf("Expected request_id on %q, got %v", record["msg"], record["request_id"])
+		}
+	}
+}
+
+func TestMiddleware_ReplacesUnusableRequestID(t *testing.T) {
+
+	capture(t, config.LogConfig{Level: "info", Format: "json", SampleRate: 1})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, id := range []string{"", "has space", strings.Repeat("a", maxRequestID+1)} {
+
+		got := serve(handler, "/v1/search", id).Header().Get(RequestIDHeader)
+
+		if got == "" || got == id {
+			t.Errorf("Expected a generated request ID for %q, got %q", id, got)
+		}
+	}
+}
+
+func TestMiddleware_SummaryLine(t *testing.T) {
+
+	out := capture(t, config.LogConfig{Level: "info", Format: "json", SampleRate: 1})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Annotate(r.Context(), slog.String("cache", "hit"), slog.Int("results", 3))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	serve(handler, "/v1/search", "")
+
+	records := lines(t, out)
+
+	if len(records) != 1 {
+		t.Fatalf("Expected one request line, got %d", len(records))
+	}
+
+	line := records[0]
+
+	if line["msg"] != "request" || line["level"] != "INFO" {
+		t.Errorf("Expected an info request line, got %v %v", line["level"], line["msg"])
+	}
+
+	if line["status"] != float64(http.StatusAccepted) || line["path"] != "/v1/search" {
+		t.Errorf("Expected status and path, got %v %v", line["status"], line["path"])
+	}
+
+	if line["cache"] != "hit" || line["results"] != float64(3) {
+		t.Errorf("Expected the handler annotations, got %v %v", line["cache"], line["results"])
+	}
+}
+
+func TestMiddleware_Sampling(t *testing.T) {
+
+	out := capture(t, config.LogConfig{Level: "debug", Format: "json", SampleRate: 0})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+
+		case "/degraded":
+			Keep(r.Context())
+
+		case "/broken":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}), "/health")
+
+	for _, path := range []string{"/ok", "/degraded", "/broken", "/health"} {
+		serve(handler, path, "")
+	}
+
+	levels := map[string]any{}
+
+	for _, record := range lines(t, out) {
+		levels[record["path"].(string)] = record["level"]
+	}
+
+	if _, ok := levels["/ok"]; ok {
+		t.Error("Expected info lines to be sampled out")
+	}
+
+	if levels["/degraded"] != "WARN" {
+		t.Errorf("Expected kept requests at warn, got %v", levels["/degraded"])
+	}
+
+	if levels["/broken"] != "ERROR" {
+		t.Errorf("Expected server errors at error, got %v", levels["/broken"])
+	}
+
+	if levels["/health"] != "DEBUG" {
+		t.Errorf("Expected quiet paths at debug, got %v", levels["/health"])
+	}
+}
+
+func TestMiddleware_SlowRequestsAtWarn(t *testing.T) {
+
+	out := capture(t, config.LogConfig{Level: "info", Format: "json", SampleRate: 0, SlowRequest: time.Millisecond})
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+	}))
+
+	serve(handler, "/v1/search", "")
+
+	records := lines(t, out)
+
+	if len(records) != 1 || records[0]["level"] != "WARN" {
+		t.Errorf("Expected one warn line for a slow request, got %v", records)
+	}
+}
+
+func TestApply_ChangesLevel(t *testing.T) {
+
+	out := capture(t, config.LogConfig{Level: "info", Format: "json", SampleRate: 1})
+
+	slog.Debug("hidden")
+
+	if err := Apply(config.LogConfig{Level: "debug", Format: "json", SampleRate: 1}); err != nil {
+		t.Fatalf("Expected apply to succeed, got %v", err)
+	}
+
+	slog.Debug("shown")
+
+	records := lines(t, out)
+
+	if len(records) != 1 || records[0]["msg"] != "shown" {
+		t.Errorf("Expected only the debug line after apply, got %v", records)
+	}
+
+	if err := Apply(config.LogConfig{Level: "loud"}); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+}
@@ -0,0 +1,200 @@
+package logging
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader carries the request ID in from clients and proxies and
+// back out on every response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestID bounds request IDs taken from clients.
+const maxRequestID = 128
+
+// entry collects what handlers add to a request's log line.
+type entry struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+	keep  bool
+}
+
+type entryKey struct{}
+
+// Annotate adds attributes to the log line of the request ctx belongs
+// to. It does nothing outside Middleware.
+func Annotate(ctx context.Context, attrs ...slog.Attr) {
+
+	e, ok := ctx.Value(entryKey{}).(*entry)
+
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	e.attrs = append(e.attrs, attrs...)
+	e.mu.Unlock()
+}
+
+// Keep logs the line of the request ctx belongs to at warn level, past
+// sampling; handlers call it for degraded answers.
+func Keep(ctx context.Context) {
+
+	e, ok := ctx.Value(entryKey{}).(*entry)
+
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	e.keep = true
+	e.mu.Unlock()
+}
+
+// Middleware gives every request an ID, taken from X-Request-ID when the
+// client sent a usable one, and logs one line per request when it
+// completes: method, path, status, duration and whatever the handler
+// annotated. Server errors are logged at error level; kept and slow
+// requests at warn level; the rest at info level, sampled at the
+// configured rate. Requests to quiet paths, such as probes, are logged
+// at debug level.
+func Middleware(next http.Handler, quiet ...string) http.Handler {
+
+	quietPaths := map[string]bool{}
+
+	for _, path := range quiet {
+		quietPaths[path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		e := &entry{}
+
+		ctx := context.WithValue(WithRequestID(r.Context(), id), entryKey{}, e)
+
+		rw := &responseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+
+		elapsed := time.Since(start)
+
+		cfg := sampling.Load()
+
+		lvl := slog.LevelInfo
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		switch {
+
+		case quietPaths[r.URL.Path]:
+			lvl = slog.LevelDebug
+
+		case rw.status >= 500:
+			lvl = slog.LevelError
+
+		case e.keep || (cfg != nil && cfg.SlowRequest > 0 && elapsed >= cfg.SlowRequest):
+			lvl = slog.LevelWarn
+
+		case cfg != nil && cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate:
+			return
+		}
+
+		attrs := append([]slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rw.status),
+			slog.Float64("duration_ms", Milliseconds(elapsed)),
+			slog.Int("bytes", rw.bytes),
+		}, e.attrs...)
+
+		slog.LogAttrs(ctx, lvl, "request", attrs...)
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces, so
+// client input cannot break log lines or headers.
+func validRequestID(id string) bool {
+
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+
+	var b [8]byte
+
+	_, _ = crand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter notes the status and size of a response. It flushes
+// through, so streaming handlers keep working.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+
+	w.bytes += n
+
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -22,6 +22,14 @@ var (
 		[]string{"endpoint"},
 	)
 
+	AccessDenied = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_access_denied_total",
+			Help: "Requests rejected by auth or client rate limits",
+		},
+		[]string{"transport", "reason"},
+	)
+
 	CacheHits = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Name: "hynek_poi_cache_hits_total",
@@ -36,6 +44,116 @@ var (
 		},
 	)
 
+	CacheTiles = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_cache_tiles_total",
+			Help: "Cache tile lookups by result (hit, miss, negative)",
+		},
+		[]string{"result"},
+	)
+
+	CacheInvalidations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_cache_invalidations_total",
+			Help: "Cache invalidations applied, by origin (api, broadcast)",
+		},
+		[]string{"origin"},
+	)
+
+	RedisOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_redis_operations_total",
+			Help: "L2 cache operations by result (hit, miss, ok, error, bypassed, undecodable)",
+		},
+		[]string{"op", "result"},
+	)
+
+	CacheEntryBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hynek_poi_cache_entry_bytes",
+			Help:    "L2 cache entry size by codec, before (serialized) and after (stored) compression",
+			Buckets: prometheus.ExponentialBuckets(64, 4, 9),
+		},
+		[]string{"codec", "stage"},
+	)
+
+	RedisCircuitBreakerState = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hynek_poi_redis_circuit_breaker_state",
+			Help: "L2 cache circuit breaker state (0 closed, 1 open, 2 half-open)",
+		},
+	)
+
+	WarmingRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_cache_warming_runs_total",
+			Help: "Cache warming runs by outcome (completed, quiet_hours, locked, error)",
+		},
+		[]string{"outcome"},
+	)
+
+	WarmingTiles = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_cache_warming_tiles_total",
+			Help: "Tiles considered by cache warming, by result (warmed, fresh, failed, budget_exhausted)",
+		},
+		[]string{"result"},
+	)
+
+	IdentityAssignments = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_identity_assignments_total",
+			Help: "Canonical IDs given to deduplicated places, by result (known, minted, failed)",
+		},
+		[]string{"result"},
+	)
+
+	CaptureRecords = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_capture_records_total",
+			Help: "Sampled search requests handed to traffic capture, by result (written, dropped, failed)",
+		},
+		[]string{"result"},
+	)
+
+	ContractViolations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_contract_violations_total",
+			Help: "HTTP requests and responses not matching the OpenAPI document, by kind (request, response)",
+		},
+		[]string{"kind"},
+	)
+
+	OverridesApplied = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_overrides_applied_total",
+			Help: "Curated override rule applications, by action (hide, patch, pin, add)",
+		},
+		[]string{"action"},
+	)
+
+	StoreIngested = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_store_ingested_total",
+			Help: "POIs written to the persistent store, by result (stored, dropped, failed)",
+		},
+		[]string{"result"},
+	)
+
+	StoreRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hynek_poi_store_records",
+			Help: "Records in the persistent store as of the last prune",
+		},
+	)
+
+	StoreCompactions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_store_compactions_total",
+			Help: "Persistent store file compactions",
+		},
+	)
+
 	ProviderDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "hynek_poi_provider_duration_seconds",
@@ -52,14 +170,128 @@ var (
 		},
 		[]string{"provider"},
 	)
+
+	ProviderHedges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_provider_hedges_total",
+			Help: "Hedged provider requests by outcome (fired, won, budget_exhausted)",
+		},
+		[]string{"provider", "outcome"},
+	)
+
+	CircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hynek_poi_circuit_breaker_state",
+			Help: "Circuit breaker state per provider (0 closed, 1 open, 2 half-open)",
+		},
+		[]string{"provider"},
+	)
+
+	ProviderSuccessRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hynek_poi_provider_success_rate",
+			Help: "Share of each provider's recent calls that succeeded, over health.window calls",
+		},
+		[]string{"provider"},
+	)
+
+	CanaryChecks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_canary_checks_total",
+			Help: "Synthetic canary searches by provider and result (ok, empty, failed, skipped)",
+		},
+		[]string{"provider", "result"},
+	)
+
+	ProviderBilledCalls = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_provider_billed_calls_total",
+			Help: "Billable provider calls made by this instance, by SKU",
+		},
+		[]string{"provider", "sku"},
+	)
+
+	ProviderSpend = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_provider_spend_total",
+			Help: "Estimated provider spend by this instance, in costs.currency",
+		},
+		[]string{"provider"},
+	)
+
+	ProviderPeriodSpend = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hynek_poi_provider_period_spend",
+			Help: "Estimated provider spend across all instances in the current UTC day or month, in costs.currency",
+		},
+		[]string{"provider", "period"},
+	)
+
+	ProviderBudgetSkips = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_provider_budget_skips_total",
+			Help: "Provider calls skipped because a budget or quota was exhausted",
+		},
+		[]string{"provider", "reason"},
+	)
+
+	TierEscalations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_tier_escalations_total",
+			Help: "Tiered searches escalated to a tier by reason (results, completeness, category, no_answer, or latency_budget when it could not be reached in time)",
+		},
+		[]string{"tier", "reason"},
+	)
+
+	CircuitBreakerTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_circuit_breaker_transitions_total",
+			Help: "Circuit breaker state transitions",
+		},
+		[]string{"provider", "from", "to"},
+	)
+
+	ConfigReloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hynek_poi_config_reloads_total",
+			Help: "Config reload attempts by result",
+		},
+		[]string{"result"},
+	)
 )
 
 func Register() {
 
 	prometheus.MustRegister(RequestsTotal)
 	prometheus.MustRegister(RequestDuration)
+	prometheus.MustRegister(AccessDenied)
 	prometheus.MustRegister(CacheHits)
 	prometheus.MustRegister(CacheMisses)
+	prometheus.MustRegister(CacheTiles)
+	prometheus.MustRegister(CacheInvalidations)
+	prometheus.MustRegister(RedisOperations)
+	prometheus.MustRegister(RedisCircuitBreakerState)
+	prometheus.MustRegister(CacheEntryBytes)
+	prometheus.MustRegister(WarmingRuns)
+	prometheus.MustRegister(WarmingTiles)
+	prometheus.MustRegister(IdentityAssignments)
+	prometheus.MustRegister(OverridesApplied)
+	prometheus.MustRegister(CaptureRecords)
+	prometheus.MustRegister(ContractViolations)
+	prometheus.MustRegister(StoreIngested)
+	prometheus.MustRegister(StoreRecords)
+	prometheus.MustRegister(StoreCompactions)
 	prometheus.MustRegister(ProviderDuration)
 	prometheus.MustRegister(ProviderErrors)
+	prometheus.MustRegister(ProviderHedges)
+	prometheus.MustRegister(CircuitBreakerState)
+	prometheus.MustRegister(CircuitBreakerTransitions)
+	prometheus.MustRegister(ProviderSuccessRate)
+	prometheus.MustRegister(CanaryChecks)
+	prometheus.MustRegister(ProviderBilledCalls)
+	prometheus.MustRegister(ProviderSpend)
+	prometheus.MustRegister(ProviderPeriodSpend)
+	prometheus.MustRegister(ProviderBudgetSkips)
+	prometheus.MustRegister(TierEscalations)
+	prometheus.MustRegister(ConfigReloads)
 }
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// BatchResult is the outcome of one query in a batch.
+type BatchResult struct {
+	Result domain.SearchResult
+	Err    error
+}
+
+// SearchBatch runs queries through o with at most concurrency searches in
+// flight and returns one result per query, in order. Identical queries
+// are searched once and share the result; overlapping ones share the
+// fetch of their common tiles when o is a CachedOrchestrator.
+func SearchBatch(
+	ctx context.Context,
+	o StreamingOrchestrator,
+	queries []domain.SearchQuery,
+	concurrency int,
+) []BatchResult {
+
+	results := make([]BatchResult, len(queries))
+
+	// group query positions by query, keeping first-seen order so earlier
+	// queries start first
+	var keys []string
+
+	groups := map[string][]int{}
+
+	for i, q := range queries {
+
+		key := batchKey(q)
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], i)
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for _, key := range keys {
+
+		positions := groups[key]
+
+		select {
+
+		case sem <- struct{}{}:
+
+		case <-ctx.Done():
+
+			for _, i := range positions {
+				results[i].Err = ctx.Err()
+			}
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(positions []int) {
+
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := o.SearchStream(ctx, queries[positions[0]], nil)
+
+			for _, i := range positions {
+				results[i] = BatchResult{Result: result, Err: err}
+			}
+
+		}(positions)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// batchKey identifies a query exactly, so only queries with the same
+// answer share one.
+func batchKey(query domain.SearchQuery) string {
+
+	categories := slices.Clone(query.Categories)
+	slices.Sort(categories)
+
+	bbox := "none"
+
+	if b := query.BBox; b != nil {
+		bbox = fmt.Sprintf("%v,%v,%v,%v", b.MinLat, b.MinLng, b.MaxLat, b.MaxLng)
+	}
+
+	return fmt.Sprintf(
+		"%v:%v:%s:%d:%d:%s",
+		query.Latitude,
+		query.Longitude,
+		bbox,
+		query.Radius,
+		query.Limit,
+		strings.Join(categories, ","),
+	)
+}
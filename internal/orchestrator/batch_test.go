@@ -0,0 +1,169 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+func countingProvider(name string, calls *atomic.Int32) *mockProvider {
+	return &mockProvider{
+		name: name,
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			calls.Add(1)
+			return []domain.POI{{ID: name, Name: name, Source: name, Latitude: q.Latitude, Longitude: q.Longitude}}, nil
+		},
+	}
+}
+
+func TestSearchBatch_CoalescesIdenticalQueries(t *testing.T) {
+
+	var calls atomic.Int32
+
+	o := NewCached(
+		NewParallel([]provider.Provider{countingProvider("osm", &calls)}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	)
+
+	queries := []domain.SearchQuery{
+		{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000},
+		{Latitude: 10, Longitude: 10, Radius: 1000},
+		{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000},
+	}
+
+	results := SearchBatch(context.Background(), o, queries, 2)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("Query %d: unexpected error %v", i, r.Err)
+		}
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 provider calls for 2 distinct queries, got %d", calls.Load())
+	}
+
+	if math.Abs(results[1].Result.POIs[0].Latitude-10) > 0.1 {
+		t.Errorf("Expected results in query order, got %+v", results[1].Result.POIs[0])
+	}
+}
+
+func TestSearchBatch_KeepsDistinctQueriesApart(t *testing.T) {
+
+	var calls atomic.Int32
+
+	echo := &mockProvider{
+		name: "osm",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			calls.Add(1)
+			return places(fmt.Sprintf("osm-%v", q.Latitude), q.Limit, false), nil
+		},
+	}
+
+	o := NewParallel([]provider.Provider{echo}, time.Second)
+
+	// nearby centers share a geohash cell but not an answer
+	queries := []domain.SearchQuery{
+		{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 1},
+		{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 3},
+		{Latitude: 59.3294, Longitude: 18.0687, Radius: 1000, Limit: 3},
+	}
+
+	results := SearchBatch(context.Background(), o, queries, 1)
+
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 provider calls for 3 distinct queries, got %d", calls.Load())
+	}
+
+	for i, want := range []int{1, 3, 3} {
+
+		if results[i].Err != nil {
+			t.Fatalf("Query %d: unexpected error %v", i, results[i].Err)
+		}
+
+		if got := len(results[i].Result.POIs); got != want {
+			t.Errorf("Query %d: expected %d POIs, got %d", i, want, got)
+		}
+	}
+}
+
+func TestSearchBatch_BudgetSkipsProviders(t *testing.T) {
+
+	var calls atomic.Int32
+
+	o := NewCached(
+		NewParallel([]provider.Provider{countingProvider("osm", &calls), countingProvider("google", &calls)}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	)
+
+	budget := NewCallBudget(3)
+
+	queries := []domain.SearchQuery{
+		{Latitude: 1, Longitude: 1},
+		{Latitude: 2, Longitude: 2},
+		{Latitude: 3, Longitude: 3},
+	}
+
+	results := SearchBatch(WithCallBudget(context.Background(), budget), o, queries, 1)
+
+	if calls.Load() != 3 || budget.Used() != 3 {
+		t.Fatalf("Expected 3 provider calls, got %d (used %d)", calls.Load(), budget.Used())
+	}
+
+	if results[0].Err != nil || !results[0].Result.Complete {
+		t.Errorf("Expected first query complete, got %+v", results[0])
+	}
+
+	if results[1].Err != nil || results[1].Result.Complete {
+		t.Errorf("Expected second query degraded, got %+v", results[1])
+	}
+
+	skipped := results[1].Result.DegradedProviders()
+
+	if len(skipped) != 1 || results[1].Result.Providers[1].Status != domain.ProviderStatusSkipped {
+		t.Errorf("Expected one skipped provider, got %+v", results[1].Result.Providers)
+	}
+
+	if !errors.Is(results[2].Err, ErrCallBudgetExhausted) {
+		t.Errorf("Expected ErrCallBudgetExhausted, got %v", results[2].Err)
+	}
+}
+
+func TestSearchBatch_CacheHitsAreFree(t *testing.T) {
+
+	var calls atomic.Int32
+
+	o := NewCached(
+		NewParallel([]provider.Provider{countingProvider("osm", &calls)}, time.Second),
+		cache.NewMemoryCache(),
+		time.Minute,
+	)
+
+	query := domain.SearchQuery{Latitude: 1, Longitude: 1}
+
+	if _, err := o.Search(query); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+
+	budget := NewCallBudget(0)
+
+	results := SearchBatch(WithCallBudget(context.Background(), budget), o, []domain.SearchQuery{query}, 1)
+
+	if results[0].Err != nil || !results[0].Result.Cached {
+		t.Errorf("Expected cached result despite empty budget, got %+v", results[0])
+	}
+}
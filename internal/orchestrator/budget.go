@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var ErrCallBudgetExhausted = errors.New("provider call budget exhausted")
+
+// CallBudget caps the provider calls made by all searches sharing it.
+// Cache hits cost nothing; each provider a search reaches costs one call.
+type CallBudget struct {
+	remaining atomic.Int64
+	used      atomic.Int64
+}
+
+func NewCallBudget(calls int) *CallBudget {
+
+	b := &CallBudget{}
+	b.remaining.Store(int64(calls))
+
+	return b
+}
+
+// Used reports how many calls have been spent.
+func (b *CallBudget) Used() int {
+
+	return int(b.used.Load())
+}
+
+func (b *CallBudget) take() bool {
+
+	if b.remaining.Add(-1) < 0 {
+		b.remaining.Add(1)
+		return false
+	}
+
+	b.used.Add(1)
+
+	return true
+}
+
+type callBudgetKey struct{}
+
+// WithCallBudget makes searches run with ctx draw from budget. Providers
+// the budget cannot cover are skipped.
+func WithCallBudget(ctx context.Context, budget *CallBudget) context.Context {
+
+	return context.WithValue(ctx, callBudgetKey{}, budget)
+}
+
+func callBudgetFrom(ctx context.Context) *CallBudget {
+
+	budget, _ := ctx.Value(callBudgetKey{}).(*CallBudget)
+
+	return budget
+}
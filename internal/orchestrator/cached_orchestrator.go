@@ -1,43 +1,515 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/hynek-systems/hynek-poi/internal/cache"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/ranking"
 )
 
 type CachedOrchestrator struct {
-	inner Orchestrator
-	cache cache.Cache
-	ttl   time.Duration
+	inner       Orchestrator
+	cache       cache.Cache
+	ttl         time.Duration
+	degradedTTL time.Duration
+	emptyTTL    time.Duration
+	negative    *failureBackoff
+	index       *cache.POIIndex
+	recorder    TileRecorder
+	overrides   Overrider
+
+	// fetching holds a channel per tile key a search is fetching, closed
+	// once it is done, so concurrent searches wait instead of fetching
+	// the same tile again.
+	mu       sync.Mutex
+	fetching map[string]chan struct{}
+}
+
+// TileRecorder is told which tiles every search covers, hit or miss.
+type TileRecorder interface {
+	RecordTiles(hashes []string, categories []string)
 }
 
+// Overrider applies curated changes to merged results before ranking.
+type Overrider interface {
+	Apply(query domain.SearchQuery, pois []domain.POI) []domain.POI
+}
+
+var _ StreamingOrchestrator = (*CachedOrchestrator)(nil)
+
 func NewCached(inner Orchestrator, cache cache.Cache, ttl time.Duration) *CachedOrchestrator {
 	return &CachedOrchestrator{
-		inner: inner,
-		cache: cache,
-		ttl:   ttl,
+		inner:       inner,
+		cache:       cache,
+		ttl:         ttl,
+		degradedTTL: ttl,
+		emptyTTL:    ttl,
+		fetching:    map[string]chan struct{}{},
+	}
+}
+
+// SetDegradedTTL sets how long results missing some providers are cached.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetDegradedTTL(ttl time.Duration) {
+	c.degradedTTL = ttl
+}
+
+// SetEmptyTTL sets how long tiles without any POI are cached, so
+// oceans and forests need not be asked about on every search.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetEmptyTTL(ttl time.Duration) {
+	c.emptyTTL = ttl
+}
+
+// SetNegativeTTL caches fetches no provider answered as failures for
+// base, doubling on each consecutive failure up to max, so retries do not
+// hit every provider again. A zero base disables negative caching.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetNegativeTTL(base time.Duration, max time.Duration) {
+
+	c.negative = nil
+
+	if base > 0 {
+		c.negative = newFailureBackoff(base, max)
 	}
 }
 
+// KeepFailures continues the negative TTL backoff of prev, the
+// orchestrator this one replaces, so tiles that kept failing are not
+// asked about again sooner after a config reload. prev may be nil.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) KeepFailures(prev *CachedOrchestrator) {
+
+	if c.negative == nil || prev == nil || prev.negative == nil {
+		return
+	}
+
+	c.negative.copyFrom(prev.negative)
+}
+
+// SetIndex records every served POI in index for lookup by ID.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetIndex(index *cache.POIIndex) {
+	c.index = index
+}
+
+// SetRecorder reports the tiles of every search to recorder.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetRecorder(recorder TileRecorder) {
+	c.recorder = recorder
+}
+
+// SetOverrides applies overrides to every result as it is assembled, so
+// cached tiles keep what providers returned and rule changes take effect
+// without invalidating them.
+// It must be called before the orchestrator is shared.
+func (c *CachedOrchestrator) SetOverrides(overrides Overrider) {
+	c.overrides = overrides
+}
+
+// ApplyOverrides applies the configured overrides to pois, for results
+// merged outside the orchestrator such as stream snapshots.
+func (c *CachedOrchestrator) ApplyOverrides(query domain.SearchQuery, pois []domain.POI) []domain.POI {
+
+	if c.overrides == nil {
+		return pois
+	}
+
+	return c.overrides.Apply(query, pois)
+}
+
 func (c *CachedOrchestrator) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
-	key := cache.BuildKey(query)
+	result, err := c.SearchWithStatus(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.POIs, nil
+}
+
+func (c *CachedOrchestrator) SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error) {
+
+	return c.SearchStream(context.Background(), query, nil)
+}
+
+// SearchStream serves cache hits without any provider updates; on a miss
+// it streams the inner orchestrator's updates, cut to the query geometry,
+// and caches the final result.
+func (c *CachedOrchestrator) SearchStream(
+	ctx context.Context,
+	query domain.SearchQuery,
+	observe func(ProviderUpdate),
+) (domain.SearchResult, error) {
+
+	result, err := c.search(ctx, query, observe)
+
+	if err == nil && c.index != nil {
+		c.index.Add(result.POIs)
+	}
+
+	return result, err
+}
+
+// search serves query from geohash tiles cached independently, so nearby
+// and overlapping queries share entries. Only missing tiles are fetched,
+// in one upstream search covering all of them, and the answer is the
+// cached tiles filtered to the exact query geometry.
+func (c *CachedOrchestrator) search(
+	ctx context.Context,
+	query domain.SearchQuery,
+	observe func(ProviderUpdate),
+) (domain.SearchResult, error) {
+
+	cover, err := cache.CoverQuery(query)
+
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+
+	if c.recorder != nil {
+		c.recorder.RecordTiles(cover.Hashes, query.Categories)
+	}
+
+	tiles := make(map[string][]domain.POI, len(cover.Hashes))
+
+	missing, failed, complete := c.lookup(cover.Hashes, query.Categories, tiles)
+
+	metrics.CacheTiles.WithLabelValues("hit").Add(float64(len(tiles)))
+	metrics.CacheTiles.WithLabelValues("miss").Add(float64(len(missing)))
+	metrics.CacheTiles.WithLabelValues("negative").Add(float64(len(failed)))
+
+	// when another search is fetching some of the missing tiles, wait for
+	// it and read them from the cache instead of fetching them again
+	for len(missing) > 0 {
+
+		done := c.claim(missing, query.Categories)
+
+		if done == nil {
+			break
+		}
+
+		for _, ch := range done {
+			select {
+			case <-ch:
+			case <-ctx.Done():
+				return domain.SearchResult{}, ctx.Err()
+			}
+		}
+
+		var stillFailed []string
+		var stillComplete bool
+
+		missing, stillFailed, stillComplete = c.lookup(missing, query.Categories, tiles)
+
+		failed = append(failed, stillFailed...)
+		complete = complete && stillComplete
+	}
+
+	defer c.release(missing, query.Categories)
 
 	// cache hit
-	if cached, found := c.cache.Get(key); found {
-		return cached, nil
+	if len(missing) == 0 {
+
+		if len(tiles) == 0 {
+			return domain.SearchResult{Cached: true}, ErrAllProvidersFailed
+		}
+
+		metrics.CacheHits.Inc()
+		return c.assemble(query, cover.Hashes, tiles, complete, true, nil), nil
 	}
 
-	// cache miss
-	results, err := c.inner.Search(query)
+	metrics.CacheMisses.Inc()
+
+	fetch := cache.FetchQuery(query, missing)
+
+	fetched, err := searchStream(ctx, c.inner, fetch, within(query, observe))
 
 	if err != nil {
-		return nil, err
+
+		if errors.Is(err, ErrAllProvidersFailed) && ctx.Err() == nil {
+			c.storeFailure(missing, query.Categories)
+		}
+
+		// serve the tiles we have rather than nothing
+		if len(tiles) > 0 && ctx.Err() == nil {
+			return c.assemble(query, cover.Hashes, tiles, false, false, fetched.Providers), nil
+		}
+
+		return fetched, err
+	}
+
+	markTruncated(fetch, &fetched)
+
+	for hash, pois := range c.store(fetched, missing, cover.Precision, query.Categories) {
+		tiles[hash] = pois
+	}
+
+	return c.assemble(query, cover.Hashes, tiles, complete && fetched.Complete, false, fetched.Providers), nil
+}
+
+// lookup reads the cached tiles of hashes into tiles. It returns the
+// hashes to fetch, those whose last fetch failed, and whether every tile
+// found was complete.
+func (c *CachedOrchestrator) lookup(
+	hashes []string,
+	categories []string,
+	tiles map[string][]domain.POI,
+) (missing []string, failed []string, complete bool) {
+
+	complete = true
+
+	for _, hash := range hashes {
+
+		key := cache.TileKey(hash, categories)
+
+		if cached, found := c.cache.Get(key); found {
+			tiles[hash] = cached
+			continue
+		}
+
+		// degraded tiles live under their own key so a later complete
+		// answer always takes precedence
+		if cached, found := c.cache.Get(cache.DegradedKey(key)); found {
+			tiles[hash] = cached
+			complete = false
+			continue
+		}
+
+		// a recent fetch of this tile reached no provider; wait out its
+		// backoff instead of asking them all again
+		if c.negative != nil {
+			if _, found := c.cache.Get(cache.FailedKey(key)); found {
+				failed = append(failed, hash)
+				complete = false
+				continue
+			}
+		}
+
+		missing = append(missing, hash)
+	}
+
+	return missing, failed, complete
+}
+
+// claim marks the tiles of hashes as fetched by the caller, who must
+// release them once they are stored. If another search is fetching any
+// of them, none are claimed and the channels closed when those fetches
+// are done are returned instead. Claiming all or nothing means a waiting
+// search holds no claims, so searches never wait for each other in a
+// cycle.
+func (c *CachedOrchestrator) claim(hashes []string, categories []string) []chan struct{} {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var done []chan struct{}
+
+	for _, hash := range hashes {
+		if ch, ok := c.fetching[cache.TileKey(hash, categories)]; ok {
+			done = append(done, ch)
+		}
+	}
+
+	if done != nil {
+		return done
+	}
+
+	for _, hash := range hashes {
+		c.fetching[cache.TileKey(hash, categories)] = make(chan struct{})
+	}
+
+	return nil
+}
+
+// release wakes the searches waiting for the claimed tiles of hashes.
+func (c *CachedOrchestrator) release(hashes []string, categories []string) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, hash := range hashes {
+
+		key := cache.TileKey(hash, categories)
+
+		close(c.fetching[key])
+		delete(c.fetching, key)
+	}
+}
+
+// TileTTL reports how long the complete cache entry of a tile has left,
+// or zero when it is not cached or the cache cannot tell.
+func (c *CachedOrchestrator) TileTTL(hash string, categories []string) time.Duration {
+
+	getter, ok := c.cache.(cache.TTLGetter)
+
+	if !ok {
+		return 0
+	}
+
+	_, ttl, found := getter.GetWithTTL(cache.TileKey(hash, categories))
+
+	if !found {
+		return 0
+	}
+
+	return ttl
+}
+
+// Warm fetches one tile upstream and stores it whether or not it is
+// cached, extending the life of entries that are about to expire.
+func (c *CachedOrchestrator) Warm(ctx context.Context, hash string, categories []string, limit int) error {
+
+	query := cache.FetchQuery(domain.SearchQuery{Limit: limit, Categories: categories}, []string{hash})
+
+	fetched, err := searchStream(ctx, c.inner, query, nil)
+
+	if err != nil {
+		return err
+	}
+
+	markTruncated(query, &fetched)
+
+	c.store(fetched, []string{hash}, uint(len(hash)), categories)
+
+	return nil
+}
+
+// store splits fetched into the given tiles and caches each of them,
+// empty ones included, since the fetch covered all of them; POIs outside
+// them are dropped.
+func (c *CachedOrchestrator) store(
+	fetched domain.SearchResult,
+	hashes []string,
+	precision uint,
+	categories []string,
+) map[string][]domain.POI {
+
+	split := make(map[string][]domain.POI, len(hashes))
+
+	for _, poi := range fetched.POIs {
+
+		hash := cache.TileOf(poi, precision)
+
+		split[hash] = append(split[hash], poi)
+	}
+
+	stored := make(map[string][]domain.POI, len(hashes))
+
+	for _, hash := range hashes {
+
+		key := cache.TileKey(hash, categories)
+
+		switch {
+
+		case !fetched.Complete:
+			c.cache.Set(cache.DegradedKey(key), split[hash], c.degradedTTL)
+
+		case len(split[hash]) == 0:
+			c.cache.Set(key, split[hash], c.emptyTTL)
+
+		default:
+			c.cache.Set(key, split[hash], c.ttl)
+		}
+
+		if c.negative != nil {
+			c.negative.reset(key)
+		}
+
+		stored[hash] = split[hash]
 	}
 
-	c.cache.Set(key, results, c.ttl)
+	return stored
+}
+
+// markTruncated marks a fetch as incomplete when a provider hit its own
+// limit, or the merged answer hit the fetch's: places beyond the limit
+// were cut, so its tiles must not be cached as the whole truth about
+// their area.
+func markTruncated(fetch domain.SearchQuery, fetched *domain.SearchResult) {
+
+	if fetch.Limit > 0 && len(fetched.POIs) >= fetch.Limit {
+		fetched.Complete = false
+	}
 
-	return results, nil
+	for _, s := range fetched.Providers {
+		if s.Truncated {
+			fetched.Complete = false
+		}
+	}
+}
+
+// storeFailure caches a negative entry for each tile a failed fetch
+// covered.
+func (c *CachedOrchestrator) storeFailure(hashes []string, categories []string) {
+
+	if c.negative == nil {
+		return
+	}
+
+	for _, hash := range hashes {
+
+		key := cache.TileKey(hash, categories)
+
+		c.cache.Set(cache.FailedKey(key), nil, c.negative.next(key))
+	}
+}
+
+// assemble merges tiles in cover order, keeps POIs inside the query
+// geometry, applies overrides and ranks them for query.
+func (c *CachedOrchestrator) assemble(
+	query domain.SearchQuery,
+	hashes []string,
+	tiles map[string][]domain.POI,
+	complete bool,
+	cached bool,
+	providers []domain.ProviderStatus,
+) domain.SearchResult {
+
+	var pois []domain.POI
+
+	for _, hash := range hashes {
+		for _, poi := range tiles[hash] {
+			if cache.Contains(query, poi) {
+				pois = append(pois, poi)
+			}
+		}
+	}
+
+	return domain.SearchResult{
+		POIs:      ranking.Rank(c.ApplyOverrides(query, pois), query),
+		Complete:  complete,
+		Cached:    cached,
+		Providers: providers,
+	}
+}
+
+// within passes observe only the POIs of each update inside the query
+// geometry, since the tiles are fetched over a larger area.
+func within(query domain.SearchQuery, observe func(ProviderUpdate)) func(ProviderUpdate) {
+
+	if observe == nil {
+		return nil
+	}
+
+	return func(update ProviderUpdate) {
+
+		var pois []domain.POI
+
+		for _, poi := range update.POIs {
+			if cache.Contains(query, poi) {
+				pois = append(pois, poi)
+			}
+		}
+
+		update.POIs = pois
+
+		observe(update)
+	}
 }
@@ -1,12 +1,16 @@
 package orchestrator
 
 import (
+	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/hynek-systems/hynek-poi/internal/cache"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
 )
 
 type mockOrchestrator struct {
@@ -23,7 +27,7 @@ func TestCachedOrchestrator_CacheHit(t *testing.T) {
 	memCache := cache.NewMemoryCache()
 	mockInner := &mockOrchestrator{
 		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
-			return []domain.POI{{ID: "1", Name: "Test"}}, nil
+			return []domain.POI{{ID: "1", Name: "Test", Latitude: 59.3293, Longitude: 18.0686}}, nil
 		},
 	}
 
@@ -68,7 +72,7 @@ func TestCachedOrchestrator_CacheMiss(t *testing.T) {
 	memCache := cache.NewMemoryCache()
 	mockInner := &mockOrchestrator{
 		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
-			return []domain.POI{{ID: "1", Name: "Test"}}, nil
+			return []domain.POI{{ID: "1", Name: "Test", Latitude: 59.3293, Longitude: 18.0686}}, nil
 		},
 	}
 
@@ -132,3 +136,507 @@ func TestCachedOrchestrator_ErrorNotCached(t *testing.T) {
 		t.Errorf("Expected 2 calls to inner orchestrator (errors not cached), got %d", mockInner.callCount)
 	}
 }
+
+type statusOrchestrator struct {
+	mockOrchestrator
+	complete bool
+}
+
+func (m *statusOrchestrator) SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error) {
+	pois, err := m.Search(query)
+	return domain.SearchResult{POIs: pois, Complete: m.complete}, err
+}
+
+func TestCachedOrchestrator_DegradedResultShortTTL(t *testing.T) {
+	memCache := cache.NewMemoryCache()
+	mockInner := &statusOrchestrator{
+		mockOrchestrator: mockOrchestrator{
+			searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+				return []domain.POI{{ID: "1", Name: "Partial", Latitude: 59.3293, Longitude: 18.0686}}, nil
+			},
+		},
+		complete: false,
+	}
+
+	orchestrator := NewCached(mockInner, memCache, 1*time.Minute)
+	orchestrator.SetDegradedTTL(50 * time.Millisecond)
+
+	query := domain.SearchQuery{
+		Latitude:  59.3293,
+		Longitude: 18.0686,
+		Radius:    1000,
+	}
+
+	result, err := orchestrator.SearchWithStatus(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Complete {
+		t.Error("Expected degraded result")
+	}
+
+	result, _ = orchestrator.SearchWithStatus(query)
+
+	if !result.Cached || result.Complete {
+		t.Errorf("Expected cached degraded result, got cached=%v complete=%v", result.Cached, result.Complete)
+	}
+
+	if mockInner.callCount != 1 {
+		t.Errorf("Expected 1 call before degraded TTL expires, got %d", mockInner.callCount)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mockInner.complete = true
+
+	_, _ = orchestrator.SearchWithStatus(query)
+
+	if mockInner.callCount != 2 {
+		t.Errorf("Expected degraded entry to expire, got %d calls", mockInner.callCount)
+	}
+
+	result, _ = orchestrator.SearchWithStatus(query)
+
+	if !result.Cached || !result.Complete {
+		t.Errorf("Expected cached complete result, got cached=%v complete=%v", result.Cached, result.Complete)
+	}
+}
+
+type recordingOrchestrator struct {
+	queries []domain.SearchQuery
+	pois    []domain.POI
+	err     error
+}
+
+func (m *recordingOrchestrator) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	m.queries = append(m.queries, query)
+	return m.pois, m.err
+}
+
+func TestCachedOrchestrator_OverlappingQueriesShareTiles(t *testing.T) {
+	inner := &recordingOrchestrator{
+		pois: []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}},
+	}
+
+	orchestrator := NewCached(inner, cache.NewMemoryCache(), time.Minute)
+
+	if _, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a smaller area inside the first is served from the same tiles
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.3295, Longitude: 18.0690, Radius: 500})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Cached || len(result.POIs) != 1 || len(inner.queries) != 1 {
+		t.Errorf("Expected cached result from shared tiles, got cached=%v pois=%d calls=%d", result.Cached, len(result.POIs), len(inner.queries))
+	}
+
+	// a panned view fetches only the tiles it does not share
+	if _, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0886, Radius: 1000}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(inner.queries) != 2 {
+		t.Fatalf("Expected one fetch for the panned view, got %d", len(inner.queries))
+	}
+
+	first, second := inner.queries[0].BBox, inner.queries[1].BBox
+
+	if second.MaxLng-second.MinLng >= first.MaxLng-first.MinLng {
+		t.Errorf("Expected panned fetch to cover fewer tiles, got %+v vs %+v", second, first)
+	}
+}
+
+func TestCachedOrchestrator_FiltersToQueryGeometry(t *testing.T) {
+	inner := &recordingOrchestrator{
+		pois: []domain.POI{
+			{ID: "near", Latitude: 59.3293, Longitude: 18.0686},
+			{ID: "far", Latitude: 59.3393, Longitude: 18.0686},
+		},
+	}
+
+	orchestrator := NewCached(inner, cache.NewMemoryCache(), time.Minute)
+
+	results, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 500})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 1 || results[0].ID != "near" {
+		t.Errorf("Expected only the POI within 500m, got %+v", results)
+	}
+}
+
+func TestCachedOrchestrator_UpdatesFilteredToQueryGeometry(t *testing.T) {
+	inner := NewParallel([]provider.Provider{&mockProvider{
+		name: "osm",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{
+				{ID: "near", Latitude: 59.3293, Longitude: 18.0686},
+				{ID: "far", Latitude: 59.3393, Longitude: 18.0686},
+			}, nil
+		},
+	}}, time.Second)
+
+	orchestrator := NewCached(inner, cache.NewMemoryCache(), time.Minute)
+
+	var streamed []domain.POI
+
+	_, err := orchestrator.SearchStream(context.Background(), domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 500}, func(u ProviderUpdate) {
+		streamed = append(streamed, u.POIs...)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(streamed) != 1 || streamed[0].ID != "near" {
+		t.Errorf("Expected only the POI within 500m streamed, got %+v", streamed)
+	}
+}
+
+func TestCachedOrchestrator_FetchFailureServesCachedTiles(t *testing.T) {
+	inner := &recordingOrchestrator{
+		pois: []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}},
+	}
+
+	orchestrator := NewCached(inner, cache.NewMemoryCache(), time.Minute)
+
+	if _, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	inner.err = errors.New("upstream down")
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0786, Radius: 1000})
+	if err != nil {
+		t.Fatalf("Expected cached tiles despite fetch failure, got %v", err)
+	}
+
+	if result.Complete || len(result.POIs) != 1 {
+		t.Errorf("Expected degraded result with the cached POI, got complete=%v pois=%d", result.Complete, len(result.POIs))
+	}
+}
+
+type tileRecorder struct {
+	hashes []string
+}
+
+func (r *tileRecorder) RecordTiles(hashes []string, categories []string) {
+	r.hashes = append(r.hashes, hashes...)
+}
+
+func TestCachedOrchestrator_WarmRefreshesCachedTile(t *testing.T) {
+	inner := &recordingOrchestrator{
+		pois: []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}},
+	}
+
+	recorder := &tileRecorder{}
+
+	orchestrator := NewCached(inner, cache.NewMemoryCache(), time.Minute)
+	orchestrator.SetRecorder(recorder)
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+	if _, err := orchestrator.Search(query); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(recorder.hashes) == 0 {
+		t.Fatal("Expected searched tiles to be recorded")
+	}
+
+	hash := cache.TileOf(inner.pois[0], coverOf(t, query).Precision)
+
+	inner.pois = []domain.POI{{ID: "2", Latitude: 59.3293, Longitude: 18.0686}}
+
+	// warming fetches even though the tile is cached
+	if err := orchestrator.Warm(context.Background(), hash, nil, 50); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(inner.queries) != 2 || inner.queries[1].Limit != 50 {
+		t.Fatalf("Expected one warming fetch with limit 50, got %+v", inner.queries)
+	}
+
+	result, err := orchestrator.SearchWithStatus(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Cached || len(result.POIs) != 1 || result.POIs[0].ID != "2" {
+		t.Errorf("Expected warmed POI served from cache, got cached=%v %+v", result.Cached, result.POIs)
+	}
+}
+
+func TestCachedOrchestrator_EmptyTilesUseEmptyTTL(t *testing.T) {
+	inner := &recordingOrchestrator{pois: []domain.POI{}}
+
+	memCache := cache.NewMemoryCache()
+
+	orchestrator := NewCached(inner, memCache, time.Hour)
+	orchestrator.SetEmptyTTL(time.Minute)
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+	result, err := orchestrator.SearchWithStatus(query)
+	if err != nil {
+		t.Fatalf("Expected empty result, got %v", err)
+	}
+
+	if len(result.POIs) != 0 {
+		t.Fatalf("Expected no POIs, got %d", len(result.POIs))
+	}
+
+	cover := coverOf(t, query)
+
+	_, ttl, found := memCache.GetWithTTL(cache.TileKey(cover.Hashes[0], nil))
+
+	if !found || ttl > time.Minute {
+		t.Errorf("Expected empty tile cached for at most a minute, got found=%v ttl=%s", found, ttl)
+	}
+
+	if _, err := orchestrator.Search(query); err != nil || len(inner.queries) != 1 {
+		t.Errorf("Expected empty area served from cache, got err=%v calls=%d", err, len(inner.queries))
+	}
+}
+
+func TestCachedOrchestrator_NegativeCachingWithBackoff(t *testing.T) {
+	inner := &recordingOrchestrator{err: ErrAllProvidersFailed}
+
+	memCache := cache.NewMemoryCache()
+
+	orchestrator := NewCached(inner, memCache, time.Hour)
+	orchestrator.SetNegativeTTL(time.Second, 4*time.Second)
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+	if _, err := orchestrator.Search(query); !errors.Is(err, ErrAllProvidersFailed) {
+		t.Fatalf("Expected ErrAllProvidersFailed, got %v", err)
+	}
+
+	// the retry is answered from the negative entry
+	result, err := orchestrator.SearchWithStatus(query)
+
+	if !errors.Is(err, ErrAllProvidersFailed) || !result.Cached || len(inner.queries) != 1 {
+		t.Fatalf("Expected cached failure without a fetch, got err=%v cached=%v calls=%d", err, result.Cached, len(inner.queries))
+	}
+
+	key := cache.TileKey(coverOf(t, query).Hashes[0], nil)
+
+	for i, want := range []time.Duration{2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if ttl := orchestrator.negative.next(key); ttl != want {
+			t.Errorf("Expected failure %d to back off %s, got %s", i+2, want, ttl)
+		}
+	}
+}
+
+type hideAll struct {
+	enabled bool
+}
+
+func (h *hideAll) Apply(query domain.SearchQuery, pois []domain.POI) []domain.POI {
+
+	if h.enabled {
+		return []domain.POI{}
+	}
+
+	return pois
+}
+
+func TestCachedOrchestrator_OverridesApplyToCachedTiles(t *testing.T) {
+	memCache := cache.NewMemoryCache()
+	mockInner := &mockOrchestrator{
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Name: "Test", Latitude: 59.3293, Longitude: 18.0686}}, nil
+		},
+	}
+
+	overrides := &hideAll{}
+
+	orchestrator := NewCached(mockInner, memCache, 1*time.Minute)
+	orchestrator.SetOverrides(overrides)
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+	if results, _ := orchestrator.Search(query); len(results) != 1 {
+		t.Fatalf("Expected 1 result before the rule, got %d", len(results))
+	}
+
+	overrides.enabled = true
+
+	if results, _ := orchestrator.Search(query); len(results) != 0 {
+		t.Errorf("Expected new rule to apply to the cached tile, got %d results", len(results))
+	}
+
+	if mockInner.callCount != 1 {
+		t.Errorf("Expected the cache to be kept, got %d inner calls", mockInner.callCount)
+	}
+}
+
+func TestCachedOrchestrator_TruncatedFetchIsDegraded(t *testing.T) {
+	inner := &statusOrchestrator{complete: true}
+
+	inner.searchFunc = func(q domain.SearchQuery) ([]domain.POI, error) {
+
+		pois := make([]domain.POI, q.Limit)
+
+		for i := range pois {
+			pois[i] = domain.POI{ID: string(rune('a' + i%26)), Latitude: 59.3293, Longitude: 18.0686}
+		}
+
+		return pois, nil
+	}
+
+	memCache := cache.NewMemoryCache()
+
+	orchestrator := NewCached(inner, memCache, time.Minute)
+	orchestrator.SetDegradedTTL(time.Second)
+
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 2}
+
+	result, err := orchestrator.SearchWithStatus(query)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Complete {
+		t.Error("Expected a fetch that hit its limit to be incomplete")
+	}
+
+	cover := coverOf(t, query)
+	key := cache.TileKey(cover.Hashes[0], nil)
+
+	if _, found := memCache.Get(key); found {
+		t.Error("Expected no complete tile from a truncated fetch")
+	}
+
+	if _, found := memCache.Get(cache.DegradedKey(key)); !found {
+		t.Error("Expected the tile cached as degraded")
+	}
+}
+
+// cappedProvider answers at most its limit, like Google's 20 per call.
+type cappedProvider struct {
+	mockProvider
+	limits provider.Limits
+}
+
+func (p *cappedProvider) Limits() provider.Limits {
+	return p.limits
+}
+
+func TestCachedOrchestrator_ProviderAtItsLimitIsDegraded(t *testing.T) {
+
+	google := &cappedProvider{
+		mockProvider: mockProvider{
+			name: "google",
+			searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+				return []domain.POI{
+					{ID: "a", Latitude: 59.3293, Longitude: 18.0686},
+					{ID: "b", Latitude: 59.3294, Longitude: 18.0687},
+				}, nil
+			},
+		},
+		limits: provider.Limits{MaxResults: 2},
+	}
+
+	memCache := cache.NewMemoryCache()
+
+	orchestrator := NewCached(NewParallel([]provider.Provider{google}, time.Second), memCache, time.Minute)
+	orchestrator.SetDegradedTTL(time.Second)
+
+	// the merged answer stays far below the fetch's limit
+	query := domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 50}
+
+	result, err := orchestrator.SearchWithStatus(query)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Complete {
+		t.Error("Expected a provider that hit its own limit to make the fetch incomplete")
+	}
+
+	cover := coverOf(t, query)
+	key := cache.TileKey(cache.TileOf(domain.POI{Latitude: 59.3293, Longitude: 18.0686}, cover.Precision), nil)
+
+	if _, found := memCache.Get(key); found {
+		t.Error("Expected no complete tile from a truncated provider")
+	}
+
+	if _, found := memCache.Get(cache.DegradedKey(key)); !found {
+		t.Error("Expected the tile cached as degraded")
+	}
+}
+
+func coverOf(t *testing.T, query domain.SearchQuery) cache.TileCover {
+
+	t.Helper()
+
+	cover, err := cache.CoverQuery(query)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return cover
+}
+
+func TestCachedOrchestrator_ConcurrentSearchesShareTileFetch(t *testing.T) {
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	slow := &mockProvider{
+		name: "osm",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			calls.Add(1)
+			<-release
+			return []domain.POI{{ID: "a", Latitude: 59.3293, Longitude: 18.0686}}, nil
+		},
+	}
+
+	orchestrator := NewCached(NewParallel([]provider.Provider{slow}, time.Second), cache.NewMemoryCache(), time.Minute)
+
+	// overlapping views of the same size share their tiles
+	queries := []domain.SearchQuery{
+		{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Limit: 50},
+		{Latitude: 59.3294, Longitude: 18.0687, Radius: 1000, Limit: 50},
+	}
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(queries))
+
+	for i, q := range queries {
+
+		wg.Add(1)
+
+		go func(i int, q domain.SearchQuery) {
+			defer wg.Done()
+			_, errs[i] = orchestrator.SearchWithStatus(q)
+		}(i, q)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Unexpected error for query %d: %v", i, err)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected the shared tiles fetched once, got %d provider calls", calls.Load())
+	}
+}
@@ -1,7 +1,67 @@
 package orchestrator
 
-import "github.com/hynek-systems/hynek-poi/internal/domain"
+import (
+	"context"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
 
 type Orchestrator interface {
 	Search(query domain.SearchQuery) ([]domain.POI, error)
 }
+
+// StatusOrchestrator is implemented by orchestrators that report how each
+// provider fared alongside the merged results.
+type StatusOrchestrator interface {
+	Orchestrator
+
+	SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error)
+}
+
+// ProviderUpdate is one provider's answer, delivered as soon as it is known.
+type ProviderUpdate struct {
+	Status domain.ProviderStatus
+	POIs   []domain.POI
+}
+
+// StreamingOrchestrator is implemented by orchestrators that can report
+// each provider's answer as it arrives. observe is called from a single
+// goroutine, before SearchStream returns. Cancelling ctx abandons the
+// search and the outstanding provider calls.
+type StreamingOrchestrator interface {
+	StatusOrchestrator
+
+	SearchStream(ctx context.Context, query domain.SearchQuery, observe func(ProviderUpdate)) (domain.SearchResult, error)
+}
+
+// searchStream runs a streaming search on o, falling back to a single
+// status report for orchestrators that cannot stream.
+func searchStream(ctx context.Context, o Orchestrator, query domain.SearchQuery, observe func(ProviderUpdate)) (domain.SearchResult, error) {
+
+	if so, ok := o.(StreamingOrchestrator); ok {
+		return so.SearchStream(ctx, query, observe)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domain.SearchResult{}, err
+	}
+
+	return searchWithStatus(o, query)
+}
+
+// searchWithStatus asks o for a status report, treating orchestrators that
+// cannot give one as having produced a complete result.
+func searchWithStatus(o Orchestrator, query domain.SearchQuery) (domain.SearchResult, error) {
+
+	if so, ok := o.(StatusOrchestrator); ok {
+		return so.SearchWithStatus(query)
+	}
+
+	pois, err := o.Search(query)
+
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
+
+	return domain.SearchResult{POIs: pois, Complete: true}, nil
+}
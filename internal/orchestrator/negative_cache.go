@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// maxTrackedFailures bounds the tiles whose failure streak is
+// remembered; past it the streaks start over.
+const maxTrackedFailures = 10000
+
+// failureBackoff doubles the negative TTL of a tile on every consecutive
+// failed fetch, from base up to max, and forgets it on success.
+type failureBackoff struct {
+	mu       sync.Mutex
+	base     time.Duration
+	max      time.Duration
+	failures map[string]int
+}
+
+func newFailureBackoff(base time.Duration, max time.Duration) *failureBackoff {
+	return &failureBackoff{
+		base:     base,
+		max:      max,
+		failures: map[string]int{},
+	}
+}
+
+// next records a failure for key and returns its negative TTL.
+func (b *failureBackoff) next(key string) time.Duration {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.failures[key]; !ok && len(b.failures) >= maxTrackedFailures {
+		b.failures = map[string]int{}
+	}
+
+	n := b.failures[key]
+	b.failures[key] = n + 1
+
+	ttl := b.base
+
+	for i := 0; i < n && ttl < b.max; i++ {
+		ttl *= 2
+	}
+
+	return min(ttl, b.max)
+}
+
+// copyFrom replaces b's failure counts with those of other.
+func (b *failureBackoff) copyFrom(other *failureBackoff) {
+
+	other.mu.Lock()
+	failures := maps.Clone(other.failures)
+	other.mu.Unlock()
+
+	b.mu.Lock()
+	b.failures = failures
+	b.mu.Unlock()
+}
+
+func (b *failureBackoff) reset(key string) {
+
+	b.mu.Lock()
+	delete(b.failures, key)
+	b.mu.Unlock()
+}
@@ -3,21 +3,63 @@ package orchestrator
 import (
 	"context"
 	"errors"
-	"log"
-	"sync"
+	"log/slog"
 	"time"
 
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
 	"github.com/hynek-systems/hynek-poi/internal/dedupe"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/logging"
 	"github.com/hynek-systems/hynek-poi/internal/provider"
 	"github.com/hynek-systems/hynek-poi/internal/ranking"
 )
 
+// identityTimeout bounds canonical ID assignment, which runs after the
+// provider deadline may already have passed.
+const identityTimeout = 250 * time.Millisecond
+
+// ErrAllProvidersFailed means no provider answered, so the search says
+// nothing about the area. A search that some provider answered with
+// nothing is an empty result instead.
+var ErrAllProvidersFailed = errors.New("all providers failed or timeout")
+
+// Ingester receives every provider's answer, e.g. to persist it.
+// Ingest must not block.
+type Ingester interface {
+	Ingest(source string, pois []domain.POI)
+}
+
+// Identifier gives each deduplicated place its canonical ID; see
+// identity.Registry.
+type Identifier interface {
+	Assign(ctx context.Context, groups [][]domain.POI) ([]domain.POI, error)
+}
+
+// HealthRecorder is told how every provider call went; see
+// health.Monitor. Observe must not block.
+type HealthRecorder interface {
+	Observe(status domain.ProviderStatus)
+}
+
+// CostGuard decides whether a paid provider may still be called; see
+// cost.Ledger. Exhausted returns why provider must be skipped, or "".
+type CostGuard interface {
+	Exhausted(provider string) string
+}
+
 type ParallelOrchestrator struct {
 	providers []provider.Provider
 	timeout   time.Duration
+
+	ingester Ingester
+	fallback provider.Provider
+	identity Identifier
+	health   HealthRecorder
+	costs    CostGuard
 }
 
+var _ StatusOrchestrator = (*ParallelOrchestrator)(nil)
+
 func NewParallel(
 	providers []provider.Provider,
 	timeout time.Duration,
@@ -29,86 +71,370 @@ func NewParallel(
 	}
 }
 
+// SetIngester hands every successful provider answer to ingester.
+// It must be called before the orchestrator is shared.
+func (o *ParallelOrchestrator) SetIngester(ingester Ingester) {
+	o.ingester = ingester
+}
+
+// SetFallback answers searches no provider answered from fallback, such
+// as the persistent POI store. Its results are reported as incomplete.
+// It must be called before the orchestrator is shared.
+func (o *ParallelOrchestrator) SetFallback(fallback provider.Provider) {
+	o.fallback = fallback
+}
+
+// SetIdentity assigns canonical IDs to merged results through identity.
+// It must be called before the orchestrator is shared.
+func (o *ParallelOrchestrator) SetIdentity(identity Identifier) {
+	o.identity = identity
+}
+
+// SetHealth reports the outcome of every provider call to health,
+// including calls finishing after the search gave up on them.
+// It must be called before the orchestrator is shared.
+func (o *ParallelOrchestrator) SetHealth(health HealthRecorder) {
+	o.health = health
+}
+
+// SetCosts skips providers costs reports exhausted, leaving the search
+// to the others. Skipped providers make the result incomplete.
+// It must be called before the orchestrator is shared.
+func (o *ParallelOrchestrator) SetCosts(costs CostGuard) {
+	o.costs = costs
+}
+
 func (o *ParallelOrchestrator) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	result, err := o.SearchWithStatus(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.POIs, nil
+}
+
+func (o *ParallelOrchestrator) SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error) {
+
+	return o.SearchStream(context.Background(), query, nil)
+}
+
+type providerOutcome struct {
+	index  int
+	status domain.ProviderStatus
+	pois   []domain.POI
+}
+
+// round is what asking a set of providers in parallel produced.
+type round struct {
+	statuses []domain.ProviderStatus
+	pois     []domain.POI
+
+	// skipped is the number of providers the call budget skipped.
+	skipped int
+}
+
+func (o *ParallelOrchestrator) SearchStream(
+	parent context.Context,
+	query domain.SearchQuery,
+	observe func(ProviderUpdate),
+) (domain.SearchResult, error) {
+
+	ctx, cancel := context.WithTimeout(parent, o.timeout)
 	defer cancel()
 
-	var wg sync.WaitGroup
+	r, err := o.ask(parent, ctx, o.providers, o.timeout, query, observe)
 
-	resultsChan := make(chan []domain.POI, len(o.providers))
+	if err != nil {
+		return domain.SearchResult{}, err
+	}
 
-	for _, p := range o.providers {
+	return o.merge(parent, query, r)
+}
 
-		wg.Add(1)
+// ask calls providers in parallel and collects their answers until every
+// one answered or ctx is done; those still out are reported as timed out
+// after timeout. It fails only when parent is cancelled.
+func (o *ParallelOrchestrator) ask(
+	parent context.Context,
+	ctx context.Context,
+	providers []provider.Provider,
+	timeout time.Duration,
+	query domain.SearchQuery,
+	observe func(ProviderUpdate),
+) (round, error) {
 
-		go func(provider provider.Provider) {
+	start := time.Now()
 
-			defer wg.Done()
+	// buffered so providers finishing after the deadline never block
+	outcomes := make(chan providerOutcome, len(providers))
 
-			results, err := provider.Search(query)
+	// statuses is pre-filled as timeout; each provider overwrites its own
+	// slot once it answers.
+	r := round{statuses: make([]domain.ProviderStatus, len(providers))}
 
-			if err != nil {
-				log.Printf("provider %s failed: %v", provider.Name(), err)
-				return
-			}
+	budget := callBudgetFrom(parent)
+
+	for i, p := range providers {
 
-			if len(results) == 0 {
-				log.Printf("provider %s returned 0 results", provider.Name())
-				return
+		r.statuses[i] = domain.ProviderStatus{
+			Provider:  p.Name(),
+			Status:    domain.ProviderStatusTimeout,
+			LatencyMs: timeout.Milliseconds(),
+		}
+
+		if o.costs != nil {
+
+			if reason := o.costs.Exhausted(p.Name()); reason != "" {
+
+				outcomes <- providerOutcome{
+					index: i,
+					status: domain.ProviderStatus{
+						Provider:   p.Name(),
+						Status:     domain.ProviderStatusSkipped,
+						ErrorClass: reason,
+					},
+				}
+
+				continue
 			}
+		}
 
-			select {
+		if budget != nil && !budget.take() {
 
-			case resultsChan <- results:
+			r.skipped++
 
-			case <-ctx.Done():
-				return
+			outcomes <- providerOutcome{
+				index: i,
+				status: domain.ProviderStatus{
+					Provider:   p.Name(),
+					Status:     domain.ProviderStatusSkipped,
+					ErrorClass: "budget_exhausted",
+				},
 			}
 
-		}(p)
-	}
+			continue
+		}
 
-	// close channel when all providers finished
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+		go func(i int, p provider.Provider) {
 
-	var all []domain.POI
+			results, err := provider.SearchWithContext(ctx, p, query)
 
-	for {
+			status := providerStatus(p.Name(), results, err, time.Since(start))
 
-		select {
+			// the raw error is not logged, as it may carry upstream URLs
+			// with API keys
+			if err != nil {
+				slog.WarnContext(ctx, "provider failed", "provider", p.Name(), "elapsed_ms", logging.Milliseconds(time.Since(start)), "status", status.Status, "error_class", errorClass(err))
+			} else if len(results) == 0 {
+				slog.DebugContext(ctx, "provider returned 0 results", "provider", p.Name())
+			}
 
-		case results, ok := <-resultsChan:
+			if err == nil {
+				status.Truncated = truncated(provider.LimitsOf(p), query, len(results))
+			}
 
-			if !ok {
+			// a call cut off by the caller's deadline or a tier cutoff
+			// says nothing about the provider
+			if o.health != nil && (err == nil || ctx.Err() == nil) {
+				o.health.Observe(status)
+			}
 
-				if len(all) == 0 {
-					return nil, errors.New("all providers failed or timeout")
-				}
+			outcomes <- providerOutcome{
+				index:  i,
+				status: status,
+				pois:   results,
+			}
 
-				deduped := dedupe.Deduplicate(all)
+		}(i, p)
+	}
 
-				ranked := ranking.Rank(deduped, query)
+	for pending := len(providers); pending > 0; pending-- {
 
-				return ranked, nil
+		select {
+
+		case outcome := <-outcomes:
+
+			r.statuses[outcome.index] = outcome.status
+
+			r.pois = append(r.pois, outcome.pois...)
+
+			if o.ingester != nil && outcome.status.Status == domain.ProviderStatusOK {
+				o.ingester.Ingest(outcome.status.Provider, outcome.pois)
 			}
 
-			all = append(all, results...)
+			if observe != nil {
+				observe(ProviderUpdate{Status: outcome.status, POIs: outcome.pois})
+			}
 
 		case <-ctx.Done():
 
-			if len(all) == 0 {
-				return nil, errors.New("all providers failed or timeout")
+			// the caller went away; a partial answer is of no use to anyone
+			if errors.Is(parent.Err(), context.Canceled) {
+				return round{}, parent.Err()
 			}
 
-			deduped := dedupe.Deduplicate(all)
+			return r, nil
+		}
+	}
 
-			ranked := ranking.Rank(deduped, query)
+	return r, nil
+}
+
+// merge deduplicates and ranks the answers of r into a result, falling
+// back when no provider answered.
+func (o *ParallelOrchestrator) merge(parent context.Context, query domain.SearchQuery, r round) (domain.SearchResult, error) {
+
+	result := domain.SearchResult{
+		Providers: r.statuses,
+		Complete:  true,
+	}
+
+	answered := 0
 
-			return ranked, nil
+	for _, s := range result.Providers {
+		if s.Status != domain.ProviderStatusOK {
+			result.Complete = false
+		} else {
+			answered++
 		}
 	}
+
+	if answered == 0 && o.fallback != nil {
+		return o.searchFallback(parent, query, result)
+	}
+
+	if answered == 0 && r.skipped > 0 && r.skipped == len(r.statuses) {
+		return result, ErrCallBudgetExhausted
+	}
+
+	if answered == 0 {
+		return result, ErrAllProvidersFailed
+	}
+
+	if len(r.pois) == 0 {
+		result.POIs = []domain.POI{}
+		return result, nil
+	}
+
+	deduped := o.identify(parent, dedupe.Group(r.pois))
+
+	result.POIs = ranking.Rank(deduped, query)
+
+	return result, nil
+}
+
+// searchFallback answers from the fallback provider once every provider
+// failed or was skipped. The result stays incomplete so it is cached as
+// degraded.
+func (o *ParallelOrchestrator) searchFallback(parent context.Context, query domain.SearchQuery, result domain.SearchResult) (domain.SearchResult, error) {
+
+	start := time.Now()
+
+	pois, err := o.fallback.Search(query)
+
+	result.Providers = append(result.Providers, providerStatus(o.fallback.Name(), pois, err, time.Since(start)))
+
+	if err != nil {
+		slog.WarnContext(parent, "fallback failed", "provider", o.fallback.Name(), "error", err)
+	}
+
+	// an empty fallback says nothing about the area either
+	if err != nil || len(pois) == 0 {
+		return result, ErrAllProvidersFailed
+	}
+
+	groups := make([][]domain.POI, len(pois))
+
+	for i, poi := range pois {
+		groups[i] = []domain.POI{poi}
+	}
+
+	result.POIs = ranking.Rank(o.identify(parent, groups), query)
+
+	return result, nil
+}
+
+// identify keeps the first POI of each group, with its canonical ID when
+// identity is set and reachable.
+func (o *ParallelOrchestrator) identify(parent context.Context, groups [][]domain.POI) []domain.POI {
+
+	if o.identity == nil {
+
+		kept := make([]domain.POI, len(groups))
+
+		for i, group := range groups {
+			kept[i] = group[0]
+		}
+
+		return kept
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), identityTimeout)
+	defer cancel()
+
+	kept, err := o.identity.Assign(ctx, groups)
+
+	if err != nil {
+		slog.WarnContext(parent, "canonical ids unavailable", "error", err)
+	}
+
+	return kept
+}
+
+func providerStatus(name string, results []domain.POI, err error, elapsed time.Duration) domain.ProviderStatus {
+
+	status := domain.ProviderStatus{
+		Provider:    name,
+		Status:      domain.ProviderStatusOK,
+		LatencyMs:   elapsed.Milliseconds(),
+		ResultCount: len(results),
+	}
+
+	switch {
+
+	case err == nil:
+
+	case errors.Is(err, circuitbreaker.ErrCircuitOpen):
+		status.Status = domain.ProviderStatusCircuitOpen
+
+	case errors.Is(err, provider.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		status.Status = domain.ProviderStatusTimeout
+
+	default:
+		status.Status = domain.ProviderStatusError
+		status.ErrorClass = errorClass(err)
+	}
+
+	return status
+}
+
+// truncated reports whether a provider with limits l answering count
+// places may have left some of query's out.
+func truncated(l provider.Limits, query domain.SearchQuery, count int) bool {
+
+	asked := l.Clamp(query)
+
+	return (asked.Limit > 0 && count >= asked.Limit) || asked.Radius < query.Radius
+}
+
+// errorClass maps an error to a coarse, client-safe label. Raw messages
+// are not exposed since they may contain upstream URLs and API keys.
+func errorClass(err error) string {
+
+	switch {
+
+	case errors.Is(err, provider.ErrRateLimited):
+		return "rate_limited"
+
+	case errors.Is(err, provider.ErrQuotaExceeded):
+		return "quota_exhausted"
+
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+
+	default:
+		return "upstream"
+	}
 }
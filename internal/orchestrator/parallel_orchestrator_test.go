@@ -1,10 +1,13 @@
 package orchestrator
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 	"github.com/hynek-systems/hynek-poi/internal/provider"
 )
@@ -174,13 +177,404 @@ func TestParallelOrchestrator_EmptyResults(t *testing.T) {
 	query := domain.SearchQuery{Latitude: 59.0, Longitude: 18.0}
 	results, err := orchestrator.Search(query)
 
-	// NOTE: Current implementation returns nil, nil when provider returns empty results
-	// This may be unintended behavior - consider returning an error
-	if results != nil {
-		t.Errorf("Expected nil results, got %v", results)
+	// a provider answering with nothing is an empty result, not a failure
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
 	}
 
+	if results == nil || len(results) != 0 {
+		t.Errorf("Expected empty results, got %v", results)
+	}
+}
+
+func TestParallelOrchestrator_EmptyAndFailedIsDegradedEmpty(t *testing.T) {
+	emptyProvider := &mockProvider{
+		name: "empty",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, nil
+		},
+	}
+
+	failingProvider := &mockProvider{
+		name: "failing",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	orchestrator := NewParallel([]provider.Provider{emptyProvider, failingProvider}, time.Second)
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
 	if err != nil {
-		t.Logf("Got error (good): %v", err)
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Complete || len(result.POIs) != 0 {
+		t.Errorf("Expected degraded empty result, got complete=%v pois=%d", result.Complete, len(result.POIs))
+	}
+}
+
+func TestParallelOrchestrator_StatusReportsDegraded(t *testing.T) {
+	working := &mockProvider{
+		name: "working",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Name: "Working Result"}}, nil
+		},
+	}
+
+	open := &mockProvider{
+		name: "open",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, circuitbreaker.ErrCircuitOpen
+		},
+	}
+
+	slow := &mockProvider{
+		name: "slow",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			time.Sleep(300 * time.Millisecond)
+			return []domain.POI{{ID: "2", Name: "Slow"}}, nil
+		},
+	}
+
+	orchestrator := NewParallel(
+		[]provider.Provider{working, open, slow},
+		100*time.Millisecond,
+	)
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Complete {
+		t.Error("Expected degraded result")
+	}
+
+	want := map[string]string{
+		"working": domain.ProviderStatusOK,
+		"open":    domain.ProviderStatusCircuitOpen,
+		"slow":    domain.ProviderStatusTimeout,
+	}
+
+	for _, s := range result.Providers {
+		if want[s.Provider] != s.Status {
+			t.Errorf("Provider %s: expected status %s, got %s", s.Provider, want[s.Provider], s.Status)
+		}
+	}
+
+	if result.Providers[0].ResultCount != 1 {
+		t.Errorf("Expected result count 1 for working provider, got %d", result.Providers[0].ResultCount)
+	}
+}
+
+func TestParallelOrchestrator_StatusComplete(t *testing.T) {
+	provider1 := &mockProvider{
+		name: "provider1",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Name: "Result 1"}}, nil
+		},
+	}
+
+	orchestrator := NewParallel([]provider.Provider{provider1}, 1*time.Second)
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Complete {
+		t.Errorf("Expected complete result, got %+v", result.Providers)
+	}
+}
+
+func TestParallelOrchestrator_StreamReportsEachProvider(t *testing.T) {
+
+	fast := &mockProvider{
+		name: "fast",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Name: "Fast", Source: "fast"}}, nil
+		},
+	}
+
+	slow := &mockProvider{
+		name: "slow",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, errors.New("boom")
+		},
+	}
+
+	o := NewParallel([]provider.Provider{fast, slow}, time.Second)
+
+	var updates []ProviderUpdate
+
+	result, err := o.SearchStream(context.Background(), domain.SearchQuery{}, func(u ProviderUpdate) {
+		updates = append(updates, u)
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %d", len(updates))
+	}
+
+	if updates[0].Status.Provider != "fast" || len(updates[0].POIs) != 1 {
+		t.Errorf("Expected fast provider with 1 POI first, got %+v", updates[0])
+	}
+
+	if updates[1].Status.Status != domain.ProviderStatusError {
+		t.Errorf("Expected slow provider error, got %s", updates[1].Status.Status)
+	}
+
+	if result.Complete {
+		t.Error("Expected incomplete result")
+	}
+}
+
+func TestParallelOrchestrator_StreamCancelled(t *testing.T) {
+
+	blocking := &mockProvider{
+		name: "blocking",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		},
+	}
+
+	o := NewParallel([]provider.Provider{blocking}, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+
+	_, err := o.SearchStream(ctx, domain.SearchQuery{}, nil)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected cancellation to return promptly, took %v", time.Since(start))
+	}
+}
+
+type recordingIngester struct {
+	sources []string
+}
+
+func (r *recordingIngester) Ingest(source string, pois []domain.POI) {
+	r.sources = append(r.sources, source)
+}
+
+func TestParallelOrchestrator_IngestsSuccessfulAnswers(t *testing.T) {
+	ok := &mockProvider{
+		name: "ok",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Source: "ok"}}, nil
+		},
+	}
+
+	failing := &mockProvider{
+		name: "failing",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("failed")
+		},
+	}
+
+	ingester := &recordingIngester{}
+
+	orchestrator := NewParallel([]provider.Provider{ok, failing}, time.Second)
+	orchestrator.SetIngester(ingester)
+
+	if _, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(ingester.sources) != 1 || ingester.sources[0] != "ok" {
+		t.Errorf("Expected only the ok answer ingested, got %v", ingester.sources)
+	}
+}
+
+type recordingHealth struct {
+	mu       sync.Mutex
+	statuses map[string]string
+}
+
+func (r *recordingHealth) Observe(status domain.ProviderStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[status.Provider] = status.Status
+}
+
+func TestParallelOrchestrator_ReportsProviderHealth(t *testing.T) {
+	ok := &mockProvider{
+		name: "ok",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Source: "ok"}}, nil
+		},
+	}
+
+	failing := &mockProvider{
+		name: "failing",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("failed")
+		},
+	}
+
+	health := &recordingHealth{statuses: map[string]string{}}
+
+	orchestrator := NewParallel([]provider.Provider{ok, failing}, time.Second)
+	orchestrator.SetHealth(health)
+
+	if _, err := orchestrator.Search(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	if health.statuses["ok"] != domain.ProviderStatusOK || health.statuses["failing"] != domain.ProviderStatusError {
+		t.Errorf("Expected every provider call reported, got %v", health.statuses)
+	}
+}
+
+func TestParallelOrchestrator_CutOffCallsSayNothingAboutHealth(t *testing.T) {
+	slow := provider.NewTimeoutProvider(&mockProvider{
+		name: "slow",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			time.Sleep(200 * time.Millisecond)
+			return nil, nil
+		},
+	}, time.Second)
+
+	health := &recordingHealth{statuses: map[string]string{}}
+
+	orchestrator := NewParallel([]provider.Provider{slow}, 20*time.Millisecond)
+	orchestrator.SetHealth(health)
+
+	orchestrator.Search(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	// let the abandoned call finish
+	time.Sleep(50 * time.Millisecond)
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	if status, found := health.statuses["slow"]; found {
+		t.Errorf("Expected a call cut off by the orchestrator deadline not reported, got %s", status)
+	}
+}
+
+type exhaustedCosts map[string]string
+
+func (c exhaustedCosts) Exhausted(provider string) string {
+	return c[provider]
+}
+
+func TestParallelOrchestrator_SkipsExhaustedProviders(t *testing.T) {
+	free := &mockProvider{
+		name: "free",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Source: "free"}}, nil
+		},
+	}
+
+	paid := &mockProvider{
+		name: "paid",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			t.Error("Expected the exhausted provider not to be called")
+			return nil, nil
+		},
+	}
+
+	orchestrator := NewParallel([]provider.Provider{free, paid}, time.Second)
+	orchestrator.SetCosts(exhaustedCosts{"paid": "spend_budget_exhausted"})
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.POIs) != 1 || result.Complete {
+		t.Errorf("Expected an incomplete result from free, got %+v", result)
+	}
+
+	got := result.Providers[1]
+
+	if got.Status != domain.ProviderStatusSkipped || got.ErrorClass != "spend_budget_exhausted" {
+		t.Errorf("Expected paid skipped as spend_budget_exhausted, got %+v", got)
+	}
+}
+
+func TestParallelOrchestrator_FallbackWhenAllFail(t *testing.T) {
+	failing := &mockProvider{
+		name: "failing",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("failed")
+		},
+	}
+
+	store := &mockProvider{
+		name: "store",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1", Source: "failing"}}, nil
+		},
+	}
+
+	orchestrator := NewParallel([]provider.Provider{failing}, time.Second)
+	orchestrator.SetFallback(store)
+
+	result, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.POIs) != 1 {
+		t.Errorf("Expected 1 stored POI, got %d", len(result.POIs))
+	}
+
+	if result.Complete {
+		t.Error("Expected fallback result to be incomplete")
+	}
+
+	if len(result.Providers) != 2 || result.Providers[1].Provider != "store" {
+		t.Errorf("Expected store status appended, got %+v", result.Providers)
+	}
+}
+
+func TestParallelOrchestrator_EmptyFallbackFails(t *testing.T) {
+	failing := &mockProvider{
+		name: "failing",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("failed")
+		},
+	}
+
+	store := &mockProvider{
+		name: "store",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, nil
+		},
+	}
+
+	orchestrator := NewParallel([]provider.Provider{failing}, time.Second)
+	orchestrator.SetFallback(store)
+
+	_, err := orchestrator.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if !errors.Is(err, ErrAllProvidersFailed) {
+		t.Errorf("Expected ErrAllProvidersFailed, got %v", err)
 	}
 }
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// replayProviders builds the production provider stack answering from the
+// fixtures in testdata/fixtures, recorded around Prague's Old Town Square.
+func replayProviders() []provider.Provider {
+
+	cb := config.CircuitBreakerConfig{
+		Window:         time.Minute,
+		FailureRate:    0.5,
+		MinRequests:    10,
+		ResetTimeout:   30 * time.Second,
+		HalfOpenProbes: 1,
+	}
+
+	cfg := config.ProvidersConfig{
+		OSM:        config.ProviderConfig{Enabled: true, Timeout: time.Second, CircuitBreaker: cb},
+		Google:     config.GoogleProviderConfig{Enabled: true, Timeout: time.Second, CircuitBreaker: cb},
+		Foursquare: config.FoursquareProviderConfig{Enabled: true, Timeout: time.Second, CircuitBreaker: cb},
+		Fixtures:   config.FixturesConfig{Mode: "replay", Dir: "testdata/fixtures"},
+	}
+
+	var providers []provider.Provider
+
+	for _, rp := range provider.BuildProviders(cfg, nil) {
+		providers = append(providers, rp.Provider)
+	}
+
+	return providers
+}
+
+var oldTownSquare = domain.SearchQuery{
+	Latitude:   50.0870,
+	Longitude:  14.4208,
+	Radius:     300,
+	Limit:      10,
+	Categories: []string{"restaurant"},
+}
+
+func TestParallelOrchestrator_ReplayedProviders(t *testing.T) {
+
+	o := NewParallel(replayProviders(), 2*time.Second)
+
+	result, err := o.SearchWithStatus(oldTownSquare)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Complete {
+		t.Errorf("Expected a complete result, degraded: %v", result.DegradedProviders())
+	}
+
+	var names []string
+
+	for _, poi := range result.POIs {
+		names = append(names, poi.Name)
+	}
+
+	sort.Strings(names)
+
+	// U Prince comes from all three providers and Mincovna from two
+	want := "Café Mozart,Lokál U Bílé kuželky,Mincovna,Restaurace U Provaznice,U Prince"
+
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestParallelOrchestrator_ReplayUnrecordedQuery(t *testing.T) {
+
+	o := NewParallel(replayProviders(), 2*time.Second)
+
+	query := oldTownSquare
+	query.Radius = 1000
+
+	_, err := o.SearchWithStatus(query)
+
+	if !errors.Is(err, ErrAllProvidersFailed) {
+		t.Errorf("Expected ErrAllProvidersFailed, got %v", err)
+	}
+}
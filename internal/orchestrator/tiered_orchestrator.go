@@ -0,0 +1,245 @@
+package orchestrator
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/dedupe"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// completenessFields are the POI fields a TierPolicy can require.
+var completenessFields = map[string]func(domain.POI) bool{
+	"rating":        func(p domain.POI) bool { return p.Rating > 0 },
+	"opening_hours": func(p domain.POI) bool { return len(p.OpeningHours) > 0 },
+	"website":       func(p domain.POI) bool { return p.Website != "" },
+	"phone":         func(p domain.POI) bool { return p.Phone != "" },
+	"address":       func(p domain.POI) bool { return p.Address != "" },
+}
+
+// TierPolicy decides when a tiered search escalates to the next tier.
+type TierPolicy struct {
+	// MinResults is the number of places a search should find, or the
+	// query's limit when that is lower.
+	MinResults int
+
+	// MinCompleteness is the share of places that should have every one
+	// of CompletenessFields set. Unknown fields are ignored.
+	MinCompleteness    float64
+	CompletenessFields []string
+
+	// Categories always escalate, e.g. restaurants, whose ratings and
+	// hours free data rarely has.
+	Categories []string
+
+	// EscalationTime is kept back from the deadline for every tier still
+	// to come, so each escalation gets at least this long.
+	EscalationTime time.Duration
+}
+
+// escalation returns why a search that got r so far should ask the next
+// tier, or "" when r is good enough.
+func (p TierPolicy) escalation(query domain.SearchQuery, r round) string {
+
+	answered := false
+
+	for _, s := range r.statuses {
+		if s.Status == domain.ProviderStatusOK {
+			answered = true
+			break
+		}
+	}
+
+	if !answered {
+		return "no_answer"
+	}
+
+	for _, category := range query.Categories {
+		for _, escalated := range p.Categories {
+			if strings.EqualFold(category, escalated) {
+				return "category"
+			}
+		}
+	}
+
+	// count places as they will be served, one per duplicate group
+	groups := dedupe.Group(r.pois)
+
+	want := p.MinResults
+
+	if query.Limit > 0 && query.Limit < want {
+		want = query.Limit
+	}
+
+	if len(groups) < want {
+		return "results"
+	}
+
+	if len(groups) == 0 || p.MinCompleteness <= 0 {
+		return ""
+	}
+
+	complete := 0
+
+	for _, group := range groups {
+		if p.complete(group[0]) {
+			complete++
+		}
+	}
+
+	if float64(complete)/float64(len(groups)) < p.MinCompleteness {
+		return "completeness"
+	}
+
+	return ""
+}
+
+func (p TierPolicy) complete(poi domain.POI) bool {
+
+	for _, field := range p.CompletenessFields {
+
+		if has, ok := completenessFields[field]; ok && !has(poi) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TieredOrchestrator asks its providers tier by tier, cheapest first, and
+// only asks the next tier while the answers so far fall short of its
+// policy. Each tier is asked the way ParallelOrchestrator asks all its
+// providers, and the answers of every tier asked are merged into one
+// result.
+//
+// All tiers share the parallel orchestrator's timeout. A tier followed by
+// others is cut off early enough to leave EscalationTime for each of
+// them. When the deadline is too close to keep that time back, the first
+// tier gets all of it, and tiers the search would escalate to are
+// reported as skipped with the error class latency_budget, leaving the
+// result incomplete. Tiers the policy did not need are not reported, so
+// a search the cheap tier answered well is complete.
+type TieredOrchestrator struct {
+	parallel *ParallelOrchestrator
+	tiers    [][]provider.Provider
+	policy   TierPolicy
+}
+
+var _ StreamingOrchestrator = (*TieredOrchestrator)(nil)
+
+// NewTiered asks tiers in order with the timeout and the ingester,
+// fallback, identity, health and cost guard of parallel, whose own
+// providers are not used.
+func NewTiered(parallel *ParallelOrchestrator, tiers [][]provider.Provider, policy TierPolicy) *TieredOrchestrator {
+
+	return &TieredOrchestrator{
+		parallel: parallel,
+		tiers:    tiers,
+		policy:   policy,
+	}
+}
+
+func (o *TieredOrchestrator) Search(query domain.SearchQuery) ([]domain.POI, error) {
+
+	result, err := o.SearchWithStatus(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.POIs, nil
+}
+
+func (o *TieredOrchestrator) SearchWithStatus(query domain.SearchQuery) (domain.SearchResult, error) {
+
+	return o.SearchStream(context.Background(), query, nil)
+}
+
+func (o *TieredOrchestrator) SearchStream(
+	parent context.Context,
+	query domain.SearchQuery,
+	observe func(ProviderUpdate),
+) (domain.SearchResult, error) {
+
+	ctx, cancel := context.WithTimeout(parent, o.parallel.timeout)
+	defer cancel()
+
+	start := time.Now()
+	deadline, _ := ctx.Deadline()
+
+	var asked round
+
+	for i, tier := range o.tiers {
+
+		// time kept back for the tiers after this one
+		cutoff := deadline.Add(-time.Duration(len(o.tiers)-1-i) * o.policy.EscalationTime)
+
+		if i > 0 {
+
+			reason := o.policy.escalation(query, asked)
+
+			if reason == "" {
+				break
+			}
+
+			// the tier before was cut off at cutoff-EscalationTime, unless
+			// that was already past when the search started
+			if !cutoff.Add(-o.policy.EscalationTime).After(start) {
+
+				metrics.TierEscalations.WithLabelValues(strconv.Itoa(i+1), "latency_budget").Inc()
+				slog.DebugContext(ctx, "no time left to escalate", "tier", i+1, "reason", reason)
+
+				for _, rest := range o.tiers[i:] {
+					asked.statuses = append(asked.statuses, latencySkipped(rest)...)
+				}
+
+				break
+			}
+
+			metrics.TierEscalations.WithLabelValues(strconv.Itoa(i+1), reason).Inc()
+			slog.DebugContext(ctx, "escalating search", "tier", i+1, "reason", reason)
+		}
+
+		// the first tier is always asked, with all the time there is if
+		// too little is left to keep any back
+		if !cutoff.After(start) {
+			cutoff = deadline
+		}
+
+		tierCtx, tierCancel := context.WithDeadline(ctx, cutoff)
+
+		r, err := o.parallel.ask(parent, tierCtx, tier, time.Until(cutoff), query, observe)
+
+		tierCancel()
+
+		if err != nil {
+			return domain.SearchResult{}, err
+		}
+
+		asked.statuses = append(asked.statuses, r.statuses...)
+		asked.pois = append(asked.pois, r.pois...)
+		asked.skipped += r.skipped
+	}
+
+	return o.parallel.merge(parent, query, asked)
+}
+
+func latencySkipped(providers []provider.Provider) []domain.ProviderStatus {
+
+	statuses := make([]domain.ProviderStatus, len(providers))
+
+	for i, p := range providers {
+		statuses[i] = domain.ProviderStatus{
+			Provider:   p.Name(),
+			Status:     domain.ProviderStatusSkipped,
+			ErrorClass: "latency_budget",
+		}
+	}
+
+	return statuses
+}
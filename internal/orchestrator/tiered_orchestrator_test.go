@@ -0,0 +1,241 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/provider"
+)
+
+// places returns n POIs of source far enough apart not to be merged.
+func places(source string, n int, rated bool) []domain.POI {
+
+	pois := make([]domain.POI, n)
+
+	for i := range pois {
+
+		pois[i] = domain.POI{
+			ID:        fmt.Sprintf("%s-%d", source, i),
+			Name:      fmt.Sprintf("%s place %d", source, i),
+			Latitude:  59.0 + float64(i)*0.01,
+			Longitude: 18.0,
+			Source:    source,
+		}
+
+		if rated {
+			pois[i].Rating = 4.5
+			pois[i].OpeningHours = []string{"Mon-Fri 9-17"}
+		}
+	}
+
+	return pois
+}
+
+func tieredProvider(name string, pois []domain.POI, calls *int32) *mockProvider {
+
+	return &mockProvider{
+		name: name,
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			atomic.AddInt32(calls, 1)
+			return pois, nil
+		},
+	}
+}
+
+func newTiered(timeout time.Duration, policy TierPolicy, tiers ...[]provider.Provider) *TieredOrchestrator {
+
+	return NewTiered(NewParallel(nil, timeout), tiers, policy)
+}
+
+var testPolicy = TierPolicy{
+	MinResults:         3,
+	MinCompleteness:    0.5,
+	CompletenessFields: []string{"rating", "opening_hours"},
+	Categories:         []string{"restaurant"},
+	EscalationTime:     100 * time.Millisecond,
+}
+
+func TestTieredOrchestrator_CheapTierSuffices(t *testing.T) {
+
+	var cheapCalls, paidCalls int32
+
+	o := newTiered(time.Second, testPolicy,
+		[]provider.Provider{tieredProvider("osm", places("osm", 5, true), &cheapCalls)},
+		[]provider.Provider{tieredProvider("google", places("google", 5, true), &paidCalls)},
+	)
+
+	result, err := o.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if paidCalls != 0 {
+		t.Errorf("Expected the paid tier not to be asked, got %d calls", paidCalls)
+	}
+
+	if len(result.POIs) != 5 || !result.Complete || len(result.Providers) != 1 {
+		t.Errorf("Expected a complete result from osm alone, got %d POIs, %+v", len(result.POIs), result.Providers)
+	}
+}
+
+func TestTieredOrchestrator_Escalates(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		cheap  []domain.POI
+		query  domain.SearchQuery
+		reason string
+	}{
+		{"too few results", places("osm", 2, true), domain.SearchQuery{}, "results"},
+		{"incomplete fields", places("osm", 5, false), domain.SearchQuery{}, "completeness"},
+		{"escalated category", places("osm", 5, true), domain.SearchQuery{Categories: []string{"Restaurant"}}, "category"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			var cheapCalls, paidCalls int32
+
+			o := newTiered(time.Second, testPolicy,
+				[]provider.Provider{tieredProvider("osm", tt.cheap, &cheapCalls)},
+				[]provider.Provider{tieredProvider("google", places("google", 5, true), &paidCalls)},
+			)
+
+			query := tt.query
+			query.Latitude, query.Longitude = 59.0, 18.0
+
+			if reason := testPolicy.escalation(query, round{
+				statuses: []domain.ProviderStatus{{Provider: "osm", Status: domain.ProviderStatusOK}},
+				pois:     tt.cheap,
+			}); reason != tt.reason {
+				t.Errorf("Expected escalation for %s, got '%s'", tt.reason, reason)
+			}
+
+			result, err := o.SearchWithStatus(query)
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if paidCalls != 1 || len(result.Providers) != 2 || !result.Complete {
+				t.Errorf("Expected both tiers asked and complete, got %d paid calls, %+v", paidCalls, result.Providers)
+			}
+		})
+	}
+}
+
+func TestTieredOrchestrator_LimitLowersMinResults(t *testing.T) {
+
+	var cheapCalls, paidCalls int32
+
+	o := newTiered(time.Second, testPolicy,
+		[]provider.Provider{tieredProvider("osm", places("osm", 2, true), &cheapCalls)},
+		[]provider.Provider{tieredProvider("google", places("google", 5, true), &paidCalls)},
+	)
+
+	if _, err := o.Search(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0, Limit: 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if paidCalls != 0 {
+		t.Error("Expected a search for 2 places not to escalate")
+	}
+}
+
+func TestTieredOrchestrator_EscalatesWhenCheapTierFails(t *testing.T) {
+
+	var paidCalls int32
+
+	failing := &mockProvider{
+		name: "osm",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("failed")
+		},
+	}
+
+	o := newTiered(time.Second, TierPolicy{EscalationTime: 100 * time.Millisecond},
+		[]provider.Provider{failing},
+		[]provider.Provider{tieredProvider("google", places("google", 1, false), &paidCalls)},
+	)
+
+	result, err := o.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if paidCalls != 1 || len(result.POIs) != 1 || result.Complete {
+		t.Errorf("Expected an incomplete result from google, got %d paid calls, %+v", paidCalls, result)
+	}
+}
+
+func TestTieredOrchestrator_SlowCheapTierLeavesTimeToEscalate(t *testing.T) {
+
+	var paidCalls int32
+
+	slow := &mockProvider{
+		name: "osm",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			time.Sleep(500 * time.Millisecond)
+			return places("osm", 5, true), nil
+		},
+	}
+
+	o := newTiered(300*time.Millisecond, testPolicy,
+		[]provider.Provider{slow},
+		[]provider.Provider{tieredProvider("google", places("google", 5, true), &paidCalls)},
+	)
+
+	start := time.Now()
+
+	result, err := o.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Expected the search to finish within the deadline, took %v", elapsed)
+	}
+
+	if paidCalls != 1 || len(result.POIs) != 5 {
+		t.Errorf("Expected google to answer after osm was cut off, got %d paid calls, %d POIs", paidCalls, len(result.POIs))
+	}
+
+	if got := result.Providers[0]; got.Status != domain.ProviderStatusTimeout || got.LatencyMs > 200 {
+		t.Errorf("Expected osm cut off at 200ms, got %+v", got)
+	}
+}
+
+func TestTieredOrchestrator_NoTimeToEscalate(t *testing.T) {
+
+	var cheapCalls, paidCalls int32
+
+	policy := testPolicy
+	policy.EscalationTime = time.Second
+
+	o := newTiered(100*time.Millisecond, policy,
+		[]provider.Provider{tieredProvider("osm", places("osm", 1, true), &cheapCalls)},
+		[]provider.Provider{tieredProvider("google", places("google", 5, true), &paidCalls)},
+	)
+
+	result, err := o.SearchWithStatus(domain.SearchQuery{Latitude: 59.0, Longitude: 18.0})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if paidCalls != 0 || len(result.POIs) != 1 {
+		t.Errorf("Expected osm's answer only, got %d paid calls, %d POIs", paidCalls, len(result.POIs))
+	}
+
+	if got := result.Providers[1]; result.Complete || got.Status != domain.ProviderStatusSkipped || got.ErrorClass != "latency_budget" {
+		t.Errorf("Expected google skipped for the latency budget, got %+v", result.Providers)
+	}
+}
@@ -0,0 +1,398 @@
+package overrides
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+// ErrFileRule means a rule from the rules file was to be changed through
+// the admin API; edit the file instead.
+var ErrFileRule = errors.New("rule is defined in the rules file")
+
+// ErrNotFound means no admin rule has the given ID.
+var ErrNotFound = errors.New("rule not found")
+
+// ErrInvalidRule wraps validation failures of admin changes.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Engine applies curated rules to search results. Rules come from the
+// rules file and from the admin API; admin rules live in a Store shared
+// by all replicas, and every change to them is written to its audit log.
+type Engine struct {
+	store Store
+
+	mu    sync.RWMutex
+	file  []compiled
+	admin []compiled
+
+	now func() time.Time
+}
+
+func NewEngine(store Store) *Engine {
+
+	return &Engine{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// SetFileRules replaces the rules loaded from the rules file. Nothing is
+// replaced if any rule is invalid or IDs repeat.
+func (e *Engine) SetFileRules(rules []Rule) error {
+
+	seen := map[string]bool{}
+
+	file := make([]compiled, 0, len(rules))
+
+	for _, r := range rules {
+
+		if seen[r.ID] {
+			return fmt.Errorf("rule %s defined twice", r.ID)
+		}
+
+		seen[r.ID] = true
+
+		r.Origin = "file"
+
+		c, err := compile(r)
+
+		if err != nil {
+			return err
+		}
+
+		file = append(file, c)
+	}
+
+	e.mu.Lock()
+	e.file = file
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Refresh reloads the admin rules from the store. Invalid rules are
+// skipped and logged.
+func (e *Engine) Refresh(ctx context.Context) error {
+
+	rules, err := e.store.Rules(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	admin := make([]compiled, 0, len(rules))
+
+	for _, r := range rules {
+
+		r.Origin = "admin"
+
+		c, err := compile(r)
+
+		if err != nil {
+			log.Printf("overrides: skipping stored rule: %v", err)
+			continue
+		}
+
+		admin = append(admin, c)
+	}
+
+	sort.Slice(admin, func(i, j int) bool { return admin[i].ID < admin[j].ID })
+
+	e.mu.Lock()
+	e.admin = admin
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes the admin rules every interval until ctx is done, so
+// changes made through another replica apply here too.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+
+			if err := e.Refresh(ctx); err != nil {
+				log.Printf("overrides: refresh, keeping previous rules: %v", err)
+			}
+		}
+	}
+}
+
+// Rules lists file rules, then admin rules.
+func (e *Engine) Rules() []Rule {
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules := make([]Rule, 0, len(e.file)+len(e.admin))
+
+	for _, c := range e.file {
+		rules = append(rules, c.Rule)
+	}
+
+	for _, c := range e.admin {
+		rules = append(rules, c.Rule)
+	}
+
+	return rules
+}
+
+// Put creates or replaces an admin rule on behalf of actor and records
+// the change in the audit log.
+func (e *Engine) Put(ctx context.Context, rule Rule, actor string) (Rule, error) {
+
+	if e.isFileRule(rule.ID) {
+		return Rule{}, ErrFileRule
+	}
+
+	rule.Author = actor
+	rule.UpdatedAt = e.now().UTC()
+	rule.Origin = ""
+
+	if _, err := compile(rule); err != nil {
+		return Rule{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	operation := "create"
+
+	if e.isAdminRule(rule.ID) {
+		operation = "update"
+	}
+
+	entry := AuditEntry{
+		Time:      rule.UpdatedAt,
+		Actor:     actor,
+		Operation: operation,
+		RuleID:    rule.ID,
+		Reason:    rule.Reason,
+		Rule:      &rule,
+	}
+
+	if err := e.store.Put(ctx, rule, entry); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, e.Refresh(ctx)
+}
+
+// Delete removes an admin rule on behalf of actor and records the change
+// in the audit log.
+func (e *Engine) Delete(ctx context.Context, id string, reason string, actor string) error {
+
+	if e.isFileRule(id) {
+		return ErrFileRule
+	}
+
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidRule)
+	}
+
+	entry := AuditEntry{
+		Time:      e.now().UTC(),
+		Actor:     actor,
+		Operation: "delete",
+		RuleID:    id,
+		Reason:    reason,
+	}
+
+	found, err := e.store.Delete(ctx, id, entry)
+
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrNotFound
+	}
+
+	return e.Refresh(ctx)
+}
+
+// Audit returns the newest limit audit entries, newest first.
+func (e *Engine) Audit(ctx context.Context, limit int) ([]AuditEntry, error) {
+
+	return e.store.Audit(ctx, limit)
+}
+
+func (e *Engine) isFileRule(id string) bool {
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, c := range e.file {
+		if c.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Engine) isAdminRule(id string) bool {
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, c := range e.admin {
+		if c.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply hides, patches, injects and pins POIs for query. Hide and patch
+// rules match POIs as providers returned them; pin rules match the final
+// POIs, so injected ones can be pinned too. Patched POIs moved out of the
+// query area are dropped, and pinned POIs get their position in
+// POI.Pinned for ranking.Rank to honour.
+func (e *Engine) Apply(query domain.SearchQuery, pois []domain.POI) []domain.POI {
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if len(e.file) == 0 && len(e.admin) == 0 {
+		return pois
+	}
+
+	rules := make([]compiled, 0, len(e.file)+len(e.admin))
+	rules = append(rules, e.file...)
+	rules = append(rules, e.admin...)
+
+	out := make([]domain.POI, 0, len(pois))
+
+	present := map[string]bool{}
+
+	for _, poi := range pois {
+
+		if hidden(rules, poi) {
+			metrics.OverridesApplied.WithLabelValues(string(ActionHide)).Inc()
+			continue
+		}
+
+		patched := poi
+		changed := false
+
+		for _, r := range rules {
+			if r.Action == ActionPatch && r.matches(poi) {
+				r.Patch.apply(&patched)
+				changed = true
+				metrics.OverridesApplied.WithLabelValues(string(ActionPatch)).Inc()
+			}
+		}
+
+		if changed && !cache.Contains(query, patched) {
+			continue
+		}
+
+		present[patched.Source+"/"+patched.ID] = true
+
+		out = append(out, patched)
+	}
+
+	for _, r := range rules {
+
+		if r.Action != ActionAdd {
+			continue
+		}
+
+		poi := *r.POI
+
+		if poi.Source == "" {
+			poi.Source = CustomSource
+		}
+
+		if present[poi.Source+"/"+poi.ID] || !wanted(query, poi) {
+			continue
+		}
+
+		present[poi.Source+"/"+poi.ID] = true
+
+		out = append(out, poi)
+
+		metrics.OverridesApplied.WithLabelValues(string(ActionAdd)).Inc()
+	}
+
+	for i := range out {
+		for _, r := range rules {
+
+			if r.Action != ActionPin || !r.matches(out[i]) {
+				continue
+			}
+
+			if out[i].Pinned == 0 || r.Position < out[i].Pinned {
+				out[i].Pinned = r.Position
+			}
+
+			metrics.OverridesApplied.WithLabelValues(string(ActionPin)).Inc()
+		}
+	}
+
+	return out
+}
+
+func hidden(rules []compiled, poi domain.POI) bool {
+
+	for _, r := range rules {
+		if r.Action == ActionHide && r.matches(poi) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wanted reports whether an injected POI belongs in the results for query.
+func wanted(query domain.SearchQuery, poi domain.POI) bool {
+
+	if !cache.Contains(query, poi) {
+		return false
+	}
+
+	if len(query.Categories) == 0 {
+		return true
+	}
+
+	for _, c := range query.Categories {
+		if strings.EqualFold(c, poi.Category) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Patch) apply(poi *domain.POI) {
+
+	if p.Name != nil {
+		poi.Name = *p.Name
+	}
+
+	if p.Category != nil {
+		poi.Category = *p.Category
+	}
+
+	if p.Latitude != nil {
+		poi.Latitude, poi.Longitude = *p.Latitude, *p.Longitude
+	}
+}
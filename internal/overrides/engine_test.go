@@ -0,0 +1,263 @@
+package overrides
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+type memoryStore struct {
+	rules map[string]Rule
+	audit []AuditEntry
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{rules: map[string]Rule{}}
+}
+
+func (s *memoryStore) Rules(ctx context.Context) ([]Rule, error) {
+
+	var rules []Rule
+
+	for _, r := range s.rules {
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+func (s *memoryStore) Put(ctx context.Context, rule Rule, entry AuditEntry) error {
+
+	s.rules[rule.ID] = rule
+	s.audit = append([]AuditEntry{entry}, s.audit...)
+
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, id string, entry AuditEntry) (bool, error) {
+
+	if _, ok := s.rules[id]; !ok {
+		return false, nil
+	}
+
+	delete(s.rules, id)
+	s.audit = append([]AuditEntry{entry}, s.audit...)
+
+	return true, nil
+}
+
+func (s *memoryStore) Audit(ctx context.Context, limit int) ([]AuditEntry, error) {
+
+	if len(s.audit) > limit {
+		return s.audit[:limit], nil
+	}
+
+	return s.audit, nil
+}
+
+var stockholm = domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}
+
+func results() []domain.POI {
+	return []domain.POI{
+		{ID: "1", Name: "Closed Cafe", Latitude: 59.3293, Longitude: 18.0686, Category: "cafe", Source: "osm"},
+		{ID: "2", Name: "Wrong Pin Bar", Latitude: 59.3294, Longitude: 18.0687, Category: "bar", Source: "google"},
+		{ID: "3", Name: "Bakery", Latitude: 59.3295, Longitude: 18.0688, Category: "bakery", Source: "osm"},
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestEngine_HidePatchAddPin(t *testing.T) {
+
+	engine := NewEngine(newMemoryStore())
+
+	err := engine.SetFileRules([]Rule{
+		{ID: "hide-closed", Action: ActionHide, Match: Match{Name: "^closed"}, Reason: "closed for good"},
+		{ID: "fix-bar", Action: ActionPatch, Match: Match{Source: "google", ID: "2"}, Patch: Patch{Name: ptr("Right Pin Bar")}, Reason: "typo"},
+		{ID: "ours", Action: ActionAdd, POI: &domain.POI{ID: "hq", Name: "Hynek HQ", Latitude: 59.3296, Longitude: 18.0689, Category: "office"}, Reason: "our office"},
+		{ID: "sponsor", Action: ActionPin, Match: Match{Source: CustomSource, ID: "hq"}, Position: 1, Reason: "sponsored"},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected rules to load, got %v", err)
+	}
+
+	pois := engine.Apply(stockholm, results())
+
+	if len(pois) != 3 {
+		t.Fatalf("Expected 3 POIs after hiding one and adding one, got %+v", pois)
+	}
+
+	byID := map[string]domain.POI{}
+
+	for _, p := range pois {
+		byID[p.ID] = p
+	}
+
+	if _, ok := byID["1"]; ok {
+		t.Error("Expected the closed cafe to be hidden")
+	}
+
+	if byID["2"].Name != "Right Pin Bar" {
+		t.Errorf("Expected patched name, got %q", byID["2"].Name)
+	}
+
+	if hq := byID["hq"]; hq.Source != CustomSource || hq.Pinned != 1 {
+		t.Errorf("Expected pinned custom POI, got %+v", hq)
+	}
+}
+
+func TestEngine_AddRespectsAreaAndCategories(t *testing.T) {
+
+	engine := NewEngine(newMemoryStore())
+
+	engine.SetFileRules([]Rule{
+		{ID: "ours", Action: ActionAdd, POI: &domain.POI{ID: "hq", Name: "Hynek HQ", Latitude: 59.3296, Longitude: 18.0689, Category: "office"}, Reason: "our office"},
+	})
+
+	elsewhere := domain.SearchQuery{Latitude: 57.7089, Longitude: 11.9746, Radius: 1000}
+
+	if pois := engine.Apply(elsewhere, nil); len(pois) != 0 {
+		t.Errorf("Expected no injection outside the area, got %+v", pois)
+	}
+
+	cafes := stockholm
+	cafes.Categories = []string{"cafe"}
+
+	if pois := engine.Apply(cafes, nil); len(pois) != 0 {
+		t.Errorf("Expected no injection for other categories, got %+v", pois)
+	}
+}
+
+func TestEngine_PatchedOutOfAreaIsDropped(t *testing.T) {
+
+	engine := NewEngine(newMemoryStore())
+
+	engine.SetFileRules([]Rule{
+		{ID: "move", Action: ActionPatch, Match: Match{ID: "3"}, Patch: Patch{Latitude: ptr(57.7089), Longitude: ptr(11.9746)}, Reason: "actually in Gothenburg"},
+	})
+
+	for _, p := range engine.Apply(stockholm, results()) {
+		if p.ID == "3" {
+			t.Errorf("Expected moved POI to leave the Stockholm results, got %+v", p)
+		}
+	}
+}
+
+func TestEngine_AdminChangesAreAudited(t *testing.T) {
+
+	store := newMemoryStore()
+	engine := NewEngine(store)
+
+	engine.SetFileRules([]Rule{
+		{ID: "file-rule", Action: ActionHide, Match: Match{ID: "1"}, Reason: "closed"},
+	})
+
+	ctx := context.Background()
+
+	rule := Rule{ID: "hide-bar", Action: ActionHide, Match: Match{Source: "google", ID: "2"}, Reason: "offensive name"}
+
+	if _, err := engine.Put(ctx, rule, "alice"); err != nil {
+		t.Fatalf("Expected put to succeed, got %v", err)
+	}
+
+	if _, err := engine.Put(ctx, rule, "bob"); err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+
+	if err := engine.Delete(ctx, "hide-bar", "", "bob"); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("Expected delete without reason to fail, got %v", err)
+	}
+
+	if err := engine.Delete(ctx, "hide-bar", "reopened under new name", "carol"); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+
+	if _, err := engine.Put(ctx, Rule{ID: "file-rule", Action: ActionHide, Match: Match{ID: "1"}, Reason: "x"}, "alice"); !errors.Is(err, ErrFileRule) {
+		t.Errorf("Expected ErrFileRule, got %v", err)
+	}
+
+	if err := engine.Delete(ctx, "missing", "cleanup", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	entries, _ := engine.Audit(ctx, 10)
+
+	want := []string{"delete/carol", "update/bob", "create/alice"}
+
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d audit entries, got %+v", len(want), entries)
+	}
+
+	for i, e := range entries {
+		if got := e.Operation + "/" + e.Actor; got != want[i] {
+			t.Errorf("Expected audit entry %d to be %s, got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestEngine_RejectsInvalidFileRules(t *testing.T) {
+
+	engine := NewEngine(newMemoryStore())
+
+	engine.SetFileRules([]Rule{{ID: "keep", Action: ActionHide, Match: Match{ID: "1"}, Reason: "closed"}})
+
+	tests := map[string][]Rule{
+		"no reason":      {{ID: "a", Action: ActionHide, Match: Match{ID: "1"}}},
+		"no match":       {{ID: "a", Action: ActionHide, Reason: "x"}},
+		"bad pattern":    {{ID: "a", Action: ActionHide, Match: Match{Name: "("}, Reason: "x"}},
+		"pin position":   {{ID: "a", Action: ActionPin, Match: Match{ID: "1"}, Reason: "x"}},
+		"half patch":     {{ID: "a", Action: ActionPatch, Match: Match{ID: "1"}, Patch: Patch{Latitude: ptr(1.0)}, Reason: "x"}},
+		"duplicate ids":  {{ID: "a", Action: ActionHide, Match: Match{ID: "1"}, Reason: "x"}, {ID: "a", Action: ActionHide, Match: Match{ID: "2"}, Reason: "x"}},
+		"unknown action": {{ID: "a", Action: "boost", Match: Match{ID: "1"}, Reason: "x"}},
+	}
+
+	for name, rules := range tests {
+		if err := engine.SetFileRules(rules); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if rules := engine.Rules(); len(rules) != 1 || rules[0].ID != "keep" {
+		t.Errorf("Expected previous rules kept, got %+v", rules)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+
+	os.WriteFile(path, []byte(`
+- id: hide-closed
+  action: hide
+  match:
+    source: osm
+    id: "123"
+  reason: closed permanently
+- id: ours
+  action: add
+  poi:
+    id: hq
+    name: Hynek HQ
+    latitude: 59.3296
+    longitude: 18.0689
+    category: office
+  reason: our office
+`), 0o600)
+
+	rules, err := LoadFile(path)
+
+	if err != nil {
+		t.Fatalf("Expected file to load, got %v", err)
+	}
+
+	if len(rules) != 2 || rules[0].Match.ID != "123" || rules[1].POI.Latitude != 59.3296 {
+		t.Errorf("Expected both rules with their fields, got %+v", rules)
+	}
+}
@@ -0,0 +1,42 @@
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// LoadFile reads rules from a YAML (or JSON) file holding a list of
+// rules. Field names are those of the admin API.
+func LoadFile(path string) ([]Rule, error) {
+
+	raw, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("overrides file: %w", err)
+	}
+
+	var doc interface{}
+
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("overrides file: %w", err)
+	}
+
+	// round-trip through JSON so the file uses the json field names of
+	// Rule and domain.POI
+	converted, err := json.Marshal(doc)
+
+	if err != nil {
+		return nil, fmt.Errorf("overrides file: %w", err)
+	}
+
+	var rules []Rule
+
+	if err := json.Unmarshal(converted, &rules); err != nil {
+		return nil, fmt.Errorf("overrides file: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,199 @@
+package overrides
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// Action is what a rule does to the POIs it matches.
+type Action string
+
+const (
+	// ActionHide removes matching POIs from results.
+	ActionHide Action = "hide"
+
+	// ActionPatch overwrites fields of matching POIs.
+	ActionPatch Action = "patch"
+
+	// ActionPin ranks matching POIs above all others.
+	ActionPin Action = "pin"
+
+	// ActionAdd injects a custom POI into searches covering it.
+	ActionAdd Action = "add"
+)
+
+// CustomSource is the source of injected POIs that do not name one.
+const CustomSource = "custom"
+
+// Rule is one curated change to search results.
+type Rule struct {
+	ID     string `json:"id"`
+	Action Action `json:"action"`
+
+	// Match selects the POIs hide, patch and pin apply to.
+	Match Match `json:"match"`
+
+	// Patch holds the fields set by patch rules.
+	Patch Patch `json:"patch"`
+
+	// Position orders pinned POIs; 1 is first.
+	Position int `json:"position,omitempty"`
+
+	// POI is the POI an add rule injects.
+	POI *domain.POI `json:"poi,omitempty"`
+
+	Reason    string    `json:"reason"`
+	Author    string    `json:"author,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Origin is "file" or "admin", set when rules are loaded.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Match selects POIs. Every field set must match. Name is a
+// case-insensitive regular expression; BBox is min_lat, min_lng,
+// max_lat, max_lng.
+type Match struct {
+	Source   string    `json:"source,omitempty"`
+	ID       string    `json:"id,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Category string    `json:"category,omitempty"`
+	BBox     []float64 `json:"bbox,omitempty"`
+}
+
+// Patch lists the fields a patch rule sets; nil fields are kept.
+type Patch struct {
+	Name      *string  `json:"name,omitempty"`
+	Category  *string  `json:"category,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// Validate reports whether r can be applied.
+func (r Rule) Validate() error {
+
+	if r.ID == "" {
+		return errors.New("rule id is required")
+	}
+
+	if strings.TrimSpace(r.Reason) == "" {
+		return fmt.Errorf("rule %s: reason is required", r.ID)
+	}
+
+	switch r.Action {
+
+	case ActionHide, ActionPatch, ActionPin:
+
+		if r.Match.empty() {
+			return fmt.Errorf("rule %s: %s needs a match", r.ID, r.Action)
+		}
+
+	case ActionAdd:
+
+		if r.POI == nil || r.POI.ID == "" || r.POI.Name == "" {
+			return fmt.Errorf("rule %s: add needs a poi with id and name", r.ID)
+		}
+
+		if !validCoordinates(r.POI.Latitude, r.POI.Longitude) {
+			return fmt.Errorf("rule %s: poi coordinates out of range", r.ID)
+		}
+
+	default:
+		return fmt.Errorf("rule %s: unknown action %q", r.ID, r.Action)
+	}
+
+	if r.Action == ActionPatch && r.Patch.empty() {
+		return fmt.Errorf("rule %s: patch sets no field", r.ID)
+	}
+
+	if (r.Patch.Latitude == nil) != (r.Patch.Longitude == nil) {
+		return fmt.Errorf("rule %s: patch latitude and longitude go together", r.ID)
+	}
+
+	if r.Patch.Latitude != nil && !validCoordinates(*r.Patch.Latitude, *r.Patch.Longitude) {
+		return fmt.Errorf("rule %s: patch coordinates out of range", r.ID)
+	}
+
+	if r.Action == ActionPin && r.Position < 1 {
+		return fmt.Errorf("rule %s: pin position must be at least 1", r.ID)
+	}
+
+	if len(r.Match.BBox) != 0 && len(r.Match.BBox) != 4 {
+		return fmt.Errorf("rule %s: match.bbox needs min_lat, min_lng, max_lat, max_lng", r.ID)
+	}
+
+	if r.Match.Name != "" {
+		if _, err := regexp.Compile(r.Match.Name); err != nil {
+			return fmt.Errorf("rule %s: match.name: %w", r.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (m Match) empty() bool {
+	return m.Source == "" && m.ID == "" && m.Name == "" && m.Category == "" && len(m.BBox) == 0
+}
+
+func (p Patch) empty() bool {
+	return p.Name == nil && p.Category == nil && p.Latitude == nil
+}
+
+func validCoordinates(lat float64, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// compiled is a validated rule with its name pattern compiled.
+type compiled struct {
+	Rule
+	name *regexp.Regexp
+}
+
+func compile(r Rule) (compiled, error) {
+
+	if err := r.Validate(); err != nil {
+		return compiled{}, err
+	}
+
+	c := compiled{Rule: r}
+
+	if r.Match.Name != "" {
+		c.name = regexp.MustCompile("(?i)" + r.Match.Name)
+	}
+
+	return c, nil
+}
+
+func (c compiled) matches(poi domain.POI) bool {
+
+	m := c.Match
+
+	if m.Source != "" && !strings.EqualFold(m.Source, poi.Source) {
+		return false
+	}
+
+	if m.ID != "" && m.ID != poi.ID {
+		return false
+	}
+
+	if m.Category != "" && !strings.EqualFold(m.Category, poi.Category) {
+		return false
+	}
+
+	if c.name != nil && !c.name.MatchString(poi.Name) {
+		return false
+	}
+
+	if len(m.BBox) == 4 {
+		if poi.Latitude < m.BBox[0] || poi.Longitude < m.BBox[1] || poi.Latitude > m.BBox[2] || poi.Longitude > m.BBox[3] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,154 @@
+package overrides
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AuditEntry records one change to the admin rules.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`
+	Operation string    `json:"operation"`
+	RuleID    string    `json:"rule_id"`
+	Reason    string    `json:"reason"`
+
+	// Rule is the rule as written; empty for deletions.
+	Rule *Rule `json:"rule,omitempty"`
+}
+
+// Store keeps admin rules and their audit log, shared by all replicas.
+type Store interface {
+	Rules(ctx context.Context) ([]Rule, error)
+
+	// Put writes rule and appends entry to the audit log together.
+	Put(ctx context.Context, rule Rule, entry AuditEntry) error
+
+	// Delete removes the rule and appends entry to the audit log; false
+	// means there was no such rule and nothing was logged.
+	Delete(ctx context.Context, id string, entry AuditEntry) (bool, error)
+
+	Audit(ctx context.Context, limit int) ([]AuditEntry, error)
+}
+
+const (
+	rulesKey = "hynek-poi:overrides:rules"
+	auditKey = "hynek-poi:overrides:audit"
+
+	// maxAuditEntries bounds the audit log; older entries are trimmed.
+	maxAuditEntries = 10000
+)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Rules(ctx context.Context) ([]Rule, error) {
+
+	values, err := s.client.HGetAll(ctx, rulesKey).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(values))
+
+	for _, raw := range values {
+
+		var r Rule
+
+		if err := json.Unmarshal([]byte(raw), &r); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+func (s *RedisStore) Put(ctx context.Context, rule Rule, entry AuditEntry) error {
+
+	rawRule, err := json.Marshal(rule)
+
+	if err != nil {
+		return err
+	}
+
+	rawEntry, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	// a pipeline rather than MULTI, since the keys may sit in different
+	// cluster slots
+	pipe := s.client.Pipeline()
+
+	pipe.HSet(ctx, rulesKey, rule.ID, rawRule)
+	pipe.LPush(ctx, auditKey, rawEntry)
+	pipe.LTrim(ctx, auditKey, 0, maxAuditEntries-1)
+
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string, entry AuditEntry) (bool, error) {
+
+	removed, err := s.client.HDel(ctx, rulesKey, id).Result()
+
+	if err != nil || removed == 0 {
+		return false, err
+	}
+
+	rawEntry, err := json.Marshal(entry)
+
+	if err != nil {
+		return true, err
+	}
+
+	pipe := s.client.Pipeline()
+
+	pipe.LPush(ctx, auditKey, rawEntry)
+	pipe.LTrim(ctx, auditKey, 0, maxAuditEntries-1)
+
+	_, err = pipe.Exec(ctx)
+
+	return true, err
+}
+
+func (s *RedisStore) Audit(ctx context.Context, limit int) ([]AuditEntry, error) {
+
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	values, err := s.client.LRange(ctx, auditKey, 0, int64(limit-1)).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, 0, len(values))
+
+	for _, raw := range values {
+
+		var e AuditEntry
+
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,56 @@
+package poistore
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// compactFreeRatio triggers a compaction once free pages take up this
+// share of the file.
+const compactFreeRatio = 0.5
+
+// Maintain prunes records not seen within retention every interval, and
+// compacts the file when pruning has left much of it free, until ctx is
+// done. A zero retention keeps records forever.
+func (s *Store) Maintain(ctx context.Context, retention time.Duration, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			s.maintain(retention)
+		}
+	}
+}
+
+func (s *Store) maintain(retention time.Duration) {
+
+	if retention > 0 {
+
+		removed, err := s.Prune(time.Now().Add(-retention))
+
+		if err != nil {
+			log.Printf("poi store: prune: %v", err)
+			return
+		}
+
+		if removed > 0 {
+			log.Printf("poi store: pruned %d records not seen for %s", removed, retention)
+		}
+	}
+
+	if s.freeRatio() < compactFreeRatio {
+		return
+	}
+
+	if err := s.Compact(); err != nil {
+		log.Printf("poi store: %v", err)
+	}
+}
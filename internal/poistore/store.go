@@ -0,0 +1,484 @@
+package poistore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/geo"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/mmcloughlin/geohash"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Name is the provider name the store answers under.
+const Name = "store"
+
+// geohashPrecision (~5m cells) keys the spatial index. Queries scan the
+// prefixes of their cache tiles, so any tile precision can be served.
+const geohashPrecision = 9
+
+// ingestQueue bounds the provider answers waiting to be written; answers
+// arriving while it is full are dropped.
+const ingestQueue = 256
+
+var (
+	poisBucket = []byte("pois")
+	geoBucket  = []byte("geo")
+)
+
+// Record is one source's version of a POI and when it was seen.
+type Record struct {
+	POI       domain.POI `json:"poi"`
+	Geohash   string     `json:"geohash"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	Sightings int        `json:"sightings"`
+}
+
+type batch struct {
+	source string
+	pois   []domain.POI
+	seen   time.Time
+}
+
+// Store persists every POI providers return in an embedded bbolt file.
+// Records are keyed by source and ID, so each provider's version is kept
+// with its own first and last sighting, and a geohash index serves area
+// queries. Writes happen in the background and never block searches.
+type Store struct {
+	// mu guards db against Compact swapping the file.
+	mu   sync.RWMutex
+	db   *bolt.DB
+	path string
+
+	// queueMu guards queue against Close closing it under Ingest.
+	queueMu sync.RWMutex
+	closed  bool
+
+	queue chan batch
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Open opens or creates the store at path, creating missing directories.
+func Open(path string) (*Store, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("open poi store: %w", err)
+	}
+
+	db, err := openDB(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		db:    db,
+		path:  path,
+		queue: make(chan batch, ingestQueue),
+		done:  make(chan struct{}),
+	}
+
+	go s.writeLoop()
+
+	return s, nil
+}
+
+func openDB(path string) (*bolt.DB, error) {
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+
+	if err != nil {
+		return nil, fmt.Errorf("open poi store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+
+		for _, name := range [][]byte{poisBucket, geoBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open poi store: %w", err)
+	}
+
+	return db, nil
+}
+
+// Close writes queued answers and closes the file.
+func (s *Store) Close() error {
+
+	s.once.Do(func() {
+
+		s.queueMu.Lock()
+		defer s.queueMu.Unlock()
+
+		s.closed = true
+		close(s.queue)
+	})
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Close()
+}
+
+// Ingest queues one provider's answer for writing. Answers the store gave
+// itself are ignored, so serving from it does not refresh its records,
+// and answers arriving after Close are dropped.
+func (s *Store) Ingest(source string, pois []domain.POI) {
+
+	if source == Name || len(pois) == 0 {
+		return
+	}
+
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+
+	if s.closed {
+		metrics.StoreIngested.WithLabelValues("dropped").Add(float64(len(pois)))
+		return
+	}
+
+	select {
+
+	case s.queue <- batch{source: source, pois: pois, seen: time.Now()}:
+
+	default:
+		metrics.StoreIngested.WithLabelValues("dropped").Add(float64(len(pois)))
+	}
+}
+
+func (s *Store) writeLoop() {
+
+	defer close(s.done)
+
+	for b := range s.queue {
+
+		if err := s.write(b); err != nil {
+			metrics.StoreIngested.WithLabelValues("failed").Add(float64(len(b.pois)))
+			log.Printf("poi store: ingest %s: %v", b.source, err)
+			continue
+		}
+
+		metrics.StoreIngested.WithLabelValues("stored").Add(float64(len(b.pois)))
+	}
+}
+
+func (s *Store) write(b batch) error {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+
+		pois := tx.Bucket(poisBucket)
+		index := tx.Bucket(geoBucket)
+
+		for _, poi := range b.pois {
+
+			if poi.ID == "" {
+				continue
+			}
+
+			if poi.Source == "" {
+				poi.Source = b.source
+			}
+
+			key := recordKey(poi.Source, poi.ID)
+
+			record := Record{FirstSeen: b.seen}
+
+			if raw := pois.Get(key); raw != nil {
+
+				if err := json.Unmarshal(raw, &record); err == nil && record.Geohash != "" {
+					if err := index.Delete(indexKey(record.Geohash, key)); err != nil {
+						return err
+					}
+				}
+			}
+
+			record.POI = poi
+			record.Geohash = geohash.EncodeWithPrecision(poi.Latitude, poi.Longitude, geohashPrecision)
+			record.LastSeen = b.seen
+			record.Sightings++
+
+			raw, err := json.Marshal(record)
+
+			if err != nil {
+				return err
+			}
+
+			if err := pois.Put(key, raw); err != nil {
+				return err
+			}
+
+			if err := index.Put(indexKey(record.Geohash, key), nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Name implements provider.Provider.
+func (s *Store) Name() string {
+	return Name
+}
+
+// Search implements provider.Provider with the stored POIs inside the
+// query area and categories, nearest first, up to query.Limit.
+func (s *Store) Search(query domain.SearchQuery) ([]domain.POI, error) {
+
+	records, err := s.Query(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pois := make([]domain.POI, 0, len(records))
+
+	for _, r := range records {
+		pois = append(pois, r.POI)
+	}
+
+	return pois, nil
+}
+
+// Query returns the records inside the query area and categories,
+// nearest first, up to query.Limit.
+func (s *Store) Query(query domain.SearchQuery) ([]Record, error) {
+
+	categories := map[string]bool{}
+
+	for _, c := range query.Categories {
+		categories[c] = true
+	}
+
+	cover, err := cache.CoverQuery(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+
+		pois := tx.Bucket(poisBucket)
+		cursor := tx.Bucket(geoBucket).Cursor()
+
+		for _, tile := range cover.Hashes {
+
+			prefix := []byte(tile)
+
+			for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+
+				raw := pois.Get(k[bytes.IndexByte(k, 0)+1:])
+
+				if raw == nil {
+					continue
+				}
+
+				var r Record
+
+				if err := json.Unmarshal(raw, &r); err != nil {
+					continue
+				}
+
+				if len(categories) > 0 && !categories[r.POI.Category] {
+					continue
+				}
+
+				if cache.Contains(query, r.POI) {
+					records = append(records, r)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	lat, lng := center(query)
+
+	sort.Slice(records, func(i, j int) bool {
+		return geo.DistanceMeters(lat, lng, records[i].POI.Latitude, records[i].POI.Longitude) <
+			geo.DistanceMeters(lat, lng, records[j].POI.Latitude, records[j].POI.Longitude)
+	})
+
+	if query.Limit > 0 && len(records) > query.Limit {
+		records = records[:query.Limit]
+	}
+
+	return records, nil
+}
+
+// Prune deletes records last seen before cutoff and reports how many
+// were removed.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+
+	removed := 0
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+
+		pois := tx.Bucket(poisBucket)
+		index := tx.Bucket(geoBucket)
+
+		var expired [][]byte
+
+		err := pois.ForEach(func(k, v []byte) error {
+
+			var r Record
+
+			if err := json.Unmarshal(v, &r); err != nil || r.LastSeen.Before(cutoff) {
+
+				if err == nil {
+					if err := index.Delete(indexKey(r.Geohash, k)); err != nil {
+						return err
+					}
+				}
+
+				expired = append(expired, bytes.Clone(k))
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := pois.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		removed = len(expired)
+
+		metrics.StoreRecords.Set(float64(pois.Stats().KeyN))
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// Compact rewrites the file without its free pages. bbolt never shrinks
+// a file on its own, so this returns the space pruned records used.
+// Searches and ingestion wait while it runs.
+func (s *Store) Compact() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path + ".compact"
+
+	_ = os.Remove(tmp)
+
+	dst, err := bolt.Open(tmp, 0o600, nil)
+
+	if err != nil {
+		return fmt.Errorf("compact poi store: %w", err)
+	}
+
+	if err := bolt.Compact(dst, s.db, 64<<20); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("compact poi store: %w", err)
+	}
+
+	err = errors.Join(dst.Close(), s.db.Close())
+
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+
+	// reopen whichever file is in place, so a failed swap leaves the
+	// store usable
+	db, openErr := openDB(s.path)
+
+	if openErr != nil {
+		return openErr
+	}
+
+	s.db = db
+
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("compact poi store: %w", err)
+	}
+
+	metrics.StoreCompactions.Inc()
+
+	return nil
+}
+
+// freeRatio is the share of the file taken by free pages.
+func (s *Store) freeRatio() float64 {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info, err := os.Stat(s.path)
+
+	if err != nil || info.Size() == 0 {
+		return 0
+	}
+
+	stats := s.db.Stats()
+
+	free := float64(stats.FreePageN+stats.PendingPageN) * float64(s.db.Info().PageSize)
+
+	return math.Min(free/float64(info.Size()), 1)
+}
+
+func recordKey(source string, id string) []byte {
+
+	return []byte(source + "\x00" + id)
+}
+
+func indexKey(hash string, record []byte) []byte {
+
+	return append([]byte(hash+"\x00"), record...)
+}
+
+func center(query domain.SearchQuery) (float64, float64) {
+
+	if b := query.BBox; b != nil {
+		return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+	}
+
+	return query.Latitude, query.Longitude
+}
@@ -0,0 +1,192 @@
+package poistore
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func openTestStore(t *testing.T) *Store {
+
+	t.Helper()
+
+	s, err := Open(filepath.Join(t.TempDir(), "poi.db"))
+
+	if err != nil {
+		t.Fatalf("Expected store to open, got %v", err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// flush writes queued answers synchronously so tests can query them.
+func flush(t *testing.T, s *Store, source string, pois []domain.POI, seen time.Time) {
+
+	t.Helper()
+
+	if err := s.write(batch{source: source, pois: pois, seen: seen}); err != nil {
+		t.Fatalf("Expected write to succeed, got %v", err)
+	}
+}
+
+func TestStore_KeepsProvenanceAndSightings(t *testing.T) {
+
+	s := openTestStore(t)
+
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+
+	cafe := domain.POI{ID: "1", Name: "Cafe", Latitude: 59.3293, Longitude: 18.0686, Category: "cafe"}
+
+	flush(t, s, "osm", []domain.POI{cafe}, first)
+	flush(t, s, "google", []domain.POI{cafe}, later)
+	flush(t, s, "osm", []domain.POI{cafe}, later)
+
+	records, err := s.Query(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 500})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected one record per source, got %d", len(records))
+	}
+
+	for _, r := range records {
+
+		switch r.POI.Source {
+
+		case "osm":
+
+			if !r.FirstSeen.Equal(first) || !r.LastSeen.Equal(later) || r.Sightings != 2 {
+				t.Errorf("Expected osm seen twice from %v to %v, got %+v", first, later, r)
+			}
+
+		case "google":
+
+			if !r.FirstSeen.Equal(later) || r.Sightings != 1 {
+				t.Errorf("Expected google seen once at %v, got %+v", later, r)
+			}
+
+		default:
+			t.Errorf("Expected source osm or google, got %q", r.POI.Source)
+		}
+	}
+}
+
+func TestStore_SearchFiltersAreaAndCategory(t *testing.T) {
+
+	s := openTestStore(t)
+
+	flush(t, s, "osm", []domain.POI{
+		{ID: "near", Latitude: 59.3293, Longitude: 18.0686, Category: "cafe"},
+		{ID: "bar", Latitude: 59.3294, Longitude: 18.0687, Category: "bar"},
+		{ID: "far", Latitude: 57.7089, Longitude: 11.9746, Category: "cafe"},
+	}, time.Now())
+
+	pois, err := s.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000, Categories: []string{"cafe"}})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(pois) != 1 || pois[0].ID != "near" {
+		t.Errorf("Expected only the nearby cafe, got %+v", pois)
+	}
+}
+
+func TestStore_MovedPOIIsReindexed(t *testing.T) {
+
+	s := openTestStore(t)
+
+	flush(t, s, "osm", []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}}, time.Now())
+	flush(t, s, "osm", []domain.POI{{ID: "1", Latitude: 57.7089, Longitude: 11.9746}}, time.Now())
+
+	old, _ := s.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000})
+
+	if len(old) != 0 {
+		t.Errorf("Expected no POI at the old position, got %+v", old)
+	}
+
+	moved, _ := s.Search(domain.SearchQuery{Latitude: 57.7089, Longitude: 11.9746, Radius: 1000})
+
+	if len(moved) != 1 {
+		t.Errorf("Expected the POI at its new position, got %+v", moved)
+	}
+}
+
+func TestStore_IngestIgnoresOwnAnswers(t *testing.T) {
+
+	s := openTestStore(t)
+
+	s.Ingest(Name, []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}})
+	s.Ingest("osm", []domain.POI{{ID: "2", Latitude: 59.3293, Longitude: 18.0686}})
+
+	// closing drains the queue
+	s.Close()
+
+	reopened, err := Open(s.path)
+
+	if err != nil {
+		t.Fatalf("Expected store to reopen, got %v", err)
+	}
+
+	defer reopened.Close()
+
+	pois, _ := reopened.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 100})
+
+	if len(pois) != 1 || pois[0].ID != "2" {
+		t.Errorf("Expected only the osm answer persisted, got %+v", pois)
+	}
+}
+
+func TestStore_IngestAfterCloseIsDropped(t *testing.T) {
+
+	s := openTestStore(t)
+
+	s.Close()
+
+	// searches still finishing at shutdown must not panic
+	s.Ingest("osm", []domain.POI{{ID: "1", Latitude: 59.3293, Longitude: 18.0686}})
+}
+
+func TestStore_PruneAndCompact(t *testing.T) {
+
+	s := openTestStore(t)
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	var stale []domain.POI
+
+	for i := 0; i < 500; i++ {
+		stale = append(stale, domain.POI{ID: fmt.Sprintf("poi-%d", i), Latitude: 59.3293, Longitude: 18.0686})
+	}
+
+	flush(t, s, "osm", stale, old)
+	flush(t, s, "google", []domain.POI{{ID: "fresh", Latitude: 59.3293, Longitude: 18.0686}}, time.Now())
+
+	removed, err := s.Prune(time.Now().Add(-24 * time.Hour))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if removed != len(stale) {
+		t.Errorf("Expected %d records pruned, got %d", len(stale), removed)
+	}
+
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Expected compaction to succeed, got %v", err)
+	}
+
+	pois, _ := s.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 100})
+
+	if len(pois) != 1 || pois[0].ID != "fresh" {
+		t.Errorf("Expected only the fresh POI after compaction, got %+v", pois)
+	}
+}
@@ -0,0 +1,17 @@
+package provider
+
+import "sort"
+
+// Categories returns the category names accepted in search queries.
+func Categories() []string {
+
+	categories := make([]string, 0, len(osmCategoryMap))
+
+	for category := range osmCategoryMap {
+		categories = append(categories, category)
+	}
+
+	sort.Strings(categories)
+
+	return categories
+}
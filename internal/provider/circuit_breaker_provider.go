@@ -1,6 +1,10 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
@@ -21,20 +25,53 @@ func (p *CircuitBreakerProvider) Name() string {
 	return p.inner.Name()
 }
 
+// Limits implements Limited.
+func (p *CircuitBreakerProvider) Limits() Limits {
+
+	return LimitsOf(p.inner)
+}
+
 func (p *CircuitBreakerProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
-	if !p.cb.Allow() {
-		return nil, circuitbreaker.ErrCircuitOpen
-	}
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *CircuitBreakerProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
 
-	results, err := p.inner.Search(query)
+	done, err := p.cb.Allow()
 
 	if err != nil {
-		p.cb.Failure()
 		return nil, err
 	}
 
-	p.cb.Success()
+	start := time.Now()
+
+	results, err := SearchWithContext(ctx, p.inner, query)
+
+	done(outcome(ctx, err), time.Since(start))
+
+	if err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
+
+// outcome classifies err for the breaker. Calls the caller cancelled or
+// cut off with its own deadline, such as a tier cutoff, say nothing about
+// the provider's health and are not counted. The provider's own timeout,
+// ErrTimeout while ctx is still live, is a failure.
+func outcome(ctx context.Context, err error) circuitbreaker.Outcome {
+
+	switch {
+
+	case err == nil:
+		return circuitbreaker.Success
+
+	case ctx.Err() != nil, errors.Is(err, context.Canceled):
+		return circuitbreaker.Ignored
+
+	default:
+		return circuitbreaker.Failure
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// slowProvider answers after delay unless ctx ends first.
+type slowProvider struct {
+	delay time.Duration
+}
+
+func (p *slowProvider) Name() string {
+	return "slow"
+}
+
+func (p *slowProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *slowProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	select {
+
+	case <-time.After(p.delay):
+		return nil, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func tripsAfterOne() *circuitbreaker.CircuitBreaker {
+
+	return circuitbreaker.New("slow", circuitbreaker.Config{
+		Window:         time.Minute,
+		FailureRate:    0.5,
+		MinRequests:    1,
+		OpenTimeout:    time.Minute,
+		HalfOpenProbes: 1,
+	})
+}
+
+func TestCircuitBreakerProvider_IgnoresCallerDeadline(t *testing.T) {
+
+	cb := tripsAfterOne()
+
+	p := NewCircuitBreakerProvider(NewTimeoutProvider(&slowProvider{delay: time.Second}, time.Second), cb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.SearchContext(ctx, domain.SearchQuery{}); err == nil {
+		t.Fatal("Expected the call to be cut off")
+	}
+
+	if cb.State() != circuitbreaker.StateClosed {
+		t.Errorf("Expected a caller deadline not to count, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerProvider_CountsProviderTimeout(t *testing.T) {
+
+	cb := tripsAfterOne()
+
+	p := NewCircuitBreakerProvider(NewTimeoutProvider(&slowProvider{delay: time.Second}, 10*time.Millisecond), cb)
+
+	if _, err := p.SearchContext(context.Background(), domain.SearchQuery{}); err == nil {
+		t.Fatal("Expected the provider to time out")
+	}
+
+	if cb.State() != circuitbreaker.StateOpen {
+		t.Errorf("Expected the provider's own timeout to count, got %s", cb.State())
+	}
+}
@@ -1,12 +1,14 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/hynek-systems/hynek-poi/internal/config"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
 
@@ -27,6 +29,12 @@ func NewFoursquareProvider(apiKey string) *FoursquareProvider {
 	}
 }
 
+// SetTransport routes the provider's requests through rt, e.g. a
+// fixture recorder or replayer.
+func (p *FoursquareProvider) SetTransport(rt http.RoundTripper) {
+	p.client.Transport = rt
+}
+
 func (p *FoursquareProvider) Name() string {
 
 	return "foursquare"
@@ -90,9 +98,27 @@ type foursquareLatLng struct {
 	Longitude float64 `json:"longitude"`
 }
 
+// SKUs implements Billed. The requested fields include premium ones,
+// which Foursquare bills on top of the search.
+func (p *FoursquareProvider) SKUs() []string {
+	return config.CostSKUs["foursquare"]
+}
+
+// Limits implements Limited.
+func (p *FoursquareProvider) Limits() Limits {
+	return Limits{MaxResults: 50, MaxRadius: 100000}
+}
+
 func (p *FoursquareProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
-	req, err := http.NewRequest("GET", p.endpoint, nil)
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *FoursquareProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	query = p.Limits().Clamp(query)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.endpoint, nil)
 
 	if err != nil {
 		return nil, err
@@ -141,6 +167,10 @@ func (p *FoursquareProvider) Search(query domain.SearchQuery) ([]domain.POI, err
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: foursquare status %d", ErrQuotaExceeded, resp.StatusCode)
+	}
+
 	if resp.StatusCode != 200 {
 
 		return nil, fmt.Errorf("foursquare status %d", resp.StatusCode)
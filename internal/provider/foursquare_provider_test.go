@@ -2,9 +2,11 @@ package provider
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
@@ -255,6 +257,27 @@ func TestFoursquareProvider_SearchNonOKStatus(t *testing.T) {
 	}
 }
 
+func TestFoursquareProvider_SearchQuotaExceeded(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	defer server.Close()
+
+	p := NewFoursquareProvider("test-key")
+	p.endpoint = server.URL
+
+	_, err := p.Search(domain.SearchQuery{
+		Latitude:  59.3293,
+		Longitude: 18.0686,
+	})
+
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+}
+
 func TestFoursquareProvider_SearchNoCategories(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -338,3 +361,51 @@ func TestMapFoursquareCategory(t *testing.T) {
 		}
 	}
 }
+
+func TestFoursquareProvider_ClampsLimitAndRadius(t *testing.T) {
+
+	var limit, radius string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		limit = r.URL.Query().Get("limit")
+		radius = r.URL.Query().Get("radius")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[]}`))
+	}))
+
+	defer server.Close()
+
+	p := NewFoursquareProvider("test-key")
+	p.endpoint = server.URL
+
+	_, err := p.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 400000, Limit: 1800})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if limit != "50" {
+		t.Errorf("Expected limit 50, got %s", limit)
+	}
+
+	if radius != "100000" {
+		t.Errorf("Expected radius 100000, got %s", radius)
+	}
+}
+
+func TestLimitsOf_PassesThroughDecorators(t *testing.T) {
+
+	p := NewRetryProvider(NewTimeoutProvider(NewFoursquareProvider("test-key"), time.Second), 1)
+
+	limits := LimitsOf(p)
+
+	if limits.MaxResults != 50 {
+		t.Errorf("Expected max results 50, got %d", limits.MaxResults)
+	}
+
+	if got := LimitsOf(&MockProvider{}); got != (Limits{}) {
+		t.Errorf("Expected no limits, got %+v", got)
+	}
+}
@@ -1,12 +1,15 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/hynek-systems/hynek-poi/internal/config"
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
 
@@ -27,12 +30,19 @@ func NewGoogleProvider(apiKey string) *GoogleProvider {
 	}
 }
 
+// SetTransport routes the provider's requests through rt, e.g. a
+// fixture recorder or replayer.
+func (p *GoogleProvider) SetTransport(rt http.RoundTripper) {
+	p.client.Transport = rt
+}
+
 func (p *GoogleProvider) Name() string {
 
 	return "google"
 }
 
 type googleResponse struct {
+	Status  string         `json:"status"`
 	Results []googleResult `json:"results"`
 }
 
@@ -61,8 +71,27 @@ type googleOpeningHours struct {
 	WeekdayText []string `json:"weekday_text"`
 }
 
+// SKUs implements Billed. Nearby Search without a field mask bills the
+// contact and atmosphere data of every result as well.
+func (p *GoogleProvider) SKUs() []string {
+	return config.CostSKUs["google"]
+}
+
+// Limits implements Limited. Nearby Search answers at most 20 places
+// per page, and only the first page is read.
+func (p *GoogleProvider) Limits() Limits {
+	return Limits{MaxResults: 20, MaxRadius: 50000}
+}
+
 func (p *GoogleProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *GoogleProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	query = p.Limits().Clamp(query)
+
 	params := url.Values{}
 
 	params.Set("key", p.apiKey)
@@ -87,14 +116,33 @@ func (p *GoogleProvider) Search(query domain.SearchQuery) ([]domain.POI, error)
 
 	reqURL := p.endpoint + "?" + params.Encode()
 
-	resp, err := p.client.Get(reqURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 
 	if err != nil {
 		return nil, err
 	}
 
+	resp, err := p.client.Do(req)
+
+	if err != nil {
+
+		// the request URL carries the API key; keep it out of error
+		// messages and logs
+		var urlErr *url.Error
+
+		if errors.As(err, &urlErr) {
+			urlErr.URL = p.endpoint
+		}
+
+		return nil, err
+	}
+
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: google status %d", ErrQuotaExceeded, resp.StatusCode)
+	}
+
 	if resp.StatusCode != 200 {
 
 		return nil, fmt.Errorf("google status %d", resp.StatusCode)
@@ -108,6 +156,11 @@ func (p *GoogleProvider) Search(query domain.SearchQuery) ([]domain.POI, error)
 		return nil, err
 	}
 
+	// quota errors come back as 200 with a status in the body
+	if gr.Status == "OVER_QUERY_LIMIT" {
+		return nil, fmt.Errorf("%w: google %s", ErrQuotaExceeded, gr.Status)
+	}
+
 	var pois []domain.POI
 
 	for _, r := range gr.Results {
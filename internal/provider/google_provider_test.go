@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func TestGoogleProvider_ErrorsHideAPIKey(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	p := NewGoogleProvider("secret-key")
+	p.endpoint = server.URL
+
+	// the connection is refused, failing with a *url.Error
+	server.Close()
+
+	_, err := p.Search(domain.SearchQuery{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000})
+
+	if err == nil {
+		t.Fatal("Expected an error from a closed server")
+	}
+
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("Expected the API key kept out of the error, got %q", err)
+	}
+}
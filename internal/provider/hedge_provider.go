@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+)
+
+const (
+	hedgeLatencySamples    = 128
+	hedgeMinSamples        = 20
+	hedgeMaxBudgetBalance  = 10.0
+	hedgeBudgetStartCredit = 1.0
+)
+
+// HedgeProvider fires a second request when the first has not answered
+// within a percentile of the primary's observed latency and returns
+// whichever succeeds first. A losing hedge is cancelled; a losing primary
+// runs on until the caller's deadline so its latency is still observed.
+// The hedge goes to alternate, which may be the same provider or another
+// endpoint of it.
+//
+// Hedges are paid for from a budget: every call deposits budget credits
+// and every hedge withdraws one, so hedges stay under that fraction of
+// traffic over time.
+type HedgeProvider struct {
+	primary   Provider
+	alternate Provider
+
+	percentile float64
+	minDelay   time.Duration
+	budget     float64
+
+	state *hedgeState
+}
+
+// hedgeState is what a HedgeProvider learns from its calls: their
+// latencies and the hedge budget left.
+type hedgeState struct {
+	latency latencyWindow
+
+	mu      sync.Mutex
+	balance float64
+}
+
+func newHedgeState() *hedgeState {
+
+	return &hedgeState{balance: hedgeBudgetStartCredit}
+}
+
+func NewHedgeProvider(
+	primary Provider,
+	alternate Provider,
+	percentile float64,
+	minDelay time.Duration,
+	budget float64,
+) *HedgeProvider {
+
+	return newHedgeProvider(primary, alternate, percentile, minDelay, budget, newHedgeState())
+}
+
+// newHedgeProvider is NewHedgeProvider continuing from state, e.g. that
+// of the provider it replaces.
+func newHedgeProvider(
+	primary Provider,
+	alternate Provider,
+	percentile float64,
+	minDelay time.Duration,
+	budget float64,
+	state *hedgeState,
+) *HedgeProvider {
+
+	if alternate == nil {
+		alternate = primary
+	}
+
+	return &HedgeProvider{
+		primary:    primary,
+		alternate:  alternate,
+		percentile: percentile,
+		minDelay:   minDelay,
+		budget:     budget,
+		state:      state,
+	}
+}
+
+func (p *HedgeProvider) Name() string {
+
+	return p.primary.Name()
+}
+
+// Limits implements Limited.
+func (p *HedgeProvider) Limits() Limits {
+
+	return LimitsOf(p.primary)
+}
+
+func (p *HedgeProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+
+	return p.SearchContext(context.Background(), query)
+}
+
+type hedgeResult struct {
+	pois  []domain.POI
+	err   error
+	hedge bool
+}
+
+func (p *HedgeProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	p.deposit()
+
+	start := time.Now()
+
+	results := make(chan hedgeResult, 2)
+
+	// The primary is left to finish within the caller's deadline when a
+	// hedge wins, so the hedge delay is learnt from its own latency
+	// rather than from hedged calls that would drag it down.
+	primaryCtx, cancelPrimary := detach(ctx)
+
+	keepPrimary := false
+
+	defer func() {
+		if !keepPrimary {
+			cancelPrimary()
+		}
+	}()
+
+	go func() {
+
+		defer cancelPrimary()
+
+		pois, err := SearchWithContext(primaryCtx, p.primary, query)
+
+		if err == nil {
+			p.state.latency.observe(time.Since(start))
+		}
+
+		results <- hedgeResult{pois: pois, err: err}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	inFlight := 1
+
+	var hedgeTimer <-chan time.Time
+
+	if delay, ok := p.delay(); ok {
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		hedgeTimer = timer.C
+	}
+
+	var lastErr error
+
+	for {
+
+		select {
+
+		case <-hedgeTimer:
+
+			hedgeTimer = nil
+
+			if !p.withdraw() {
+				metrics.ProviderHedges.WithLabelValues(p.Name(), "budget_exhausted").Inc()
+				continue
+			}
+
+			metrics.ProviderHedges.WithLabelValues(p.Name(), "fired").Inc()
+
+			slog.DebugContext(ctx, "provider hedge fired", "provider", p.Name(), "alternate", p.alternate.Name())
+
+			go func() {
+				pois, err := SearchWithContext(ctx, p.alternate, query)
+				results <- hedgeResult{pois: pois, err: err, hedge: true}
+			}()
+
+			inFlight++
+
+		case r := <-results:
+
+			inFlight--
+
+			if r.err == nil {
+
+				if r.hedge {
+					metrics.ProviderHedges.WithLabelValues(p.Name(), "won").Inc()
+					keepPrimary = true
+				}
+
+				return r.pois, nil
+			}
+
+			lastErr = r.err
+
+			// Failures are the retry layer's business; only a request
+			// still in flight is worth waiting for.
+			if inFlight == 0 {
+				return nil, lastErr
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// detach returns a context for the primary request that outlives ctx's
+// cancellation but not its deadline.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+
+	detached := context.WithoutCancel(ctx)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+
+	return context.WithCancel(detached)
+}
+
+// delay returns how long to wait before hedging. It reports false until
+// enough latency samples have been collected.
+func (p *HedgeProvider) delay() (time.Duration, bool) {
+
+	d, ok := p.state.latency.percentile(p.percentile)
+
+	if !ok {
+		return 0, false
+	}
+
+	if d < p.minDelay {
+		d = p.minDelay
+	}
+
+	return d, true
+}
+
+func (p *HedgeProvider) deposit() {
+
+	p.state.mu.Lock()
+	p.state.balance = math.Min(hedgeMaxBudgetBalance, p.state.balance+p.budget)
+	p.state.mu.Unlock()
+}
+
+func (p *HedgeProvider) withdraw() bool {
+
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+
+	if p.state.balance < 1 {
+		return false
+	}
+
+	p.state.balance--
+
+	return true
+}
+
+// latencyWindow keeps the most recent call latencies.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [hedgeLatencySamples]time.Duration
+	next    int
+	count   int
+}
+
+func (w *latencyWindow) observe(d time.Duration) {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % hedgeLatencySamples
+
+	if w.count < hedgeLatencySamples {
+		w.count++
+	}
+}
+
+func (w *latencyWindow) percentile(q float64) (time.Duration, bool) {
+
+	w.mu.Lock()
+
+	if w.count < hedgeMinSamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+
+	sorted := make([]time.Duration, w.count)
+	copy(sorted, w.samples[:w.count])
+
+	w.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+
+	if idx < 0 {
+		idx = 0
+	}
+
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+
+	return sorted[idx], true
+}
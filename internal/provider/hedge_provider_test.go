@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+type ctxMockProvider struct {
+	name  string
+	delay time.Duration
+	id    string
+	calls atomic.Int32
+	done  atomic.Int32
+}
+
+func (m *ctxMockProvider) Name() string {
+	return m.name
+}
+
+func (m *ctxMockProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+	return m.SearchContext(context.Background(), query)
+}
+
+func (m *ctxMockProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+	m.calls.Add(1)
+	defer m.done.Add(1)
+
+	select {
+	case <-time.After(m.delay):
+		return []domain.POI{{ID: m.id}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func warmUp(p *HedgeProvider, d time.Duration) {
+	for i := 0; i < hedgeMinSamples; i++ {
+		p.state.latency.observe(d)
+	}
+}
+
+func TestHedgeProvider_NoHedgeWithoutSamples(t *testing.T) {
+
+	primary := &ctxMockProvider{name: "p", delay: 50 * time.Millisecond, id: "primary"}
+	alternate := &ctxMockProvider{name: "p", delay: 0, id: "alternate"}
+
+	hp := NewHedgeProvider(primary, alternate, 0.9, 0, 1)
+
+	results, err := hp.Search(domain.SearchQuery{})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].ID != "primary" {
+		t.Errorf("Expected primary result, got %s", results[0].ID)
+	}
+
+	if alternate.calls.Load() != 0 {
+		t.Errorf("Expected no hedge before latency is known, got %d", alternate.calls.Load())
+	}
+}
+
+func TestHedgeProvider_HedgeWinsAndPrimaryLatencyIsObserved(t *testing.T) {
+
+	primary := &ctxMockProvider{name: "p", delay: 200 * time.Millisecond, id: "primary"}
+	alternate := &ctxMockProvider{name: "p", delay: 0, id: "alternate"}
+
+	hp := NewHedgeProvider(primary, alternate, 0.9, 0, 1)
+	warmUp(hp, 10*time.Millisecond)
+
+	start := time.Now()
+
+	results, err := hp.Search(domain.SearchQuery{})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if results[0].ID != "alternate" {
+		t.Errorf("Expected hedged result, got %s", results[0].ID)
+	}
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected hedge to cut latency, took %v", elapsed)
+	}
+
+	sampled := func() (int, time.Duration) {
+		hp.state.latency.mu.Lock()
+		defer hp.state.latency.mu.Unlock()
+		return hp.state.latency.count, hp.state.latency.samples[(hp.state.latency.next+hedgeLatencySamples-1)%hedgeLatencySamples]
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for count, _ := sampled(); count == hedgeMinSamples && time.Now().Before(deadline); count, _ = sampled() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The slow primary, not the fast hedged call, is the latest sample.
+	count, latest := sampled()
+
+	if count != hedgeMinSamples+1 {
+		t.Fatalf("Expected one new latency sample, got %d", count-hedgeMinSamples)
+	}
+
+	if latest < 200*time.Millisecond {
+		t.Errorf("Expected primary latency of at least 200ms, got %v", latest)
+	}
+}
+
+func TestHedgeProvider_LosingPrimaryStopsAtDeadline(t *testing.T) {
+
+	primary := &ctxMockProvider{name: "p", delay: time.Second, id: "primary"}
+	alternate := &ctxMockProvider{name: "p", delay: 0, id: "alternate"}
+
+	hp := NewHedgeProvider(primary, alternate, 0.9, 0, 1)
+	warmUp(hp, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := hp.SearchContext(ctx, domain.SearchQuery{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for primary.done.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if primary.done.Load() != 1 {
+		t.Error("Expected primary request to stop at the caller's deadline")
+	}
+
+	hp.state.latency.mu.Lock()
+	count := hp.state.latency.count
+	hp.state.latency.mu.Unlock()
+
+	if count != hedgeMinSamples {
+		t.Errorf("Expected no sample from a primary cut off by the deadline, got %d", count-hedgeMinSamples)
+	}
+}
+
+func TestHedgeProvider_FastPrimaryNoHedge(t *testing.T) {
+
+	primary := &ctxMockProvider{name: "p", delay: 0, id: "primary"}
+	alternate := &ctxMockProvider{name: "p", delay: 0, id: "alternate"}
+
+	hp := NewHedgeProvider(primary, alternate, 0.9, 100*time.Millisecond, 1)
+	warmUp(hp, 10*time.Millisecond)
+
+	if _, err := hp.Search(domain.SearchQuery{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if alternate.calls.Load() != 0 {
+		t.Errorf("Expected no hedge for fast primary, got %d", alternate.calls.Load())
+	}
+}
+
+func TestHedgeProvider_BudgetLimitsHedges(t *testing.T) {
+
+	primary := &ctxMockProvider{name: "p", delay: 30 * time.Millisecond, id: "primary"}
+	alternate := &ctxMockProvider{name: "p", delay: 0, id: "alternate"}
+
+	hp := NewHedgeProvider(primary, alternate, 0.5, 0, 0.1)
+	warmUp(hp, time.Millisecond)
+
+	for i := 0; i < 20; i++ {
+		if _, err := hp.Search(domain.SearchQuery{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// One start credit plus 0.1 per call.
+	if hedges := alternate.calls.Load(); hedges > 3 {
+		t.Errorf("Expected budget to cap hedges at 3, got %d", hedges)
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+// ErrQuotaExceeded means the upstream API refused a call because the
+// account's quota is used up.
+var ErrQuotaExceeded = errors.New("provider quota exceeded")
+
+// Billed is implemented by paid providers. SKUs lists what one search
+// call is billed as.
+type Billed interface {
+	Provider
+
+	SKUs() []string
+}
+
+// Meter counts the billable calls of paid providers; see cost.Ledger.
+type Meter interface {
+	Record(provider string, skus []string)
+
+	// QuotaExceeded notes that provider reported its quota used up.
+	QuotaExceeded(provider string)
+}
+
+// MeteredProvider reports every call of a paid provider to a Meter.
+// Successful calls are billed as the provider's SKUs; failed calls are
+// not counted, and quota errors are passed on so the provider can be
+// skipped.
+type MeteredProvider struct {
+	provider Provider
+	skus     []string
+	meter    Meter
+}
+
+func NewMeteredProvider(provider Billed, meter Meter) Provider {
+
+	return &MeteredProvider{
+		provider: provider,
+		skus:     provider.SKUs(),
+		meter:    meter,
+	}
+}
+
+func (p *MeteredProvider) Name() string {
+
+	return p.provider.Name()
+}
+
+// Limits implements Limited.
+func (p *MeteredProvider) Limits() Limits {
+
+	return LimitsOf(p.provider)
+}
+
+func (p *MeteredProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *MeteredProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	results, err := SearchWithContext(ctx, p.provider, query)
+
+	switch {
+
+	case err == nil:
+		p.meter.Record(p.provider.Name(), p.skus)
+
+	case errors.Is(err, ErrQuotaExceeded):
+		p.meter.QuotaExceeded(p.provider.Name())
+	}
+
+	return results, err
+}
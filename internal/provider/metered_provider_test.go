@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+type billedMock struct {
+	mockProvider
+}
+
+func (m *billedMock) SKUs() []string {
+	return []string{"search", "details"}
+}
+
+type fakeMeter struct {
+	recorded [][]string
+	quota    []string
+}
+
+func (m *fakeMeter) Record(provider string, skus []string) {
+	m.recorded = append(m.recorded, skus)
+}
+
+func (m *fakeMeter) QuotaExceeded(provider string) {
+	m.quota = append(m.quota, provider)
+}
+
+func TestMeteredProvider_RecordsSuccessfulCalls(t *testing.T) {
+
+	base := &billedMock{mockProvider{
+		name: "paid",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1"}}, nil
+		},
+	}}
+
+	meter := &fakeMeter{}
+
+	mp := NewMeteredProvider(base, meter)
+
+	if _, err := mp.Search(domain.SearchQuery{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(meter.recorded) != 1 || len(meter.recorded[0]) != 2 {
+		t.Errorf("Expected one call billed as both SKUs, got %v", meter.recorded)
+	}
+
+	if mp.Name() != "paid" {
+		t.Errorf("Expected name 'paid', got '%s'", mp.Name())
+	}
+}
+
+func TestMeteredProvider_FailedCallsNotBilled(t *testing.T) {
+
+	base := &billedMock{mockProvider{
+		name: "paid",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, errors.New("paid status 500")
+		},
+	}}
+
+	meter := &fakeMeter{}
+
+	if _, err := NewMeteredProvider(base, meter).Search(domain.SearchQuery{}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if len(meter.recorded) != 0 || len(meter.quota) != 0 {
+		t.Errorf("Expected nothing recorded, got %v and %v", meter.recorded, meter.quota)
+	}
+}
+
+func TestMeteredProvider_ReportsQuotaExceeded(t *testing.T) {
+
+	base := &billedMock{mockProvider{
+		name: "paid",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return nil, fmt.Errorf("%w: paid status 429", ErrQuotaExceeded)
+		},
+	}}
+
+	meter := &fakeMeter{}
+
+	_, err := NewMeteredProvider(base, meter).Search(domain.SearchQuery{})
+
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	if len(meter.quota) != 1 || meter.quota[0] != "paid" {
+		t.Errorf("Expected the quota reported for 'paid', got %v", meter.quota)
+	}
+}
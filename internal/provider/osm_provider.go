@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,15 +17,29 @@ type OSMProvider struct {
 	client   *http.Client
 }
 
+const defaultOverpassEndpoint = "https://overpass-api.de/api/interpreter"
+
 func NewOSMProvider() *OSMProvider {
+	return NewOSMProviderWithEndpoint(defaultOverpassEndpoint)
+}
+
+// NewOSMProviderWithEndpoint targets a specific Overpass instance, e.g. a
+// mirror used as a hedging alternate.
+func NewOSMProviderWithEndpoint(endpoint string) *OSMProvider {
 	return &OSMProvider{
-		endpoint: "https://overpass-api.de/api/interpreter",
+		endpoint: endpoint,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
 }
 
+// SetTransport routes the provider's requests through rt, e.g. a
+// fixture recorder or replayer.
+func (p *OSMProvider) SetTransport(rt http.RoundTripper) {
+	p.client.Transport = rt
+}
+
 func (p *OSMProvider) Name() string {
 	return "osm"
 }
@@ -40,8 +55,21 @@ type overpassElement struct {
 	Tags map[string]string `json:"tags"`
 }
 
+// Limits implements Limited. Overpass has no cap of its own; this one
+// keeps a single answer within the request timeout.
+func (p *OSMProvider) Limits() Limits {
+	return Limits{MaxResults: 500}
+}
+
 func (p *OSMProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *OSMProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	query = p.Limits().Clamp(query)
+
 	amenityFilter := ""
 
 	if len(query.Categories) > 0 {
@@ -100,7 +128,15 @@ func (p *OSMProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 	form := url.Values{}
 	form.Add("data", overpassQuery)
 
-	resp, err := p.client.PostForm(p.endpoint, form)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
 
 	if err != nil {
 		return nil, err
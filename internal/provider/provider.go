@@ -1,9 +1,78 @@
 package provider
 
-import "github.com/hynek-systems/hynek-poi/internal/domain"
+import (
+	"context"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
 
 type Provider interface {
 	Name() string
 
 	Search(query domain.SearchQuery) ([]domain.POI, error)
 }
+
+// ContextProvider is implemented by providers whose upstream call can be
+// cancelled. Decorators that abandon calls early, such as HedgeProvider,
+// use it to stop the losing request.
+type ContextProvider interface {
+	Provider
+
+	SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error)
+}
+
+// SearchWithContext calls p with ctx when p supports cancellation, so a
+// caller deadline or cancellation reaches the upstream request.
+func SearchWithContext(ctx context.Context, p Provider, query domain.SearchQuery) ([]domain.POI, error) {
+
+	if cp, ok := p.(ContextProvider); ok {
+		return cp.SearchContext(ctx, query)
+	}
+
+	return p.Search(query)
+}
+
+// Limits is the most one upstream call of a provider can answer. Zero
+// fields are unbounded.
+type Limits struct {
+	// MaxResults is the most POIs a call returns, whatever the query's
+	// limit.
+	MaxResults int
+
+	// MaxRadius is the largest search radius in meters the API accepts.
+	MaxRadius int
+}
+
+// Limited is implemented by providers whose upstream API caps the
+// results or the radius of a call. Decorators pass on the limits of the
+// provider they wrap.
+type Limited interface {
+	Provider
+
+	Limits() Limits
+}
+
+// LimitsOf returns p's limits, or none when p is not Limited.
+func LimitsOf(p Provider) Limits {
+
+	if l, ok := p.(Limited); ok {
+		return l.Limits()
+	}
+
+	return Limits{}
+}
+
+// Clamp fits query's limit and radius within l, so a call never asks the
+// API for more than it accepts.
+func (l Limits) Clamp(query domain.SearchQuery) domain.SearchQuery {
+
+	if l.MaxResults > 0 && query.Limit > l.MaxResults {
+		query.Limit = l.MaxResults
+	}
+
+	if l.MaxRadius > 0 && query.Radius > l.MaxRadius {
+		query.Radius = l.MaxRadius
+	}
+
+	return query
+}
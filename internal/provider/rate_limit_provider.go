@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/ratelimit"
+)
+
+var ErrRateLimited = errors.New("provider rate limit exceeded")
+
+// RateLimitProvider caps calls to the wrapped provider with a token bucket.
+// Calls over the limit fail immediately instead of queueing, so they never
+// eat into the orchestrator deadline.
+type RateLimitProvider struct {
+	provider Provider
+	bucket   *ratelimit.Bucket
+}
+
+func NewRateLimitProvider(provider Provider, perSecond float64) Provider {
+
+	return &RateLimitProvider{
+		provider: provider,
+		bucket:   ratelimit.NewBucket(perSecond, 0),
+	}
+}
+
+func (p *RateLimitProvider) Name() string {
+
+	return p.provider.Name()
+}
+
+// Limits implements Limited.
+func (p *RateLimitProvider) Limits() Limits {
+
+	return LimitsOf(p.provider)
+}
+
+func (p *RateLimitProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
+
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *RateLimitProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	if !p.bucket.Allow() {
+		return nil, ErrRateLimited
+	}
+
+	return SearchWithContext(ctx, p.provider, query)
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+)
+
+func TestRateLimitProvider_RejectsOverLimit(t *testing.T) {
+
+	base := &mockProvider{
+		name: "limited",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1"}}, nil
+		},
+	}
+
+	rp := NewRateLimitProvider(base, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := rp.Search(domain.SearchQuery{}); err != nil {
+			t.Fatalf("Call %d: unexpected error: %v", i, err)
+		}
+	}
+
+	_, err := rp.Search(domain.SearchQuery{})
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestRateLimitProvider_Refills(t *testing.T) {
+
+	base := &mockProvider{
+		name: "limited",
+		searchFunc: func(q domain.SearchQuery) ([]domain.POI, error) {
+			return []domain.POI{{ID: "1"}}, nil
+		},
+	}
+
+	rp := NewRateLimitProvider(base, 20)
+
+	for i := 0; i < 20; i++ {
+		_, _ = rp.Search(domain.SearchQuery{})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := rp.Search(domain.SearchQuery{}); err != nil {
+		t.Fatalf("Expected refilled bucket, got %v", err)
+	}
+}
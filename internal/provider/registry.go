@@ -1,25 +1,76 @@
 package provider
 
 import (
-	"time"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
 
 	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
 	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/fixture"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/ratelimit"
 )
 
 type RegisteredProvider struct {
 	Provider Provider
 	Priority int
+
+	// Tier orders providers for tiered routing, lowest first.
+	Tier int
+
+	// Breaker is the provider's circuit breaker, for health reporting.
+	Breaker *circuitbreaker.CircuitBreaker
+}
+
+// Registry builds providers and keeps the state of their circuit
+// breakers, rate limits and hedging by provider name. Rebuilding after a
+// config reload reconfigures that state in place instead of resetting
+// it, so an open breaker stays open and a drained limit stays drained.
+type Registry struct {
+	mu sync.Mutex
+
+	breakers map[string]*circuitbreaker.CircuitBreaker
+	limits   map[string]*ratelimit.Bucket
+	hedges   map[string]*hedgeState
 }
 
-func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
+func NewRegistry() *Registry {
+
+	return &Registry{
+		breakers: map[string]*circuitbreaker.CircuitBreaker{},
+		limits:   map[string]*ratelimit.Bucket{},
+		hedges:   map[string]*hedgeState{},
+	}
+}
+
+// BuildProviders assembles the enabled providers with their resilience
+// stack, starting from fresh state. A non-nil meter counts the calls of
+// paid providers; replayed calls are not counted.
+func BuildProviders(cfg config.ProvidersConfig, meter Meter) []RegisteredProvider {
+
+	return NewRegistry().Build(cfg, meter)
+}
+
+// Build assembles the enabled providers like BuildProviders, continuing
+// from the state of the providers r built before. The state of disabled
+// providers and decorators is dropped.
+func (r *Registry) Build(cfg config.ProvidersConfig, meter Meter) []RegisteredProvider {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	var result []RegisteredProvider
 
 	// Google
 	if cfg.Google.Enabled {
 
-		base := NewGoogleProvider(cfg.Google.ApiKey)
+		base := r.hedge(
+			withMeter(NewGoogleProvider(cfg.Google.ApiKey), cfg.Fixtures, meter),
+			nil,
+			cfg.Google.Hedge,
+		)
 
 		// timeout
 		withTimeout := NewTimeoutProvider(
@@ -34,7 +85,7 @@ func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
 		)
 
 		// circuit breaker
-		cb := circuitbreaker.New(3, 30*time.Second)
+		cb := r.breaker("google", cfg.Google.CircuitBreaker)
 
 		protected := NewCircuitBreakerProvider(
 			withRetry,
@@ -42,21 +93,30 @@ func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
 		)
 
 		result = append(result, RegisteredProvider{
-			Provider: protected,
+			Provider: r.rateLimit(protected, cfg.Google.RateLimit),
 			Priority: cfg.Google.Priority,
+			Tier:     cfg.Google.Tier,
+			Breaker:  cb,
 		})
 	}
 
 	// OSM
 	if cfg.OSM.Enabled {
 
-		base := NewOSMProvider()
+		var alternate Provider
 
-		cb := circuitbreaker.New(
-			3,
-			30*time.Second,
+		if cfg.OSM.Hedge.AlternateEndpoint != "" {
+			alternate = withFixtures(NewOSMProviderWithEndpoint(cfg.OSM.Hedge.AlternateEndpoint), cfg.Fixtures)
+		}
+
+		base := r.hedge(
+			withFixtures(NewOSMProvider(), cfg.Fixtures),
+			alternate,
+			cfg.OSM.Hedge,
 		)
 
+		cb := r.breaker("osm", cfg.OSM.CircuitBreaker)
+
 		withTimeout := NewTimeoutProvider(
 			base,
 			cfg.OSM.Timeout,
@@ -75,15 +135,21 @@ func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
 		)
 
 		result = append(result, RegisteredProvider{
-			Provider: protected,
+			Provider: r.rateLimit(protected, cfg.OSM.RateLimit),
 			Priority: cfg.OSM.Priority,
+			Tier:     cfg.OSM.Tier,
+			Breaker:  cb,
 		})
 	}
 
 	// Foursquare
 	if cfg.Foursquare.Enabled {
 
-		base := NewFoursquareProvider(cfg.Foursquare.ApiKey)
+		base := r.hedge(
+			withMeter(NewFoursquareProvider(cfg.Foursquare.ApiKey), cfg.Fixtures, meter),
+			nil,
+			cfg.Foursquare.Hedge,
+		)
 
 		withTimeout := NewTimeoutProvider(
 			base,
@@ -95,7 +161,7 @@ func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
 			cfg.Foursquare.Retries,
 		)
 
-		cb := circuitbreaker.New(3, 30*time.Second)
+		cb := r.breaker("foursquare", cfg.Foursquare.CircuitBreaker)
 
 		protected := NewCircuitBreakerProvider(
 			withRetry,
@@ -103,10 +169,182 @@ func BuildProviders(cfg config.ProvidersConfig) []RegisteredProvider {
 		)
 
 		result = append(result, RegisteredProvider{
-			Provider: protected,
+			Provider: r.rateLimit(protected, cfg.Foursquare.RateLimit),
 			Priority: cfg.Foursquare.Priority,
+			Tier:     cfg.Foursquare.Tier,
+			Breaker:  cb,
 		})
 	}
 
+	for name := range r.breakers {
+		if !enabled(cfg, name) {
+			delete(r.breakers, name)
+			delete(r.limits, name)
+			delete(r.hedges, name)
+		}
+	}
+
 	return result
 }
+
+func enabled(cfg config.ProvidersConfig, name string) bool {
+
+	switch name {
+
+	case "google":
+		return cfg.Google.Enabled
+
+	case "osm":
+		return cfg.OSM.Enabled
+
+	case "foursquare":
+		return cfg.Foursquare.Enabled
+	}
+
+	return false
+}
+
+// NewBase returns the named provider without the resilience stack, for
+// diagnostics. It need not be enabled in cfg; fixtures still apply.
+func NewBase(name string, cfg config.ProvidersConfig) (Provider, error) {
+
+	switch name {
+
+	case "google":
+		return withFixtures(NewGoogleProvider(cfg.Google.ApiKey), cfg.Fixtures), nil
+
+	case "osm":
+		return withFixtures(NewOSMProvider(), cfg.Fixtures), nil
+
+	case "foursquare":
+		return withFixtures(NewFoursquareProvider(cfg.Foursquare.ApiKey), cfg.Fixtures), nil
+	}
+
+	return nil, fmt.Errorf("unknown provider %q", name)
+}
+
+// transportSetter is implemented by providers calling HTTP APIs.
+type transportSetter interface {
+	Provider
+	SetTransport(rt http.RoundTripper)
+}
+
+// paidProvider is implemented by providers calling paid HTTP APIs.
+type paidProvider interface {
+	transportSetter
+	SKUs() []string
+}
+
+// withFixtures routes p's HTTP traffic through a fixture recorder or
+// replayer when cfg asks for one. Fixtures are kept per provider name, so
+// a hedging alternate shares its primary's.
+func withFixtures(p transportSetter, cfg config.FixturesConfig) Provider {
+
+	if rt := fixture.Transport(fixture.Mode(cfg.Mode), cfg.Dir, p.Name(), nil); rt != nil {
+		p.SetTransport(rt)
+	}
+
+	return p
+}
+
+// withMeter routes p's traffic through fixtures like withFixtures, and
+// reports its calls to meter unless they are replayed. It sits below
+// retries and hedging, so every upstream call is counted.
+func withMeter(p paidProvider, cfg config.FixturesConfig, meter Meter) Provider {
+
+	withFixtures(p, cfg)
+
+	if meter == nil || cfg.Mode == "replay" {
+		return p
+	}
+
+	return NewMeteredProvider(p, meter)
+}
+
+// hedge wraps p in a HedgeProvider when hedging is enabled, keeping the
+// latencies and budget of p's previous HedgeProvider. A nil alternate
+// hedges against p itself.
+func (r *Registry) hedge(p Provider, alternate Provider, cfg config.HedgeConfig) Provider {
+
+	if !cfg.Enabled {
+		delete(r.hedges, p.Name())
+		return p
+	}
+
+	state, ok := r.hedges[p.Name()]
+
+	if !ok {
+		state = newHedgeState()
+		r.hedges[p.Name()] = state
+	}
+
+	return newHedgeProvider(
+		p,
+		alternate,
+		cfg.Percentile,
+		cfg.MinDelay,
+		cfg.Budget,
+		state,
+	)
+}
+
+// rateLimit wraps p in a RateLimitProvider unless perSecond is zero,
+// keeping the tokens left in p's previous bucket.
+func (r *Registry) rateLimit(p Provider, perSecond float64) Provider {
+
+	if perSecond <= 0 {
+		delete(r.limits, p.Name())
+		return p
+	}
+
+	bucket, ok := r.limits[p.Name()]
+
+	if ok {
+		bucket.SetRate(perSecond, 0)
+	} else {
+		bucket = ratelimit.NewBucket(perSecond, 0)
+		r.limits[p.Name()] = bucket
+	}
+
+	return &RateLimitProvider{
+		provider: p,
+		bucket:   bucket,
+	}
+}
+
+// breaker returns the named provider's circuit breaker with cfg applied,
+// creating it closed the first time.
+func (r *Registry) breaker(name string, cfg config.CircuitBreakerConfig) *circuitbreaker.CircuitBreaker {
+
+	settings := circuitbreaker.Config{
+		Window:           cfg.Window,
+		FailureRate:      cfg.FailureRate,
+		MinRequests:      cfg.MinRequests,
+		OpenTimeout:      cfg.ResetTimeout,
+		HalfOpenProbes:   cfg.HalfOpenProbes,
+		SlowCallDuration: cfg.SlowCallDuration,
+		SlowCallRate:     cfg.SlowCallRate,
+		OnStateChange:    onBreakerStateChange,
+	}
+
+	if cb, ok := r.breakers[name]; ok {
+		cb.SetConfig(settings)
+		return cb
+	}
+
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(circuitbreaker.StateClosed))
+
+	cb := circuitbreaker.New(name, settings)
+
+	r.breakers[name] = cb
+
+	return cb
+}
+
+func onBreakerStateChange(name string, from, to circuitbreaker.State) {
+
+	log.Printf("circuit breaker %s: %s -> %s", name, from, to)
+
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(to))
+	metrics.CircuitBreakerTransitions.WithLabelValues(name, from.String(), to.String()).Inc()
+}
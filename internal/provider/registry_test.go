@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/circuitbreaker"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+)
+
+func registryConfig() config.ProvidersConfig {
+
+	return config.ProvidersConfig{
+		OSM: config.ProviderConfig{
+			Enabled:   true,
+			Timeout:   time.Second,
+			RateLimit: 1,
+			CircuitBreaker: config.CircuitBreakerConfig{
+				Window:       time.Minute,
+				FailureRate:  0.5,
+				MinRequests:  1,
+				ResetTimeout: time.Hour,
+			},
+			Hedge: config.HedgeConfig{Enabled: true, Percentile: 0.9, Budget: 0.1},
+		},
+	}
+}
+
+func TestRegistry_RebuildKeepsState(t *testing.T) {
+
+	registry := NewRegistry()
+
+	first := registry.Build(registryConfig(), nil)
+
+	done, err := first[0].Breaker.Allow()
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	done(circuitbreaker.Failure, 0)
+
+	bucket := registry.limits["osm"]
+	bucket.Allow()
+
+	hedge := registry.hedges["osm"]
+
+	// e.g. only the log level changed
+	second := registry.Build(registryConfig(), nil)
+
+	if second[0].Breaker != first[0].Breaker || second[0].Breaker.State() != circuitbreaker.StateOpen {
+		t.Errorf("Expected the open breaker kept, got %v", second[0].Breaker.State())
+	}
+
+	if registry.limits["osm"] != bucket || bucket.Allow() {
+		t.Error("Expected the drained rate limit kept")
+	}
+
+	if registry.hedges["osm"] != hedge {
+		t.Error("Expected the hedge state kept")
+	}
+}
+
+func TestRegistry_RebuildReconfigures(t *testing.T) {
+
+	registry := NewRegistry()
+
+	first := registry.Build(registryConfig(), nil)
+
+	done, _ := first[0].Breaker.Allow()
+	done(circuitbreaker.Failure, 0)
+
+	cfg := registryConfig()
+	cfg.OSM.CircuitBreaker.ResetTimeout = 0
+	cfg.OSM.RateLimit = 0
+	cfg.OSM.Hedge.Enabled = false
+
+	second := registry.Build(cfg, nil)
+
+	if second[0].Breaker.State() != circuitbreaker.StateHalfOpen {
+		t.Errorf("Expected the new reset timeout applied, got %v", second[0].Breaker.State())
+	}
+
+	if _, ok := registry.limits["osm"]; ok {
+		t.Error("Expected the disabled rate limit dropped")
+	}
+
+	if _, ok := registry.hedges["osm"]; ok {
+		t.Error("Expected the disabled hedge dropped")
+	}
+
+	cfg.OSM.Enabled = false
+
+	if registry.Build(cfg, nil); len(registry.breakers) != 0 {
+		t.Error("Expected the disabled provider's breaker dropped")
+	}
+}
@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/hynek-systems/hynek-poi/internal/domain"
@@ -24,13 +26,24 @@ func (p *RetryProvider) Name() string {
 	return p.provider.Name()
 }
 
+// Limits implements Limited.
+func (p *RetryProvider) Limits() Limits {
+
+	return LimitsOf(p.provider)
+}
+
 func (p *RetryProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *RetryProvider) SearchContext(ctx context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
 	var lastErr error
 
 	for i := 0; i <= p.retries; i++ {
 
-		result, err := p.provider.Search(query)
+		result, err := SearchWithContext(ctx, p.provider, query)
 
 		if err == nil {
 			return result, nil
@@ -38,7 +51,17 @@ func (p *RetryProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
 		lastErr = err
 
-		time.Sleep(100 * time.Millisecond)
+		if i < p.retries {
+			slog.DebugContext(ctx, "provider retry", "provider", p.provider.Name(), "attempt", i+1, "error", err)
+		}
+
+		select {
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-time.After(100 * time.Millisecond):
+		}
 	}
 
 	return nil, lastErr
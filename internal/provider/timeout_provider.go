@@ -2,12 +2,15 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
 
+var ErrTimeout = errors.New("provider timeout")
+
 type TimeoutProvider struct {
 	provider Provider
 	timeout  time.Duration
@@ -26,9 +29,20 @@ func (p *TimeoutProvider) Name() string {
 	return p.provider.Name()
 }
 
+// Limits implements Limited.
+func (p *TimeoutProvider) Limits() Limits {
+
+	return LimitsOf(p.provider)
+}
+
 func (p *TimeoutProvider) Search(query domain.SearchQuery) ([]domain.POI, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	return p.SearchContext(context.Background(), query)
+}
+
+func (p *TimeoutProvider) SearchContext(parent context.Context, query domain.SearchQuery) ([]domain.POI, error) {
+
+	ctx, cancel := context.WithTimeout(parent, p.timeout)
 	defer cancel()
 
 	resultChan := make(chan []domain.POI, 1)
@@ -36,7 +50,7 @@ func (p *TimeoutProvider) Search(query domain.SearchQuery) ([]domain.POI, error)
 
 	go func() {
 
-		result, err := p.provider.Search(query)
+		result, err := SearchWithContext(ctx, p.provider, query)
 
 		if err != nil {
 			errorChan <- err
@@ -55,6 +69,11 @@ func (p *TimeoutProvider) Search(query domain.SearchQuery) ([]domain.POI, error)
 		return nil, err
 
 	case <-ctx.Done():
-		return nil, fmt.Errorf("provider timeout: %s", p.provider.Name())
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, ctx.Err()
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrTimeout, p.provider.Name())
 	}
 }
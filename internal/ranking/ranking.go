@@ -2,15 +2,23 @@ package ranking
 
 import (
 	"sort"
+	"sync"
 
 	"github.com/hynek-systems/hynek-poi/internal/domain"
 )
 
-var providerPriority = map[string]int{}
+var (
+	priorityMu       sync.RWMutex
+	providerPriority = map[string]int{}
+)
 
+// SetProviderPriorities replaces the priority table. It is safe to call
+// while Rank runs concurrently, e.g. on config reload.
 func SetProviderPriorities(priorities map[string]int) {
 
+	priorityMu.Lock()
 	providerPriority = priorities
+	priorityMu.Unlock()
 }
 
 func Rank(pois []domain.POI, query domain.SearchQuery) []domain.POI {
@@ -20,6 +28,11 @@ func Rank(pois []domain.POI, query domain.SearchQuery) []domain.POI {
 		a := pois[i]
 		b := pois[j]
 
+		// 0. curated pins
+		if a.Pinned != b.Pinned {
+			return b.Pinned == 0 || (a.Pinned != 0 && a.Pinned < b.Pinned)
+		}
+
 		// 1. provider priority
 		pa := priority(a.Source)
 		pb := priority(b.Source)
@@ -40,7 +53,11 @@ func Rank(pois []domain.POI, query domain.SearchQuery) []domain.POI {
 
 func priority(provider string) int {
 
-	if p, ok := providerPriority[provider]; ok {
+	priorityMu.RLock()
+	p, ok := providerPriority[provider]
+	priorityMu.RUnlock()
+
+	if ok {
 
 		return p
 	}
@@ -198,3 +198,22 @@ func TestDistance_Calculation(t *testing.T) {
 		t.Errorf("Expected poi2 to be farther, got d1=%f, d2=%f", d1, d2)
 	}
 }
+
+func TestRank_PinnedFirst(t *testing.T) {
+	query := domain.SearchQuery{
+		Latitude:  59.3293,
+		Longitude: 18.0686,
+	}
+
+	pois := []domain.POI{
+		{ID: "near", Latitude: 59.3293, Longitude: 18.0686},
+		{ID: "second", Latitude: 59.4000, Longitude: 18.1000, Pinned: 2},
+		{ID: "first", Latitude: 59.5000, Longitude: 18.2000, Pinned: 1},
+	}
+
+	result := Rank(pois, query)
+
+	if result[0].ID != "first" || result[1].ID != "second" || result[2].ID != "near" {
+		t.Errorf("Expected pinned POIs first in pin order, got %s, %s, %s", result[0].ID, result[1].ID, result[2].ID)
+	}
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket. Callers over the limit are refused rather than
+// queued, so a limit never eats into a request deadline.
+type Bucket struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+// NewBucket allows perSecond calls on average with bursts of up to burst.
+// A burst below 1 defaults to one second worth of calls.
+func NewBucket(perSecond float64, burst int) *Bucket {
+
+	b := burstOf(perSecond, burst)
+
+	return &Bucket{
+		rate:     perSecond,
+		burst:    b,
+		tokens:   b,
+		lastFill: time.Now(),
+	}
+}
+
+// SetRate changes the limit like NewBucket's arguments, keeping the
+// tokens left so far, up to the new burst.
+func (b *Bucket) SetRate(perSecond float64, burst int) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// tokens earned so far are earned at the old rate
+	b.fill(time.Now())
+
+	b.rate = perSecond
+	b.burst = burstOf(perSecond, burst)
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// fill adds the tokens earned since the last fill. It must be called
+// with mu held.
+func (b *Bucket) fill(now time.Time) {
+
+	if now.After(b.lastFill) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.lastFill).Seconds()*b.rate)
+		b.lastFill = now
+	}
+}
+
+func burstOf(perSecond float64, burst int) float64 {
+
+	if burst < 1 {
+		return math.Max(1, math.Ceil(perSecond))
+	}
+
+	return float64(burst)
+}
+
+// Allow takes a token if one is available.
+func (b *Bucket) Allow() bool {
+
+	return b.allowAt(time.Now())
+}
+
+func (b *Bucket) allowAt(now time.Time) bool {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fill(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// full reports whether the bucket has refilled completely, meaning it
+// holds no state worth keeping.
+func (b *Bucket) full(now time.Time) bool {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens+now.Sub(b.lastFill).Seconds()*b.rate >= b.burst
+}
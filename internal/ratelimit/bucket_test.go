@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucket_RefusesOverBurstAndRefills(t *testing.T) {
+
+	b := NewBucket(10, 2)
+
+	now := time.Now()
+
+	if !b.allowAt(now) || !b.allowAt(now) {
+		t.Fatalf("Expected burst of 2 to be allowed")
+	}
+
+	if b.allowAt(now) {
+		t.Fatalf("Expected third call to be refused")
+	}
+
+	if !b.allowAt(now.Add(100 * time.Millisecond)) {
+		t.Fatalf("Expected a token after 100ms at 10/s")
+	}
+}
+
+func TestBucket_DefaultBurst(t *testing.T) {
+
+	b := NewBucket(2.5, 0)
+
+	if b.burst != 3 {
+		t.Errorf("Expected burst 3, got %v", b.burst)
+	}
+}
+
+func TestKeyed_SeparateBucketsPerKey(t *testing.T) {
+
+	k := NewKeyed(1, 1)
+
+	if !k.Allow("a") {
+		t.Fatalf("Expected first call for a to be allowed")
+	}
+
+	if k.Allow("a") {
+		t.Fatalf("Expected second call for a to be refused")
+	}
+
+	if !k.Allow("b") {
+		t.Fatalf("Expected b to have its own bucket")
+	}
+}
+
+func TestKeyed_SweepDropsFullBuckets(t *testing.T) {
+
+	k := NewKeyed(1, 1)
+
+	k.Allow("a")
+
+	k.mu.Lock()
+	k.sweep(time.Now().Add(2 * time.Second))
+	remaining := len(k.buckets)
+	k.mu.Unlock()
+
+	if remaining != 0 {
+		t.Errorf("Expected refilled bucket to be dropped, got %d", remaining)
+	}
+}
+
+func TestBucket_SetRateKeepsTokens(t *testing.T) {
+
+	b := NewBucket(1, 2)
+	b.Allow()
+	b.Allow()
+
+	b.SetRate(100, 0)
+
+	if b.burst != 100 || b.tokens >= 1 {
+		t.Errorf("Expected the new burst and no tokens yet, got burst %v and %v tokens", b.burst, b.tokens)
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+// Keyed keeps one bucket per key, e.g. per client. Buckets that have
+// refilled completely are dropped periodically, so memory follows the
+// number of recently active keys.
+type Keyed struct {
+	perSecond float64
+	burst     int
+
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+}
+
+func NewKeyed(perSecond float64, burst int) *Keyed {
+
+	return &Keyed{
+		perSecond: perSecond,
+		burst:     burst,
+		buckets:   map[string]*Bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket.
+func (k *Keyed) Allow(key string) bool {
+
+	now := time.Now()
+
+	k.mu.Lock()
+
+	if now.Sub(k.lastSweep) >= sweepInterval {
+		k.sweep(now)
+	}
+
+	bucket, ok := k.buckets[key]
+
+	if !ok {
+		bucket = NewBucket(k.perSecond, k.burst)
+		k.buckets[key] = bucket
+	}
+
+	k.mu.Unlock()
+
+	return bucket.allowAt(now)
+}
+
+func (k *Keyed) sweep(now time.Time) {
+
+	for key, bucket := range k.buckets {
+		if bucket.full(now) {
+			delete(k.buckets, key)
+		}
+	}
+
+	k.lastSweep = now
+}
@@ -0,0 +1,81 @@
+package warming
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps tile request counts shared by all replicas, so the hot set
+// survives restarts and reflects traffic across the fleet.
+type Store interface {
+	Increment(ctx context.Context, counts map[string]float64) error
+	Top(ctx context.Context, n int) ([]string, error)
+
+	// Decay multiplies every count by factor and drops the ones that fall
+	// below one, so old traffic fades out.
+	Decay(ctx context.Context, factor float64) error
+
+	// Acquire takes the warming lock for ttl; false means another replica
+	// holds it.
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+}
+
+const (
+	frequencyKey = "hynek-poi:warming:frequency"
+	lockKey      = "hynek-poi:warming:lock"
+)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Increment(ctx context.Context, counts map[string]float64) error {
+
+	pipe := s.client.Pipeline()
+
+	for key, n := range counts {
+		pipe.ZIncrBy(ctx, frequencyKey, n, key)
+	}
+
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (s *RedisStore) Top(ctx context.Context, n int) ([]string, error) {
+
+	if n <= 0 {
+		return nil, nil
+	}
+
+	return s.client.ZRevRange(ctx, frequencyKey, 0, int64(n-1)).Result()
+}
+
+func (s *RedisStore) Decay(ctx context.Context, factor float64) error {
+
+	pipe := s.client.TxPipeline()
+
+	pipe.ZUnionStore(ctx, frequencyKey, &redis.ZStore{
+		Keys:    []string{frequencyKey},
+		Weights: []float64{factor},
+	})
+
+	pipe.ZRemRangeByScore(ctx, frequencyKey, "-inf", "(1")
+
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (s *RedisStore) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+
+	return s.client.SetNX(ctx, lockKey, "1", ttl).Result()
+}
+
+var _ Store = (*RedisStore)(nil)
@@ -0,0 +1,61 @@
+package warming
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+)
+
+// maxPending bounds the distinct tiles buffered between flushes; tiles
+// first seen once it is reached are not counted until the next flush.
+const maxPending = 10000
+
+// Tracker counts the tiles searches cover. Counts are buffered in memory
+// and added to the Store on Flush, keeping Redis off the search path.
+type Tracker struct {
+	mu      sync.Mutex
+	pending map[string]float64
+	store   Store
+}
+
+func NewTracker(store Store) *Tracker {
+	return &Tracker{
+		pending: map[string]float64{},
+		store:   store,
+	}
+}
+
+// RecordTiles implements orchestrator.TileRecorder.
+func (t *Tracker) RecordTiles(hashes []string, categories []string) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, hash := range hashes {
+
+		key := cache.TileKey(hash, categories)
+
+		if _, ok := t.pending[key]; !ok && len(t.pending) >= maxPending {
+			continue
+		}
+
+		t.pending[key]++
+	}
+}
+
+// Flush adds the buffered counts to the store. They are dropped if the
+// store fails, since a missed interval only slightly skews the ranking.
+func (t *Tracker) Flush(ctx context.Context) error {
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = map[string]float64{}
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return t.store.Increment(ctx, pending)
+}
@@ -0,0 +1,265 @@
+package warming
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/domain"
+	"github.com/hynek-systems/hynek-poi/internal/metrics"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+)
+
+const (
+	// flushInterval is how often buffered tile counts reach the store.
+	flushInterval = time.Minute
+
+	// decayFactor is applied to all counts after every run, halving the
+	// weight of traffic roughly every other interval.
+	decayFactor = 0.7
+)
+
+// Refresher re-fetches one tile and stores it; CachedOrchestrator.Warm.
+type Refresher interface {
+	Warm(ctx context.Context, hash string, categories []string, limit int) error
+}
+
+// ttlReader is implemented by refreshers that can tell how long a cached
+// tile has left, such as CachedOrchestrator. Without it every target is
+// re-fetched.
+type ttlReader interface {
+	TileTTL(hash string, categories []string) time.Duration
+}
+
+// Warmer keeps configured regions and the most requested tiles cached.
+// On startup and every interval one replica, holding the store's lock,
+// re-fetches them within a provider-call budget.
+type Warmer struct {
+	store     Store
+	tracker   *Tracker
+	refresher func() Refresher
+	cfg       config.WarmingConfig
+
+	quietStart time.Duration
+	quietEnd   time.Duration
+
+	now func() time.Time
+}
+
+func NewWarmer(store Store, tracker *Tracker, refresher func() Refresher, cfg config.WarmingConfig) *Warmer {
+
+	w := &Warmer{
+		store:     store,
+		tracker:   tracker,
+		refresher: refresher,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+
+	if cfg.QuietHours != "" {
+		// validated with the rest of the config
+		w.quietStart, w.quietEnd, _ = config.ParseQuietHours(cfg.QuietHours)
+	}
+
+	return w
+}
+
+// Run warms immediately, then every interval, and flushes tile counts
+// until ctx is done.
+func (w *Warmer) Run(ctx context.Context) {
+
+	w.RunOnce(ctx)
+
+	warm := time.NewTicker(w.cfg.Interval)
+	defer warm.Stop()
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		case <-flush.C:
+
+			if err := w.tracker.Flush(ctx); err != nil {
+				log.Printf("cache warming: flush tile counts: %v", err)
+			}
+
+		case <-warm.C:
+			w.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce performs one warming pass and reports how many tiles were
+// refreshed.
+func (w *Warmer) RunOnce(ctx context.Context) int {
+
+	if err := w.tracker.Flush(ctx); err != nil {
+		log.Printf("cache warming: flush tile counts: %v", err)
+	}
+
+	if w.quiet(w.now()) {
+		metrics.WarmingRuns.WithLabelValues("quiet_hours").Inc()
+		return 0
+	}
+
+	acquired, err := w.store.Acquire(ctx, w.cfg.Interval*9/10)
+
+	if err != nil {
+		metrics.WarmingRuns.WithLabelValues("error").Inc()
+		log.Printf("cache warming: lock: %v", err)
+		return 0
+	}
+
+	if !acquired {
+		metrics.WarmingRuns.WithLabelValues("locked").Inc()
+		return 0
+	}
+
+	targets, err := w.targets(ctx)
+
+	if err != nil {
+		log.Printf("cache warming: hot tiles unavailable, warming configured regions only: %v", err)
+	}
+
+	budget := orchestrator.NewCallBudget(w.cfg.ProviderBudget)
+	budgetCtx := orchestrator.WithCallBudget(ctx, budget)
+
+	refresher := w.refresher()
+
+	warmed := 0
+
+	ttls, _ := refresher.(ttlReader)
+
+	for i, key := range targets {
+
+		hash, categories, _ := cache.ParseTileKey(key)
+
+		// a tile still cached past the next run is refreshed by a later one
+		if ttls != nil && ttls.TileTTL(hash, categories) > w.refreshMargin() {
+			metrics.WarmingTiles.WithLabelValues("fresh").Inc()
+			continue
+		}
+
+		err := refresher.Warm(budgetCtx, hash, categories, w.cfg.Limit)
+
+		if errors.Is(err, orchestrator.ErrCallBudgetExhausted) {
+			metrics.WarmingTiles.WithLabelValues("budget_exhausted").Add(float64(len(targets) - i))
+			break
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err != nil {
+			metrics.WarmingTiles.WithLabelValues("failed").Inc()
+			continue
+		}
+
+		metrics.WarmingTiles.WithLabelValues("warmed").Inc()
+		warmed++
+	}
+
+	if err := w.store.Decay(ctx, decayFactor); err != nil {
+		log.Printf("cache warming: decay tile counts: %v", err)
+	}
+
+	metrics.WarmingRuns.WithLabelValues("completed").Inc()
+
+	log.Printf("cache warming: %d of %d tiles refreshed, %d provider calls", warmed, len(targets), budget.Used())
+
+	return warmed
+}
+
+// refreshMargin is the remaining TTL below which a tile is refreshed. Two
+// intervals leave a tile skipped now at least one interval to spare when
+// the next run refreshes it.
+func (w *Warmer) refreshMargin() time.Duration {
+
+	return 2 * w.cfg.Interval
+}
+
+// targets lists tile keys to warm: configured regions first, then the
+// hottest tiles not already included.
+func (w *Warmer) targets(ctx context.Context) ([]string, error) {
+
+	seen := map[string]bool{}
+
+	var keys []string
+
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, region := range w.cfg.Regions {
+
+		cover, err := cache.CoverQuery(regionQuery(region))
+
+		if err != nil {
+			log.Printf("cache warming: region at %f,%f: %v", region.Latitude, region.Longitude, err)
+			continue
+		}
+
+		for _, hash := range cover.Hashes {
+			add(cache.TileKey(hash, region.Categories))
+		}
+	}
+
+	hot, err := w.store.Top(ctx, w.cfg.TopN)
+
+	for _, key := range hot {
+		add(key)
+	}
+
+	return keys, err
+}
+
+func regionQuery(region config.WarmRegion) domain.SearchQuery {
+
+	query := domain.SearchQuery{
+		Latitude:   region.Latitude,
+		Longitude:  region.Longitude,
+		Radius:     region.Radius,
+		Categories: region.Categories,
+	}
+
+	if len(region.BBox) == 4 {
+		query.BBox = &domain.BBox{
+			MinLat: region.BBox[0],
+			MinLng: region.BBox[1],
+			MaxLat: region.BBox[2],
+			MaxLng: region.BBox[3],
+		}
+	}
+
+	return query
+}
+
+// quiet reports whether now falls within the quiet hours.
+func (w *Warmer) quiet(now time.Time) bool {
+
+	if w.cfg.QuietHours == "" {
+		return false
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	offset := now.Sub(midnight)
+
+	if w.quietStart <= w.quietEnd {
+		return offset >= w.quietStart && offset < w.quietEnd
+	}
+
+	return offset >= w.quietStart || offset < w.quietEnd
+}
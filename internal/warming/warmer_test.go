@@ -0,0 +1,225 @@
+package warming
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hynek-systems/hynek-poi/internal/cache"
+	"github.com/hynek-systems/hynek-poi/internal/config"
+	"github.com/hynek-systems/hynek-poi/internal/orchestrator"
+)
+
+type memoryStore struct {
+	mu     sync.Mutex
+	counts map[string]float64
+	locked bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{counts: map[string]float64{}}
+}
+
+func (s *memoryStore) Increment(ctx context.Context, counts map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, n := range counts {
+		s.counts[k] += n
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Top(ctx context.Context, n int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.counts))
+	for k := range s.counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return s.counts[keys[i]] > s.counts[keys[j]] })
+
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+
+	return keys, nil
+}
+
+func (s *memoryStore) Decay(ctx context.Context, factor float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.counts {
+		if s.counts[k] *= factor; s.counts[k] < 1 {
+			delete(s.counts, k)
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.locked {
+		return false, nil
+	}
+
+	s.locked = true
+
+	return true, nil
+}
+
+type fakeRefresher struct {
+	warmed []string
+	budget int
+}
+
+func (r *fakeRefresher) Warm(ctx context.Context, hash string, categories []string, limit int) error {
+	if len(r.warmed) >= r.budget {
+		return orchestrator.ErrCallBudgetExhausted
+	}
+
+	r.warmed = append(r.warmed, cache.TileKey(hash, categories))
+
+	return nil
+}
+
+// ttlRefresher knows the remaining TTL of cached tiles.
+type ttlRefresher struct {
+	fakeRefresher
+	ttls map[string]time.Duration
+}
+
+func (r *ttlRefresher) TileTTL(hash string, categories []string) time.Duration {
+	return r.ttls[cache.TileKey(hash, categories)]
+}
+
+func testConfig() config.WarmingConfig {
+	return config.WarmingConfig{
+		Enabled:        true,
+		Interval:       time.Minute,
+		TopN:           2,
+		ProviderBudget: 100,
+		Limit:          50,
+	}
+}
+
+func TestWarmer_WarmsHottestTiles(t *testing.T) {
+	store := newMemoryStore()
+	tracker := NewTracker(store)
+
+	tracker.RecordTiles([]string{"u6sce", "u6scf"}, []string{"cafe"})
+	tracker.RecordTiles([]string{"u6sce"}, []string{"cafe"})
+	tracker.RecordTiles([]string{"u6scg"}, nil)
+	tracker.RecordTiles([]string{"u6scg"}, nil)
+	tracker.RecordTiles([]string{"u6scg"}, nil)
+
+	refresher := &fakeRefresher{budget: 100}
+
+	w := NewWarmer(store, tracker, func() Refresher { return refresher }, testConfig())
+
+	if n := w.RunOnce(context.Background()); n != 2 {
+		t.Fatalf("Expected 2 tiles warmed, got %d", n)
+	}
+
+	want := []string{cache.TileKey("u6scg", nil), cache.TileKey("u6sce", []string{"cafe"})}
+
+	if len(refresher.warmed) != 2 || refresher.warmed[0] != want[0] || refresher.warmed[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, refresher.warmed)
+	}
+}
+
+func TestWarmer_ConfiguredRegionsFirstWithinBudget(t *testing.T) {
+	store := newMemoryStore()
+	tracker := NewTracker(store)
+
+	tracker.RecordTiles([]string{"u6sce"}, nil)
+
+	cfg := testConfig()
+	cfg.Regions = []config.WarmRegion{{Latitude: 59.3293, Longitude: 18.0686, Radius: 1000}}
+
+	refresher := &fakeRefresher{budget: 3}
+
+	w := NewWarmer(store, tracker, func() Refresher { return refresher }, cfg)
+
+	if n := w.RunOnce(context.Background()); n != 3 {
+		t.Fatalf("Expected warming to stop at the budget, got %d tiles", n)
+	}
+
+	hash, _, _ := cache.ParseTileKey(refresher.warmed[0])
+
+	cover, err := cache.CoverQuery(regionQuery(cfg.Regions[0]))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(hash) != int(cover.Precision) {
+		t.Errorf("Expected region tiles warmed first, got %v", refresher.warmed)
+	}
+}
+
+func TestWarmer_SkipsQuietHoursAndLockedRuns(t *testing.T) {
+	store := newMemoryStore()
+	tracker := NewTracker(store)
+
+	tracker.RecordTiles([]string{"u6sce"}, nil)
+
+	cfg := testConfig()
+	cfg.QuietHours = "22:00-06:00"
+
+	refresher := &fakeRefresher{budget: 100}
+
+	w := NewWarmer(store, tracker, func() Refresher { return refresher }, cfg)
+
+	w.now = func() time.Time { return time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC) }
+
+	if n := w.RunOnce(context.Background()); n != 0 {
+		t.Errorf("Expected no warming during quiet hours, got %d", n)
+	}
+
+	w.now = func() time.Time { return time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	if n := w.RunOnce(context.Background()); n != 1 {
+		t.Errorf("Expected warming outside quiet hours, got %d", n)
+	}
+
+	// the lock is still held for this interval
+	if n := w.RunOnce(context.Background()); n != 0 {
+		t.Errorf("Expected locked run to skip, got %d", n)
+	}
+}
+
+func TestWarmer_SkipsTilesFarFromExpiry(t *testing.T) {
+	store := newMemoryStore()
+	tracker := NewTracker(store)
+
+	tracker.RecordTiles([]string{"u6sce", "u6scf"}, nil)
+	tracker.RecordTiles([]string{"u6sce"}, nil)
+
+	refresher := &ttlRefresher{
+		fakeRefresher: fakeRefresher{budget: 100},
+		ttls: map[string]time.Duration{
+			cache.TileKey("u6sce", nil): time.Hour,
+			cache.TileKey("u6scf", nil): 30 * time.Second,
+		},
+	}
+
+	w := NewWarmer(store, tracker, func() Refresher { return refresher }, testConfig())
+
+	if n := w.RunOnce(context.Background()); n != 1 {
+		t.Fatalf("Expected 1 tile warmed, got %d", n)
+	}
+
+	if refresher.warmed[0] != cache.TileKey("u6scf", nil) {
+		t.Errorf("Expected only the tile about to expire warmed, got %v", refresher.warmed)
+	}
+}